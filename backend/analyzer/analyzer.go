@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"proyecto1/commands"
+	"proyecto1/structs"
 	"strings"
 )
 
@@ -167,8 +168,11 @@ func executeCommand(commandLine string) string {
 		// Crea un FlagSet específico para mkfs.
 		mkfsCmd := flag.NewFlagSet("mkfs", flag.ContinueOnError)
 		id := mkfsCmd.String("id", "", "ID de la partición a formatear.")
-		typeStr := mkfsCmd.String("type", "full", "Tipo de formateo (full).")
+		typeStr := mkfsCmd.String("type", "full", "Tipo de formateo (fast/full).")
 		fs := mkfsCmd.String("fs", "2fs", "Sistema de archivos (2fs).")
+		inodes := mkfsCmd.Int("inodes", 0, "Cantidad de inodos (0 = calcular según el espacio).")
+		ratio := mkfsCmd.Int("ratio", 3, "Bloques por cada inodo.")
+		blockSize := mkfsCmd.Int("blocksize", structs.FILE_BLOCK_SIZE, "Tamaño de bloque en bytes.")
 
 		// Parsea los argumentos después del comando "mkfs".
 		mkfsCmd.Parse(args)
@@ -179,7 +183,7 @@ func executeCommand(commandLine string) string {
 
 		}
 		// Ejecuta la lógica del comando mkfs.
-		commands.ExecuteMkfs(*id, *typeStr, *fs)
+		commands.ExecuteMkfs(*id, *typeStr, *fs, *inodes, *ratio, *blockSize)
 
 	case "remove":
 		removeCmd := flag.NewFlagSet("remove", flag.ContinueOnError)
@@ -245,6 +249,19 @@ func executeCommand(commandLine string) string {
 
 		commands.ExecuteMove(*path, *destino)
 
+	case "ln":
+		lnCmd := flag.NewFlagSet("ln", flag.ContinueOnError)
+		path := lnCmd.String("path", "", "Ruta del archivo a enlazar.")
+		destino := lnCmd.String("destino", "", "Ruta del nuevo enlace o carpeta donde crearlo.")
+		symbolic := lnCmd.Bool("s", false, "Crea un enlace simbólico en lugar de uno duro.")
+		lnCmd.Parse(args)
+
+		if *path == "" || *destino == "" {
+			fmt.Println("Error: los parámetros -path y -destino son obligatorios para ln.")
+		} else {
+			commands.ExecuteLn(*path, *destino, *symbolic)
+		}
+
 	case "find":
 		findCmd := flag.NewFlagSet("find", flag.ContinueOnError)
 		path := findCmd.String("path", "", "Lugar donde se realizará la busqueda.")
@@ -262,21 +279,22 @@ func executeCommand(commandLine string) string {
 		chownCmd := flag.NewFlagSet("chown", flag.ContinueOnError)
 		path := chownCmd.String("path", "", "Ruta en la que se encuentra el archivo o carpeta.")
 		r := chownCmd.Bool("r", false, "Indica si sera recurivo.")
-		usuario := chownCmd.String("usuario", "", "Nombre del nuevo propietario.")
+		usuario := chownCmd.String("usuario", "", "Nombre del nuevo propietario (solo root).")
+		grp := chownCmd.String("grp", "", "Nuevo grupo (el propietario puede usar cualquiera de sus grupos).")
 
 		chownCmd.Parse(args)
 
-		if *path == "" || *usuario == "" {
-			fmt.Println("Error: Los parametros -path y -usuario son obligatorios.")
+		if *path == "" || (*usuario == "" && *grp == "") {
+			fmt.Println("Error: El parametro -path y al menos uno de -usuario o -grp son obligatorios.")
+		} else {
+			commands.ExecuteChown(*path, *r, *usuario, *grp)
 		}
 
-		commands.ExecuteChown(*path, *r, *usuario)
-
 	case "chmod":
 		chmodCmd := flag.NewFlagSet("chmod", flag.ContinueOnError)
 		path := chmodCmd.String("path", "", "Ruta a la que se cambiaran los permisos.")
 		r := chmodCmd.Bool("r", false, "Indica si el cambio será recursivo en las carpetas.")
-		ugo := chmodCmd.String("ugo", "", "Indica los permisos que se otorgarán.")
+		ugo := chmodCmd.String("ugo", "", "Permisos en octal (750) o simbólicos (u+rwx,g-w,o=r).")
 
 		chmodCmd.Parse(args)
 
@@ -286,6 +304,42 @@ func executeCommand(commandLine string) string {
 
 		commands.ExecuteChmod(*path, *ugo, *r)
 
+	case "setfacl":
+		setfaclCmd := flag.NewFlagSet("setfacl", flag.ContinueOnError)
+		path := setfaclCmd.String("path", "", "Ruta del archivo o carpeta.")
+		m := setfaclCmd.String("m", "", "Entradas a agregar o cambiar (u:usuario:rwx,g:grupo:r-x,m::rwx).")
+		x := setfaclCmd.String("x", "", "Entradas a quitar (u:usuario,g:grupo,m).")
+		b := setfaclCmd.Bool("b", false, "Elimina toda la ACL.")
+
+		setfaclCmd.Parse(args)
+
+		if *path == "" || (*m == "" && *x == "" && !*b) {
+			fmt.Println("Error: setfacl requiere -path y al menos uno de -m, -x o -b.")
+		} else {
+			commands.ExecuteSetfacl(*path, *m, *x, *b)
+		}
+
+	case "getfacl":
+		getfaclCmd := flag.NewFlagSet("getfacl", flag.ContinueOnError)
+		path := getfaclCmd.String("path", "", "Ruta del archivo o carpeta.")
+
+		getfaclCmd.Parse(args)
+
+		if *path == "" {
+			fmt.Println("Error: El parametro -path es obligatorio para getfacl.")
+		} else {
+			commands.ExecuteGetfacl(*path)
+		}
+
+	case "umask":
+		umaskCmd := flag.NewFlagSet("umask", flag.ContinueOnError)
+		user := umaskCmd.String("user", "", "Usuario (por defecto el de la sesión; solo root puede cambiar otros).")
+		mask := umaskCmd.String("mask", "", "Nueva umask en octal (ej. 022). Sin -mask se muestra la actual.")
+
+		umaskCmd.Parse(args)
+
+		commands.ExecuteUmask(*user, *mask)
+
 	case "login":
 		loginCmd := flag.NewFlagSet("login", flag.ContinueOnError)
 		user := loginCmd.String("user", "", "Usuario que va a iniciar sesion.")
@@ -371,6 +425,34 @@ func executeCommand(commandLine string) string {
 
 		commands.ExecuteRmusr(*user)
 
+	case "usermod":
+		usermodCmd := flag.NewFlagSet("usermod", flag.ContinueOnError)
+		user := usermodCmd.String("user", "", "Usuario a modificar.")
+		addgrp := usermodCmd.String("addgrp", "", "Grupo suplementario a agregar.")
+		delgrp := usermodCmd.String("delgrp", "", "Grupo suplementario a quitar.")
+
+		usermodCmd.Parse(args)
+
+		if *user == "" || (*addgrp == "" && *delgrp == "") {
+			fmt.Println("Error: usermod requiere -user y al menos uno de -addgrp o -delgrp.")
+		} else {
+			commands.ExecuteUsermod(*user, *addgrp, *delgrp)
+		}
+
+	case "passwd":
+		passwdCmd := flag.NewFlagSet("passwd", flag.ContinueOnError)
+		user := passwdCmd.String("user", "", "Usuario al que se le cambia la contraseña (por defecto el de la sesión).")
+		pass := passwdCmd.String("pass", "", "Nueva contraseña.")
+		old := passwdCmd.String("old", "", "Contraseña actual (obligatoria si no eres root).")
+
+		passwdCmd.Parse(args)
+
+		if *pass == "" {
+			fmt.Println("Error: El parametro -pass es obligatorio para passwd.")
+		} else {
+			commands.ExecutePasswd(*user, *pass, *old)
+		}
+
 	case "chgrp":
 		chgrpCmd := flag.NewFlagSet("chgrp", flag.ContinueOnError)
 		user := chgrpCmd.String("user", "", "Nombre del usuario a cambiar de grupo.")
@@ -420,6 +502,15 @@ func executeCommand(commandLine string) string {
 		path := repCmd.String("path", "", "Ruta donde se creara el reporte.")
 		id := repCmd.String("id", "", "Indica el ID de la particion.")
 		path_file_ls := repCmd.String("path_file_ls", "", "Funciona con file y ls.")
+		format := repCmd.String("format", "", "Formato de salida: png, jpg, svg, pdf, dot, html, json o txt (por defecto, el de la extensión de path).")
+		operation := repCmd.String("operation", "", "journaling: operaciones a mostrar, separadas por comas.")
+		prefix := repCmd.String("prefix", "", "journaling: solo rutas dentro de esta.")
+		user := repCmd.String("user", "", "journaling: solo operaciones de este usuario.")
+		from := repCmd.String("from", "", "journaling: desde esta fecha (2006-01-02 o 2006-01-02T15:04).")
+		to := repCmd.String("to", "", "journaling: hasta esta fecha, inclusive.")
+		depth := repCmd.Int("depth", -1, "du: niveles de subcarpetas a listar (-1 sin límite).")
+		r := repCmd.Bool("r", false, "ls: lista también las subcarpetas.")
+		sortBy := repCmd.String("sort", "name", "ls: orden por name, size, time (mtime), atime o ctime.")
 
 		repCmd.Parse(args)
 
@@ -431,17 +522,44 @@ func executeCommand(commandLine string) string {
 			fmt.Println("Error: El parametro path_file_ls es obligatorio cuando se utiliza file o ls")
 		}
 
-		commands.ExecuteRep(*name, *path, *id, *path_file_ls)
+		opts := commands.ReportOptions{
+			PathFileLS: *path_file_ls,
+			Depth:      *depth,
+			Recursive:  *r,
+			Sort:       *sortBy,
+			Journal:    commands.JournalFilter{Operation: *operation, PathPrefix: *prefix, User: *user, From: *from, To: *to},
+		}
+		commands.ExecuteRep(*name, *path, *id, opts, *format)
 
 	case "journaling":
 		journalCmd := flag.NewFlagSet("journaling", flag.ContinueOnError)
 		id := journalCmd.String("id", "", "ID de la partición a consultar journaling.")
+		operation := journalCmd.String("operation", "", "Operaciones a mostrar, separadas por comas.")
+		prefix := journalCmd.String("prefix", "", "Solo rutas dentro de esta.")
+		user := journalCmd.String("user", "", "Solo operaciones de este usuario.")
+		from := journalCmd.String("from", "", "Desde esta fecha (2006-01-02 o 2006-01-02T15:04).")
+		to := journalCmd.String("to", "", "Hasta esta fecha, inclusive.")
 		journalCmd.Parse(args)
 
 		if *id == "" {
 			fmt.Println("Error: el parámetro -id es obligatorio para journal.")
 		}
-		commands.ShowJournal(*id)
+		commands.ShowJournal(*id, commands.JournalFilter{Operation: *operation, PathPrefix: *prefix, User: *user, From: *from, To: *to})
+
+	case "audit":
+		auditCmd := flag.NewFlagSet("audit", flag.ContinueOnError)
+		id := auditCmd.String("id", "", "ID de la partición.")
+		user := auditCmd.String("user", "", "Muestra solo los eventos de este usuario.")
+		unlock := auditCmd.String("unlock", "", "Desbloquea la cuenta indicada.")
+		maxfails := auditCmd.String("maxfails", "", "Intentos fallidos seguidos que bloquean una cuenta (0 desactiva).")
+		locktime := auditCmd.String("locktime", "", "Segundos que dura el bloqueo.")
+		auditCmd.Parse(args)
+
+		if *id == "" {
+			fmt.Println("Error: el parámetro -id es obligatorio para audit.")
+		} else {
+			commands.ExecuteAudit(*id, *user, *unlock, *maxfails, *locktime)
+		}
 
 	case "recovery":
 		recoveryCmd := flag.NewFlagSet("recovery", flag.ContinueOnError)
@@ -453,6 +571,18 @@ func executeCommand(commandLine string) string {
 		}
 		commands.RecoveryFileSystem(*id)
 
+	case "fsck":
+		fsckCmd := flag.NewFlagSet("fsck", flag.ContinueOnError)
+		id := fsckCmd.String("id", "", "ID de la partición a revisar.")
+		repair := fsckCmd.Bool("repair", false, "Corrige los problemas encontrados.")
+		fsckCmd.Parse(args)
+
+		if *id == "" {
+			fmt.Println("Error: el parámetro -id es obligatorio para fsck.")
+		} else {
+			commands.ExecuteFsck(*id, *repair)
+		}
+
 	case "loss":
 		lossCmd := flag.NewFlagSet("loss", flag.ContinueOnError)
 		id := lossCmd.String("id", "", "ID de la partición a simular perdida de datos.")
@@ -463,6 +593,50 @@ func executeCommand(commandLine string) string {
 		}
 		commands.SimulateSystemLoss(*id)
 
+	case "dump":
+		dumpCmd := flag.NewFlagSet("dump", flag.ContinueOnError)
+		path := dumpCmd.String("path", "", "Ruta del disco .mia.")
+		format := dumpCmd.String("format", "json", "Formato del volcado (json).")
+		out := dumpCmd.String("out", "", "Archivo de salida; sin él se imprime.")
+		dumpCmd.Parse(args)
+
+		if *path == "" {
+			fmt.Println("Error: el parámetro -path es obligatorio para dump.")
+		} else {
+			commands.ExecuteDump(*path, *format, *out)
+		}
+
+	case "diskdiff":
+		diffCmd := flag.NewFlagSet("diskdiff", flag.ContinueOnError)
+		a := diffCmd.String("a", "", "Disco de antes.")
+		b := diffCmd.String("b", "", "Disco de después.")
+		name := diffCmd.String("name", "", "Compara solo esta partición.")
+		diffCmd.Parse(args)
+
+		if *a == "" || *b == "" {
+			fmt.Println("Error: los parámetros -a y -b son obligatorios para diskdiff.")
+		} else {
+			commands.ExecuteDiskDiff(*a, *b, *name)
+		}
+
+	case "du":
+		duCmd := flag.NewFlagSet("du", flag.ContinueOnError)
+		path := duCmd.String("path", "/", "Carpeta desde la que se suma.")
+		depth := duCmd.Int("depth", -1, "Niveles de subcarpetas a listar (-1 sin límite).")
+		duCmd.Parse(args)
+		commands.ExecuteDu(*path, *depth)
+
+	case "df":
+		dfCmd := flag.NewFlagSet("df", flag.ContinueOnError)
+		id := dfCmd.String("id", "", "ID de la partición.")
+		dfCmd.Parse(args)
+
+		if *id == "" {
+			fmt.Println("Error: el parámetro -id es obligatorio para df.")
+		} else {
+			commands.ExecuteDf(*id)
+		}
+
 	case "listdisks":
 		listCmd := flag.NewFlagSet("listdisks", flag.ContinueOnError)
 		path := listCmd.String("path", "/home/ubuntu/Calificacion_MIA/Discos", "directorio que contiene discos")
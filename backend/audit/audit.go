@@ -0,0 +1,196 @@
+// Package audit interpreta y modifica /.audit, la bitácora oculta de autenticación de
+// cada partición. Cada línea es un evento "fecha,TIPO,usuario,detalle" con la fecha en
+// segundos Unix. Los eventos POLICY guardan la política de bloqueo vigente en el detalle
+// ("intentos,segundos"); si no hay ninguno se usa DefaultPolicy.
+package audit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Path es la ruta de la bitácora dentro de la partición.
+const Path = "/.audit"
+
+// MaxSize es el tamaño máximo de /.audit en bytes. Un evento que lo haría superar rota
+// la bitácora: se conservan los eventos más recientes que entran en la mitad (ver Compact).
+const MaxSize = 16 * 1024
+
+// Kind es el tipo de un evento.
+type Kind string
+
+const (
+	Login     Kind = "LOGIN"      // inicio de sesión correcto
+	LoginFail Kind = "LOGIN_FAIL" // contraseña o usuario incorrectos
+	Locked    Kind = "LOCKED"     // intento rechazado porque la cuenta está bloqueada
+	Logout    Kind = "LOGOUT"
+	Unlock    Kind = "UNLOCK" // root desbloqueó la cuenta
+	Policy    Kind = "POLICY" // root cambió la política de bloqueo
+)
+
+// Event es una línea de la bitácora.
+type Event struct {
+	Time   time.Time
+	Kind   Kind
+	User   string
+	Detail string
+}
+
+// LockPolicy dice cuántos intentos fallidos seguidos bloquean una cuenta y por cuánto
+// tiempo. MaxFails 0 desactiva el bloqueo.
+type LockPolicy struct {
+	MaxFails int
+	LockTime time.Duration
+}
+
+// DefaultPolicy es la política de las particiones que nunca la cambiaron.
+var DefaultPolicy = LockPolicy{MaxFails: 3, LockTime: 5 * time.Minute}
+
+// Log es el contenido de la bitácora en orden cronológico.
+type Log struct {
+	Events []Event
+}
+
+// Parse interpreta el contenido de /.audit. Las líneas mal formadas se descartan.
+func Parse(content string) *Log {
+	l := &Log{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.Trim(line, " \t\r\x00")
+		fields := strings.SplitN(line, ",", 4)
+		if len(fields) < 3 {
+			continue
+		}
+		secs, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		ev := Event{Time: time.Unix(secs, 0), Kind: Kind(fields[1]), User: fields[2]}
+		if len(fields) == 4 {
+			ev.Detail = fields[3]
+		}
+		l.Events = append(l.Events, ev)
+	}
+	return l
+}
+
+// String devuelve el contenido del archivo, una línea por evento.
+func (l *Log) String() string {
+	return format(l.Events)
+}
+
+// format arma las líneas de los eventos tal como se guardan en /.audit.
+func format(events []Event) string {
+	var sb strings.Builder
+	for _, ev := range events {
+		fmt.Fprintf(&sb, "%d,%s,%s,%s\n", ev.Time.Unix(), ev.Kind, clean(ev.User), cleanDetail(ev.Detail))
+	}
+	return sb.String()
+}
+
+// Compact descarta los eventos más viejos hasta que el contenido quepa en limit bytes.
+// El último POLICY válido se conserva siempre (al principio si era de los descartados)
+// para que la rotación no devuelva la partición a DefaultPolicy.
+func (l *Log) Compact(limit int) {
+	policy := -1
+	for i, ev := range l.Events {
+		if _, ok := parsePolicy(ev); ok {
+			policy = i
+		}
+	}
+
+	size := 0
+	keep := len(l.Events)
+	for keep > 0 {
+		line := len(format(l.Events[keep-1 : keep]))
+		if size+line > limit {
+			break
+		}
+		size += line
+		keep--
+	}
+	kept := l.Events[keep:]
+	if policy >= 0 && policy < keep {
+		kept = append([]Event{l.Events[policy]}, kept...)
+	}
+	l.Events = append([]Event(nil), kept...)
+}
+
+// Add agrega un evento al final.
+func (l *Log) Add(kind Kind, user, detail string, now time.Time) {
+	l.Events = append(l.Events, Event{Time: now, Kind: kind, User: user, Detail: detail})
+}
+
+// SetPolicy agrega un evento POLICY con la política nueva.
+func (l *Log) SetPolicy(by string, p LockPolicy, now time.Time) {
+	l.Add(Policy, by, fmt.Sprintf("%d,%d", p.MaxFails, int64(p.LockTime/time.Second)), now)
+}
+
+// Policy devuelve la política vigente: la del último evento POLICY válido.
+func (l *Log) Policy() LockPolicy {
+	p := DefaultPolicy
+	for _, ev := range l.Events {
+		if policy, ok := parsePolicy(ev); ok {
+			p = policy
+		}
+	}
+	return p
+}
+
+// parsePolicy interpreta el detalle de un evento POLICY; ok es false si el evento no es
+// POLICY o su detalle no es válido.
+func parsePolicy(ev Event) (LockPolicy, bool) {
+	if ev.Kind != Policy {
+		return LockPolicy{}, false
+	}
+	var fails, secs int
+	if _, err := fmt.Sscanf(ev.Detail, "%d,%d", &fails, &secs); err != nil || fails < 0 || secs < 0 {
+		return LockPolicy{}, false
+	}
+	return LockPolicy{MaxFails: fails, LockTime: time.Duration(secs) * time.Second}, true
+}
+
+// LockedUntil indica si la cuenta está bloqueada en now y hasta cuándo. Cuenta los
+// LOGIN_FAIL seguidos del usuario: un LOGIN o UNLOCK reinicia la cuenta, y también
+// un fallo que llega cuando el bloqueo anterior ya venció.
+func (l *Log) LockedUntil(user string, now time.Time) (time.Time, bool) {
+	p := l.Policy()
+	if p.MaxFails == 0 {
+		return time.Time{}, false
+	}
+
+	fails := 0
+	var until time.Time
+	for _, ev := range l.Events {
+		if clean(ev.User) != clean(user) {
+			continue
+		}
+		switch ev.Kind {
+		case Login, Unlock:
+			fails = 0
+		case LoginFail:
+			if fails >= p.MaxFails && !ev.Time.Before(until) {
+				fails = 0
+			}
+			fails++
+			if fails == p.MaxFails {
+				until = ev.Time.Add(p.LockTime)
+			}
+		}
+	}
+	if fails >= p.MaxFails && now.Before(until) {
+		return until, true
+	}
+	return time.Time{}, false
+}
+
+// clean quita del nombre lo que rompería el formato de la línea.
+func clean(s string) string {
+	return strings.NewReplacer(",", "_", "\n", "_", "\r", "_").Replace(s)
+}
+
+// cleanDetail es como clean pero conserva las comas: el detalle es el último campo.
+func cleanDetail(s string) string {
+	return strings.NewReplacer("\n", " ", "\r", " ").Replace(s)
+}
@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+var t0 = time.Unix(1_700_000_000, 0)
+
+// at devuelve el instante t0 + s segundos.
+func at(s int) time.Time { return t0.Add(time.Duration(s) * time.Second) }
+
+func TestLockedUntil(t *testing.T) {
+	type ev struct {
+		s    int
+		kind Kind
+		user string
+	}
+	fails := func(user string, secs ...int) []ev {
+		var out []ev
+		for _, s := range secs {
+			out = append(out, ev{s, LoginFail, user})
+		}
+		return out
+	}
+	tests := []struct {
+		name      string
+		policy    *LockPolicy // nil: DefaultPolicy (3 intentos, 5 minutos)
+		events    []ev
+		now       int
+		wantLock  bool
+		wantUntil int
+	}{
+		{name: "sin eventos", now: 10},
+		{name: "dos fallos no bloquean", events: fails("ana", 1, 2), now: 3},
+		{name: "tres fallos bloquean", events: fails("ana", 1, 2, 3), now: 4, wantLock: true, wantUntil: 303},
+		{name: "justo antes de vencer", events: fails("ana", 1, 2, 3), now: 302, wantLock: true, wantUntil: 303},
+		{name: "el bloqueo vence", events: fails("ana", 1, 2, 3), now: 303},
+		{name: "fallos durante el bloqueo no lo alargan", events: fails("ana", 1, 2, 3, 100, 200), now: 302, wantLock: true, wantUntil: 303},
+		{name: "un fallo tras vencer empieza de cero", events: fails("ana", 1, 2, 3, 400), now: 401},
+		{name: "tres fallos tras vencer vuelven a bloquear", events: fails("ana", 1, 2, 3, 400, 401, 402), now: 403, wantLock: true, wantUntil: 702},
+		{
+			name:   "un login correcto reinicia la cuenta",
+			events: append(append(fails("ana", 1, 2), ev{3, Login, "ana"}), fails("ana", 4, 5)...),
+			now:    6,
+		},
+		{
+			name:   "unlock levanta el bloqueo",
+			events: append(fails("ana", 1, 2, 3), ev{10, Unlock, "ana"}),
+			now:    11,
+		},
+		{
+			name:   "los fallos de otro usuario no cuentan",
+			events: append(fails("ana", 1, 2), fails("luis", 3)...),
+			now:    4,
+		},
+		{
+			name:   "solo se bloquea la cuenta con fallos",
+			events: append(fails("luis", 1, 2, 3), fails("ana", 4)...),
+			now:    5,
+		},
+		{name: "política de 2 intentos", policy: &LockPolicy{2, time.Minute}, events: fails("ana", 1, 2), now: 3, wantLock: true, wantUntil: 62},
+		{name: "maxfails 0 desactiva el bloqueo", policy: &LockPolicy{0, time.Minute}, events: fails("ana", 1, 2, 3, 4, 5), now: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Log{}
+			if tt.policy != nil {
+				l.SetPolicy("root", *tt.policy, at(0))
+			}
+			for _, e := range tt.events {
+				l.Add(e.kind, e.user, "", at(e.s))
+			}
+			// El resultado no puede depender de guardar y volver a leer la bitácora
+			for _, log := range []*Log{l, Parse(l.String())} {
+				until, locked := log.LockedUntil("ana", at(tt.now))
+				if locked != tt.wantLock {
+					t.Fatalf("bloqueada = %v, se esperaba %v", locked, tt.wantLock)
+				}
+				if locked && !until.Equal(at(tt.wantUntil)) {
+					t.Errorf("bloqueada hasta %v, se esperaba %v", until.Sub(t0), at(tt.wantUntil).Sub(t0))
+				}
+			}
+		})
+	}
+}
+
+func TestParseString(t *testing.T) {
+	in := "1700000000,LOGIN,root,\n" +
+		"basura\n" +
+		"x,LOGIN,ana,\n" +
+		"1700000005,POLICY,root,5,600\n" +
+		"1700000009,LOGIN_FAIL,ana\r\n"
+	l := Parse(in)
+	if len(l.Events) != 3 {
+		t.Fatalf("%d eventos, se esperaban 3 (las líneas mal formadas se descartan)", len(l.Events))
+	}
+	want := "1700000000,LOGIN,root,\n1700000005,POLICY,root,5,600\n1700000009,LOGIN_FAIL,ana,\n"
+	if got := l.String(); got != want {
+		t.Errorf("String() = %q, se esperaba %q", got, want)
+	}
+	if p := l.Policy(); p != (LockPolicy{5, 10 * time.Minute}) {
+		t.Errorf("Policy() = %+v", p)
+	}
+
+	// Un usuario con coma o salto de línea no puede romper el formato
+	l.Add(LoginFail, "a,b\nc", "x\ny", at(20))
+	again := Parse(l.String())
+	if n := len(again.Events); n != 4 || again.Events[3].User != "a_b_c" || again.Events[3].Detail != "x y" {
+		t.Errorf("evento con caracteres especiales = %+v", again.Events[n-1])
+	}
+}
+
+func TestPolicyIgnoresInvalid(t *testing.T) {
+	l := Parse("1,POLICY,root,2,60\n2,POLICY,root,-1,60\n3,POLICY,root,nada\n")
+	if p := l.Policy(); p != (LockPolicy{2, time.Minute}) {
+		t.Errorf("Policy() = %+v, se esperaba la última válida", p)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	l := &Log{}
+	l.SetPolicy("root", LockPolicy{4, time.Hour}, at(0))
+	l.Add(Login, "root", "", at(1))
+	for i := 0; i < 200; i++ {
+		l.Add(LoginFail, "ana", "", at(10+i))
+	}
+	newest := l.Events[len(l.Events)-1]
+
+	l.Compact(MaxSize / 8)
+	if size := len(l.String()); size > MaxSize/8+len(format(l.Events[:1])) {
+		t.Errorf("después de Compact el contenido ocupa %d bytes", size)
+	}
+	if l.Events[0].Kind != Policy || l.Policy() != (LockPolicy{4, time.Hour}) {
+		t.Errorf("Compact descartó la política: %+v", l.Events[0])
+	}
+	if last := l.Events[len(l.Events)-1]; last != newest {
+		t.Errorf("el último evento es %+v, se esperaba %+v", last, newest)
+	}
+	for _, ev := range l.Events[1:] {
+		if ev.Kind != LoginFail {
+			t.Fatalf("quedó un evento viejo: %+v", ev)
+		}
+	}
+	// La cuenta sigue bloqueada: los fallos recientes se conservan
+	if _, locked := l.LockedUntil("ana", at(210)); !locked {
+		t.Error("después de Compact la cuenta dejó de estar bloqueada")
+	}
+}
@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+
+	"proyecto1/fs"
+	"proyecto1/structs"
+)
+
+// Load lee /.audit de la partición. Si el archivo todavía no existe devuelve una
+// bitácora vacía y exists en false; quien la guarde tiene que crearlo primero.
+func Load(file *os.File, sb structs.Superblock) (l *Log, exists bool, err error) {
+	inode, _, err := fs.FindInodeByPathNoCheck(file, sb, Path)
+	if err != nil {
+		return Parse(""), false, nil
+	}
+	content, err := fs.ReadFileContent(file, sb, inode)
+	if err != nil {
+		return nil, true, fmt.Errorf("error al leer %s: %w", Path, err)
+	}
+	return Parse(string(content)), true, nil
+}
+
+// Append agrega al final de /.audit los eventos l.Events[from:], sin reescribir los
+// anteriores. Si el archivo pasaría de MaxSize, rota: compacta l a la mitad de MaxSize y
+// reescribe el archivo de forma atómica. El archivo tiene que existir.
+func Append(file *os.File, sb structs.Superblock, l *Log, from int) error {
+	inode, inodeIndex, err := fs.FindInodeByPathNoCheck(file, sb, Path)
+	if err != nil {
+		return fmt.Errorf("no se encontró %s: %w", Path, err)
+	}
+	lines := format(l.Events[from:])
+	if int(inode.I_size)+len(lines) > MaxSize {
+		l.Compact(MaxSize / 2)
+		err = fs.ReplaceFileContent(file, sb, inodeIndex, []byte(l.String()))
+	} else {
+		err = fs.AppendFileContent(file, sb, inodeIndex, []byte(lines))
+	}
+	if err != nil {
+		return fmt.Errorf("error al escribir %s: %w", Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"proyecto1/audit"
+	"proyecto1/fs"
+	"proyecto1/state"
+	"proyecto1/structs"
+	"proyecto1/users"
+)
+
+// ExecuteAudit muestra la bitácora de autenticación de una partición (solo root con sesión
+// en esa partición). user filtra los eventos; unlock desbloquea una cuenta; maxFails y
+// lockTime (en segundos) cambian la política de bloqueo. Sin cambios, solo muestra.
+func ExecuteAudit(id, user, unlock, maxFails, lockTime string) {
+	mountedPartition, found := state.GetMountedPartitionByID(id)
+	if !found {
+		fmt.Printf("Error: No se encontró la partición montada con el id '%s'.\n", id)
+		return
+	}
+	if !state.CurrentSession.IsActive || state.CurrentSession.User != users.RootName ||
+		state.CurrentSession.PartitionID != id {
+		fmt.Println("Error: Solo root, con sesión en la partición, puede usar audit.")
+		return
+	}
+
+	file, err := os.OpenFile(mountedPartition.Path, os.O_RDWR, 0644)
+	if err != nil {
+		fmt.Println("Error al abrir el disco:", err)
+		return
+	}
+	defer file.Close()
+
+	var sb structs.Superblock
+	file.Seek(mountedPartition.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		fmt.Println("Error al leer el superbloque:", err)
+		return
+	}
+
+	log, exists, err := audit.Load(file, sb)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	now := time.Now()
+	from := len(log.Events)
+	if maxFails != "" || lockTime != "" {
+		policy := log.Policy()
+		if maxFails != "" {
+			n, err := strconv.Atoi(maxFails)
+			if err != nil || n < 0 {
+				fmt.Println("Error: -maxfails debe ser un entero mayor o igual a 0 (0 desactiva el bloqueo).")
+				return
+			}
+			policy.MaxFails = n
+		}
+		if lockTime != "" {
+			secs, err := strconv.Atoi(lockTime)
+			if err != nil || secs <= 0 {
+				fmt.Println("Error: -locktime debe ser una cantidad de segundos mayor que 0.")
+				return
+			}
+			policy.LockTime = time.Duration(secs) * time.Second
+		}
+		log.SetPolicy(state.CurrentSession.User, policy, now)
+		fmt.Printf("Política de bloqueo: %s\n", describePolicy(policy))
+	}
+	if unlock != "" {
+		if _, locked := log.LockedUntil(unlock, now); !locked {
+			fmt.Printf("La cuenta '%s' no está bloqueada.\n", unlock)
+		} else {
+			log.Add(audit.Unlock, unlock, "por "+state.CurrentSession.User, now)
+			fmt.Printf("Cuenta '%s' desbloqueada.\n", unlock)
+		}
+	}
+	if len(log.Events) > from {
+		if err := saveAuditLog(file, sb, log, exists, from); err != nil {
+			fmt.Println("Error:", err)
+		}
+		return
+	}
+
+	fmt.Printf("Bitácora de autenticación - Partición: %s\n", mountedPartition.Name)
+	fmt.Printf("Política de bloqueo: %s\n", describePolicy(log.Policy()))
+	fmt.Printf("%-19s  %-10s  %-12s  %s\n", "Fecha", "Evento", "Usuario", "Detalle")
+	shown := 0
+	seen := map[string]bool{}
+	var lockedUsers []string
+	for _, ev := range log.Events {
+		if user != "" && ev.User != user {
+			continue
+		}
+		fmt.Printf("%-19s  %-10s  %-12s  %s\n", ev.Time.Format("2006-01-02 15:04:05"), ev.Kind, ev.User, ev.Detail)
+		shown++
+		if !seen[ev.User] {
+			seen[ev.User] = true
+			if until, locked := log.LockedUntil(ev.User, now); locked {
+				lockedUsers = append(lockedUsers, fmt.Sprintf("%s (hasta %s)", ev.User, until.Format("15:04:05")))
+			}
+		}
+	}
+	if shown == 0 {
+		fmt.Println("(sin eventos)")
+	}
+	for _, u := range lockedUsers {
+		fmt.Println("Cuenta bloqueada:", u)
+	}
+}
+
+// describePolicy resume la política de bloqueo para mostrarla.
+func describePolicy(p audit.LockPolicy) string {
+	if p.MaxFails == 0 {
+		return "desactivada"
+	}
+	return fmt.Sprintf("%d intento(s) fallido(s) seguidos bloquean la cuenta por %s", p.MaxFails, p.LockTime)
+}
+
+// recordAuthEvent agrega un evento a la bitácora de la partición, creándola si hace
+// falta. Un error aquí no debe impedir el login o logout: solo se avisa.
+func recordAuthEvent(file *os.File, sb structs.Superblock, log *audit.Log, exists bool, kind audit.Kind, user, detail string) {
+	from := len(log.Events)
+	log.Add(kind, user, detail, time.Now())
+	if err := saveAuditLog(file, sb, log, exists, from); err != nil {
+		fmt.Println("Aviso: no se pudo registrar el evento en la bitácora:", err)
+	}
+}
+
+// saveAuditLog agrega a la bitácora los eventos log.Events[from:]; si el archivo no
+// existe lo crea primero.
+func saveAuditLog(file *os.File, sb structs.Superblock, log *audit.Log, exists bool, from int) error {
+	if !exists {
+		if err := createAuditFile(file, sb); err != nil {
+			return err
+		}
+	}
+	return audit.Append(file, sb, log, from)
+}
+
+// isAuditEntry indica si la entrada name de la carpeta dirIndex es la bitácora, que ls,
+// find, tree y el explorador de archivos no muestran.
+func isAuditEntry(dirIndex int32, name string) bool {
+	return dirIndex == 0 && name == audit.Path[1:]
+}
+
+// auditNodes devuelve el inodo de la bitácora y sus bloques (datos, apuntadores y ACL),
+// para que el reporte tree los omita. Sin bitácora devuelve -1 y un mapa vacío.
+func auditNodes(file *os.File, sb structs.Superblock) (int32, map[int32]bool) {
+	blocks := make(map[int32]bool)
+	inode, index, err := fs.FindInodeByPathNoCheck(file, sb, audit.Path)
+	if err != nil {
+		return -1, blocks
+	}
+	dataBlocks, pointerBlocks, _ := fs.InodeBlocks(file, sb, inode)
+	for _, b := range append(dataBlocks, pointerBlocks...) {
+		blocks[b] = true
+	}
+	if fs.HasACL(inode) {
+		blocks[inode.I_acl] = true
+	}
+	return index, blocks
+}
+
+// createAuditFile crea /.audit vacío, de root y solo legible por root (600).
+func createAuditFile(file *os.File, sb structs.Superblock) error {
+	inodeIndex, err := fs.FindFreeInode(file, sb)
+	if err != nil {
+		return err
+	}
+	fs.MarkInodeAsUsed(file, sb, inodeIndex)
+
+	now := time.Now().Unix()
+	var inode structs.Inode
+	inode.I_uid = fs.RootUID
+	inode.I_gid = fs.RootUID
+	inode.I_atime = now
+	inode.I_ctime = now
+	inode.I_mtime = now
+	inode.I_type = 1
+	fs.SetPerm(&inode, 0o600)
+	inode.I_links = 1
+	inode.I_acl = -1
+	for i := range inode.I_block {
+		inode.I_block[i] = -1
+	}
+	if err := fs.WriteInode(file, sb, inodeIndex, inode); err != nil {
+		return err
+	}
+	return addEntryToParent(file, sb, 0, audit.Path[1:], inodeIndex)
+}
@@ -1,14 +1,12 @@
 package commands
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
-	"io"
 	"os"
 	"proyecto1/state"
 	"proyecto1/structs"
-	"strings"
+	"proyecto1/users"
 )
 
 func ExecuteChgrp(user, newGroup string) {
@@ -50,156 +48,28 @@ func ExecuteChgrp(user, newGroup string) {
 		return
 	}
 
-	// Leer inodo raíz
-	var currentInode structs.Inode
-	file.Seek(int64(sb.S_inode_start), 0)
-	if err := binary.Read(file, binary.BigEndian, &currentInode); err != nil {
-		fmt.Println("Error al leer el inodo raíz:", err)
-		return
-	}
-
-	// Buscar el inodo de /users.txt
-	path := "/users.txt"
-	parts := strings.Split(path, "/")
-	for i := 1; i < len(parts); i++ {
-		name := parts[i]
-		if name == "" {
-			continue
-		}
-
-		found := false
-		for _, blockNum := range currentInode.I_block {
-			if blockNum == -1 {
-				continue
-			}
-			blockPos := int64(sb.S_block_start) + int64(blockNum)*int64(sb.S_block_size)
-			var folderBlock structs.FolderBlock
-			file.Seek(blockPos, 0)
-			if err := binary.Read(file, binary.BigEndian, &folderBlock); err != nil {
-				fmt.Println("Error al leer bloque de carpeta:", err)
-				return
-			}
-
-			for _, entry := range folderBlock.B_content {
-				entryName := string(bytes.Trim(entry.B_name[:], "\x00"))
-				if entryName == name {
-					file.Seek(int64(sb.S_inode_start)+int64(entry.B_inodo)*int64(sb.S_inode_size), 0)
-					if err := binary.Read(file, binary.BigEndian, &currentInode); err != nil {
-						fmt.Println("Error al leer inodo:", err)
-						return
-					}
-					found = true
-					break
-				}
-			}
-			if found {
-				break
-			}
-		}
-
-		if !found {
-			fmt.Printf("Error: No se encontró '%s' en la ruta.\n", name)
-			return
-		}
-	}
-
-	// Leer contenido actual de /users.txt
-	var content strings.Builder
-	for _, blockNum := range currentInode.I_block {
-		if blockNum == -1 {
-			continue
-		}
-		blockPos := int64(sb.S_block_start) + int64(blockNum)*int64(sb.S_block_size)
-		blockData := make([]byte, sb.S_block_size)
-		file.Seek(blockPos, 0)
-		if _, err := io.ReadFull(file, blockData); err != nil {
-			fmt.Println("Error al leer bloque del archivo:", err)
-			return
-		}
-		content.Write(bytes.Trim(blockData, "\x00"))
-	}
-
-	// Procesar líneas
-	lines := strings.Split(content.String(), "\n")
-	userFound := false
-	groupExists := false
-
-	// Verificar que el grupo existe
-	for _, line := range lines {
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-		parts := strings.Split(line, ",")
-		if len(parts) >= 3 && parts[1] == "G" && parts[2] == newGroup && parts[0] != "0" {
-			groupExists = true
-			break
-		}
-	}
-
-	if !groupExists {
-		fmt.Printf("Error: El grupo '%s' no existe.\n", newGroup)
+	// Leer users.txt
+	table, err := users.Load(file, sb)
+	if err != nil {
+		fmt.Println("Error:", err)
 		return
 	}
 
 	// Modificar el grupo del usuario
-	for i, line := range lines {
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-		parts := strings.Split(line, ",")
-		if len(parts) >= 4 && parts[1] == "U" && parts[3] == user {
-			if parts[0] == "0" {
-				fmt.Printf("Error: El usuario '%s' está eliminado.\n", user)
-				return
-			}
-			parts[2] = newGroup // cambiar grupo
-			lines[i] = strings.Join(parts, ",")
-			userFound = true
-			break
-		}
+	if err := table.SetUserGroup(user, newGroup); err != nil {
+		fmt.Println("Error:", err)
+		return
 	}
 
-	if !userFound {
-		fmt.Printf("Error: El usuario '%s' no existe.\n", user)
+	if err := users.Save(file, sb, table); err != nil {
+		fmt.Println("Error:", err)
 		return
 	}
 
-	// Nuevo contenido
-	newContent := strings.Join(lines, "\n")
-
-	// Escribir de nuevo
-	data := []byte(newContent)
-	offset := 0
-	for _, blockNum := range currentInode.I_block {
-		if blockNum == -1 {
-			continue
-		}
-		blockPos := int64(sb.S_block_start) + int64(blockNum)*int64(sb.S_block_size)
-		blockSize := int(sb.S_block_size)
-
-		end := offset + blockSize
-		if end > len(data) {
-			end = len(data)
-		}
-		chunk := data[offset:end]
-
-		// Rellenar con ceros
-		if len(chunk) < blockSize {
-			padded := make([]byte, blockSize)
-			copy(padded, chunk)
-			chunk = padded
-		}
-
-		file.Seek(blockPos, 0)
-		if _, err := file.Write(chunk); err != nil {
-			fmt.Println("Error al escribir bloque:", err)
-			return
-		}
-
-		offset += blockSize
-		if offset >= len(data) {
-			break
-		}
+	// Si se cambió al usuario de la sesión, su grupo cambia de inmediato
+	if user == state.CurrentSession.User {
+		_, state.CurrentSession.GID, _ = table.IDs(user)
+		state.CurrentSession.Groups = table.GIDs(user)
 	}
 
 	fmt.Printf("Usuario '%s' cambiado exitosamente al grupo '%s'.\n", user, newGroup)
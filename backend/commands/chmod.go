@@ -4,7 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"os"
-	"strconv"
+	pathpkg "path"
 	"strings"
 
 	"proyecto1/fs"
@@ -14,9 +14,11 @@ import (
 
 // ======================== ExecuteChmod ========================
 //
-// Cambia los permisos (ugo) de un archivo o carpeta.
-// Solo el usuario root puede ejecutarlo.
-// Si se usa -r y el path apunta a una carpeta, el cambio será recursivo.
+// Cambia los permisos de un archivo o carpeta. -ugo acepta octal (750) o modo
+// simbólico (u+rwx,g-w,o=r), que se aplica sobre los permisos actuales de cada inodo.
+// Solo el propietario del inodo o root pueden ejecutarlo.
+// Si se usa -r y el path apunta a una carpeta, el cambio será recursivo; los inodos
+// que no pertenecen a quien ejecuta se omiten y se listan al final.
 //
 func ExecuteChmod(path string, ugo string, recursive bool) {
 	// Validar sesión activa
@@ -25,27 +27,9 @@ func ExecuteChmod(path string, ugo string, recursive bool) {
 		return
 	}
 
-	// Solo root puede ejecutar chmod
-	if state.CurrentSession.User != "root" {
-		fmt.Println("Error: Solo el usuario root puede ejecutar chmod.")
-		return
-	}
-
-	// Validar parámetro -ugo
-	if len(ugo) != 3 {
-		fmt.Println("Error: El parámetro -ugo debe tener exactamente 3 dígitos (U,G,O).")
-		return
-	}
-	for _, c := range ugo {
-		if c < '0' || c > '7' {
-			fmt.Println("Error: Cada dígito del parámetro -ugo debe estar entre 0 y 7.")
-			return
-		}
-	}
-
-	permInt, err := strconv.Atoi(ugo)
-	if err != nil {
-		fmt.Println("Error: No se pudo convertir el parámetro -ugo a número entero.")
+	// Validar parámetro -ugo antes de tocar el disco
+	if _, err := fs.ParseMode(ugo, 0, true); err != nil {
+		fmt.Println("Error:", err)
 		return
 	}
 
@@ -85,27 +69,38 @@ func ExecuteChmod(path string, ugo string, recursive bool) {
 		return
 	}
 
+	// Solo el propietario (o root) puede cambiar los permisos
+	cred := fs.SessionCred()
+	if !esPropietario(inode, cred) {
+		fmt.Println("Error: Solo el propietario o root pueden cambiar los permisos de", path)
+		return
+	}
+
 	// Cambiar permisos del inodo principal
-	inode.I_perm = int32(permInt)
+	perm, _ := fs.ParseMode(ugo, fs.PermOf(inode), inode.I_type == 0)
+	fs.SetPerm(&inode, perm)
 	fs.WriteInode(file, sb, inodeIndex, inode)
 
-	fmt.Println("Permisos cambiados a", ugo, "en", path)
+	fmt.Printf("Permisos cambiados a %s (%s) en %s\n", perm.Octal(), perm, path)
 
 	// Si se especificó -r y el inodo es carpeta, aplicar recursivamente
 	if recursive && inode.I_type == 0 {
-		aplicarChmodRecursivo(file, sb, inodeIndex, int32(permInt))
+		omitidos := aplicarChmodRecursivo(file, sb, inodeIndex, path, ugo, cred)
+		reportarOmitidos(omitidos)
 	}
 }
 
 // ======================== aplicarChmodRecursivo ========================
 //
 // Recorre todos los subdirectorios y archivos dentro de un inodo tipo carpeta,
-// cambiando sus permisos.
+// cambiando sus permisos según el modo (ya validado). Devuelve las rutas que se
+// omitieron por no pertenecer a cred.
 //
-func aplicarChmodRecursivo(file *os.File, sb structs.Superblock, inodeIndex int32, perm int32) {
+func aplicarChmodRecursivo(file *os.File, sb structs.Superblock, inodeIndex int32, dirPath string, mode string, cred fs.Cred) []string {
+	var omitidos []string
 	inode, err := fs.ReadInode(file, sb, inodeIndex)
 	if err != nil {
-		return
+		return omitidos
 	}
 
 	for _, block := range inode.I_block {
@@ -132,14 +127,39 @@ func aplicarChmodRecursivo(file *os.File, sb structs.Superblock, inodeIndex int3
 			if err != nil {
 				continue
 			}
+			childPath := pathpkg.Join(dirPath, name)
+			if !esPropietario(childInode, cred) {
+				omitidos = append(omitidos, childPath)
+				continue
+			}
 
-			childInode.I_perm = perm
+			perm, _ := fs.ParseMode(mode, fs.PermOf(childInode), childInode.I_type == 0)
+			fs.SetPerm(&childInode, perm)
 			fs.WriteInode(file, sb, entry.B_inodo, childInode)
 
 			// Si es carpeta, aplicar recursivamente
 			if childInode.I_type == 0 {
-				aplicarChmodRecursivo(file, sb, entry.B_inodo, perm)
+				omitidos = append(omitidos, aplicarChmodRecursivo(file, sb, entry.B_inodo, childPath, mode, cred)...)
 			}
 		}
 	}
+	return omitidos
+}
+
+// esPropietario indica si cred puede administrar el inodo (chmod, cambio de grupo):
+// solo su propietario o root.
+func esPropietario(inode structs.Inode, cred fs.Cred) bool {
+	return cred.IsRoot() || inode.I_uid == cred.UID
+}
+
+// reportarOmitidos lista los inodos que un cambio recursivo no tocó por no pertenecer
+// a quien lo ejecuta.
+func reportarOmitidos(omitidos []string) {
+	if len(omitidos) == 0 {
+		return
+	}
+	fmt.Printf("Se omitieron %d elemento(s) que no te pertenecen:\n", len(omitidos))
+	for _, p := range omitidos {
+		fmt.Println(" -", p)
+	}
 }
\ No newline at end of file
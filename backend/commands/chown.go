@@ -4,15 +4,21 @@ import (
     "encoding/binary"
     "fmt"
     "os"
+    pathpkg "path"
     "strings"
     "proyecto1/fs"
     "proyecto1/state"
     "proyecto1/structs"
+    "proyecto1/users"
 )
 
 // ================= Ejecutables =================
 
-func ExecuteChown(path string, recursive bool, newUser string) {
+// ExecuteChown cambia el propietario (newUser) y/o el grupo (newGroup) de un archivo o
+// carpeta. Cambiar el propietario solo lo puede hacer root; el propietario de un inodo
+// puede cambiarle el grupo a cualquiera de los grupos a los que pertenece.
+// Sin newGroup, el grupo pasa a ser el grupo principal del nuevo propietario.
+func ExecuteChown(path string, recursive bool, newUser string, newGroup string) {
     if !state.CurrentSession.IsActive {
         fmt.Println("Error: Debes iniciar sesión para usar chown.")
         return
@@ -45,18 +51,41 @@ func ExecuteChown(path string, recursive bool, newUser string) {
         return
     }
 
-    // Obtener IDs de usuario actual
-    currentUID, _, err := getUserIDs(file, sb, state.CurrentSession.User)
+    cred := fs.SessionCred()
+    table, err := users.Load(file, sb)
     if err != nil {
-        fmt.Println("Error al obtener UID/GID del usuario actual:", err)
+        fmt.Println("Error:", err)
         return
     }
 
-    // Obtener IDs del nuevo propietario
-    newUID, newGID, err := getUserIDs(file, sb, newUser)
-    if err != nil {
-        fmt.Println("Error: el usuario", newUser, "no existe.")
-        return
+    // -1 = no cambiar
+    newUID, newGID := int32(-1), int32(-1)
+
+    // Nuevo propietario: solo root
+    if newUser != "" {
+        if !cred.IsRoot() {
+            fmt.Println("Error: Solo el usuario root puede cambiar el propietario.")
+            return
+        }
+        newUID, newGID, err = table.IDs(newUser)
+        if err != nil {
+            fmt.Println("Error: el usuario", newUser, "no existe.")
+            return
+        }
+    }
+
+    // Nuevo grupo: root cualquiera; los demás, uno de sus grupos
+    if newGroup != "" {
+        g := table.Group(newGroup)
+        if g == nil || g.Deleted() {
+            fmt.Printf("Error: el grupo '%s' no existe.\n", newGroup)
+            return
+        }
+        if !cred.IsRoot() && !cred.InGroup(g.GID) {
+            fmt.Printf("Error: no perteneces al grupo '%s'.\n", newGroup)
+            return
+        }
+        newGID = g.GID
     }
 
     // Buscar inodo del archivo o carpeta
@@ -67,29 +96,45 @@ func ExecuteChown(path string, recursive bool, newUser string) {
     }
 
     // Verificar permisos
-    if state.CurrentSession.User != "root" && inode.I_uid != currentUID {
-        fmt.Println("Error: no tienes permisos para cambiar propietario de este archivo.")
+    if !esPropietario(inode, cred) {
+        fmt.Println("Error: Solo el propietario o root pueden cambiar el grupo de", path)
         return
     }
 
-    // Cambiar propietario
-    inode.I_uid = newUID
-    inode.I_gid = newGID
+    // Cambiar propietario y/o grupo
+    aplicarChown(&inode, newUID, newGID)
     fs.WriteInode(file, sb, inodeIndex, inode)
 
+    if newUser != "" {
+        fmt.Println("Propietario cambiado correctamente a", newUser, "en:", path)
+    }
+    if newGroup != "" {
+        fmt.Println("Grupo cambiado correctamente a", newGroup, "en:", path)
+    }
+
     // Si es recursivo y es una carpeta, aplicar a su contenido
     if recursive && inode.I_type == 0 {
-        applyChownRecursive(file, sb, inodeIndex, newUID, newGID)
+        omitidos := applyChownRecursive(file, sb, inodeIndex, path, newUID, newGID, cred)
+        reportarOmitidos(omitidos)
     }
+}
 
-    fmt.Println("Propietario cambiado correctamente a", newUser, "en:", path)
+// aplicarChown cambia el UID y el GID del inodo; -1 deja el valor actual.
+func aplicarChown(inode *structs.Inode, newUID, newGID int32) {
+    if newUID != -1 {
+        inode.I_uid = newUID
+    }
+    if newGID != -1 {
+        inode.I_gid = newGID
+    }
 }
 
-// Función recursiva auxiliar
-func applyChownRecursive(file *os.File, sb structs.Superblock, inodeIndex, newUID, newGID int32) {
+// Función recursiva auxiliar. Devuelve las rutas omitidas por no pertenecer a cred.
+func applyChownRecursive(file *os.File, sb structs.Superblock, inodeIndex int32, dirPath string, newUID, newGID int32, cred fs.Cred) []string {
+    var omitidos []string
     inode, err := fs.ReadInode(file, sb, inodeIndex)
     if err != nil {
-        return
+        return omitidos
     }
 
     for _, block := range inode.I_block {
@@ -112,16 +157,21 @@ func applyChownRecursive(file *os.File, sb structs.Superblock, inodeIndex, newUI
             if err != nil {
                 continue
             }
+            childPath := pathpkg.Join(dirPath, name)
+            if !esPropietario(childInode, cred) {
+                omitidos = append(omitidos, childPath)
+                continue
+            }
 
             // Cambiar propietario
-            childInode.I_uid = newUID
-            childInode.I_gid = newGID
+            aplicarChown(&childInode, newUID, newGID)
             fs.WriteInode(file, sb, entry.B_inodo, childInode)
 
             // Si es carpeta, aplicar recursivamente
             if childInode.I_type == 0 {
-                applyChownRecursive(file, sb, entry.B_inodo, newUID, newGID)
+                omitidos = append(omitidos, applyChownRecursive(file, sb, entry.B_inodo, childPath, newUID, newGID, cred)...)
             }
         }
     }
+    return omitidos
 }
@@ -57,7 +57,7 @@ func ExecuteCopy(srcPath string, destPath string) {
 		return
 	}
 
-	if !tienePermisoLectura(srcInode, uid, gid) {
+	if !tienePermisoLectura(file, sb, srcInode, uid, gid) {
 		fmt.Println("Error: no tienes permiso de lectura sobre el origen.")
 		return
 	}
@@ -116,7 +116,7 @@ func ExecuteCopy(srcPath string, destPath string) {
 	}
 
 	// Permiso escritura en carpeta destino
-	if !tienePermisoEscritura(parentInode, uid, gid) {
+	if !tienePermisoEscritura(file, sb, parentInode, uid, gid) {
 		fmt.Println("Error: no tienes permiso de escritura en la carpeta destino.")
 		return
 	}
@@ -193,32 +193,14 @@ func writeFileToParent(file *os.File, sb structs.Superblock, parentInodeIndex in
 		if err != nil {
 			return fmt.Errorf("error leyendo inodo existente: %v", err)
 		}
-		// Limpiar punteros actuales (no implementamos liberación de bloques aquí; simplemente sobrescribimos)
-		for i := range inode.I_block {
-			inode.I_block[i] = -1
+		// Reemplazar el contenido (libera los bloques anteriores)
+		if err := fs.WriteFileContent(file, sb, &inode, data); err != nil {
+			return fmt.Errorf("error escribiendo contenido: %v", err)
 		}
-		// Escribir datos en nuevos bloques
-		blockSize := int(sb.S_block_size)
-		offset := 0
-		for i := 0; i < len(inode.I_block) && offset < len(data); i++ {
-			bIdx, _ := fs.FindFreeBlock(file, sb)
-			fs.MarkBlockAsUsed(file, sb, bIdx)
-			end := offset + blockSize
-			if end > len(data) {
-				end = len(data)
-			}
-			var fb structs.FileBlock
-			copy(fb.B_content[:], data[offset:end])
-			if err := fs.WriteFileBlock(file, sb, bIdx, fb); err != nil {
-				return fmt.Errorf("error escribiendo bloque: %v", err)
-			}
-			inode.I_block[i] = bIdx
-			offset = end
-		}
-		inode.I_size = int32(len(data))
 		inode.I_mtime = time.Now().Unix()
 		inode.I_uid = uid
 		inode.I_gid = gid
+		fs.InheritSetgid(parentInode, &inode)
 		if err := fs.WriteInode(file, sb, existingInodeIndex, inode); err != nil {
 			return fmt.Errorf("error actualizando inodo: %v", err)
 		}
@@ -233,7 +215,10 @@ func writeFileToParent(file *os.File, sb structs.Superblock, parentInodeIndex in
 	newInode.I_uid = uid
 	newInode.I_gid = gid
 	newInode.I_type = 1
-	newInode.I_perm = 664
+	fs.SetPerm(&newInode, fs.NewPerm(false, fs.SessionUmask()))
+	newInode.I_links = 1
+	newInode.I_acl = -1
+	fs.InheritSetgid(parentInode, &newInode)
 	newInode.I_atime = time.Now().Unix()
 	newInode.I_ctime = time.Now().Unix()
 	newInode.I_mtime = time.Now().Unix()
@@ -242,27 +227,9 @@ func writeFileToParent(file *os.File, sb structs.Superblock, parentInodeIndex in
 	}
 
 	// Escribir contenido en bloques
-	blockSize := int(sb.S_block_size)
-	offset := 0
-	for i := 0; offset < len(data) && i < len(newInode.I_block); i++ {
-		blockIndex, _ := fs.FindFreeBlock(file, sb)
-		fs.MarkBlockAsUsed(file, sb, blockIndex)
-
-		end := offset + blockSize
-		if end > len(data) {
-			end = len(data)
-		}
-
-		var fb structs.FileBlock
-		copy(fb.B_content[:], data[offset:end])
-		offset = end
-
-		newInode.I_block[i] = blockIndex
-		if err := fs.WriteFileBlock(file, sb, blockIndex, fb); err != nil {
-			return fmt.Errorf("error escribiendo bloque: %v", err)
-		}
+	if err := fs.WriteFileContent(file, sb, &newInode, data); err != nil {
+		return fmt.Errorf("error escribiendo contenido: %v", err)
 	}
-	newInode.I_size = int32(len(data))
 
 	if err := fs.WriteInode(file, sb, newInodeIndex, newInode); err != nil {
 		return fmt.Errorf("error escribiendo inodo: %v", err)
@@ -296,10 +263,7 @@ func writeFileToParent(file *os.File, sb structs.Superblock, parentInodeIndex in
 		newParentBlockIndex, _ := fs.FindFreeBlock(file, sb)
 		fs.MarkBlockAsUsed(file, sb, newParentBlockIndex)
 
-		var parentFB structs.FolderBlock
-		for i := range parentFB.B_content {
-			parentFB.B_content[i].B_inodo = -1
-		}
+		parentFB := fs.NewFolderBlock(sb)
 		copy(parentFB.B_content[0].B_name[:], []byte(fileName))
 		parentFB.B_content[0].B_inodo = newInodeIndex
 		if err := fs.WriteFolderBlock(file, sb, newParentBlockIndex, parentFB); err != nil {
@@ -327,6 +291,13 @@ func writeFileToParent(file *os.File, sb structs.Superblock, parentInodeIndex in
 
 // copyFolderRecursive copia el contenido de una carpeta fuente dentro de la carpeta destino (ya creada).
 func copyFolderRecursive(file *os.File, sb structs.Superblock, srcInode structs.Inode, destInode structs.Inode, destPath string, uid, gid int32) {
+	// Índice del inodo destino, necesario para agregarle entradas
+	_, destIndex, err := fs.FindInodeByPath(file, sb, destPath)
+	if err != nil {
+		fmt.Println("Error al obtener carpeta destino:", err)
+		return
+	}
+
 	// Leer cada entrada del directorio fuente y copiar según tipo
 	for _, blockNum := range srcInode.I_block {
 		if blockNum == -1 {
@@ -339,16 +310,24 @@ func copyFolderRecursive(file *os.File, sb structs.Superblock, srcInode structs.
 				continue
 			}
 			entryInode, _ := fs.ReadInode(file, sb, entry.B_inodo)
-			if entryInode.I_type == 1 {
+			if entryInode.I_type == 2 {
+				// enlace simbólico: se copia el enlace, no el destino
+				target, err := fs.ReadSymlinkTarget(file, sb, entryInode)
+				if err != nil {
+					fmt.Println("Error leyendo enlace fuente:", err)
+					continue
+				}
+				if _, err := createSymlink(file, sb, destIndex, entryName, target, uid, gid); err != nil {
+					fmt.Println("Error copiando enlace:", err)
+				}
+			} else if entryInode.I_type == 1 {
 				// archivo
 				data, err := fs.ReadFileContent(file, sb, entryInode)
 				if err != nil {
 					fmt.Println("Error leyendo archivo fuente:", err)
 					continue
 				}
-				if err := writeFileToParent(file, sb, destInode.I_block[0], entryName, data, uid, gid); err != nil {
-					// Nota: intenta encontrar el índice del inodo destino carpeta si I_block[0] no es el bloque que contiene la entrada
-					// Para simplicidad se asume que destInode está sincronizado y writeFileToParent busca en todos sus bloques.
+				if err := writeFileToParent(file, sb, destIndex, entryName, data, uid, gid); err != nil {
 					fmt.Println("Error escribiendo archivo destino:", err)
 				}
 			} else {
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"proyecto1/report"
+)
+
+// ExecuteDf resume los inodos y bloques libres y usados de una partición montada según
+// su superbloque. Si los contadores no coinciden con los bitmaps lo advierte (fsck
+// -repair los corrige).
+// Uso: df -id=<MountID>
+func ExecuteDf(id string) {
+	file, mp, sb, err := readReportSuperblock(id)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer file.Close()
+	if sb.S_magic != 0xEF53 {
+		fmt.Printf("Error: la partición %s no está formateada.\n", id)
+		return
+	}
+
+	inodesUsed := int64(sb.S_inodes_count - sb.S_free_inodes_count)
+	blocksUsed := int64(sb.S_blocks_count - sb.S_free_blocks_count)
+	blockSize := int64(sb.S_block_size)
+	usage := &report.Table{
+		Columns: []string{"", "Total", "Usados", "Libres", "% Uso"},
+		Rows: [][]string{
+			dfRow("Inodos", int64(sb.S_inodes_count), inodesUsed),
+			dfRow("Bloques", int64(sb.S_blocks_count), blocksUsed),
+			dfRow("Bytes", int64(sb.S_blocks_count)*blockSize, blocksUsed*blockSize),
+		},
+	}
+	rep := &report.Report{
+		Title: fmt.Sprintf("DF - Partición %s (%s)", mp.Name, mp.ID),
+		Sections: []report.Section{
+			{Fields: []report.Field{
+				{Key: "Sistema de archivos", Value: fmt.Sprintf("EXT%d", sb.S_filesystem_type)},
+				{Key: "Tamaño de bloque", Value: fmt.Sprintf("%d bytes", sb.S_block_size)},
+				{Key: "Tamaño de la partición", Value: fmt.Sprintf("%d bytes", mp.Size)},
+			}},
+			{Table: usage},
+		},
+	}
+	if err := report.Render(os.Stdout, "txt", rep); err != nil {
+		fmt.Println("Error al mostrar el resumen:", err)
+		return
+	}
+
+	// Los contadores del superbloque se pueden desfasar de los bitmaps
+	bmInodes, err := readBitmap(file, sb.S_bm_inode_start, sb.S_inodes_count)
+	if err != nil {
+		return
+	}
+	bmBlocks, err := readBitmap(file, sb.S_bm_block_start, sb.S_blocks_count)
+	if err != nil {
+		return
+	}
+	if n, m := countUsed(bmInodes), countUsed(bmBlocks); n != inodesUsed || m != blocksUsed {
+		fmt.Printf("Advertencia: los bitmaps marcan %d inodos y %d bloques usados; ejecuta 'fsck -id=%s -repair' para corregir el superbloque.\n", n, m, id)
+	}
+}
+
+func dfRow(label string, total, used int64) []string {
+	percent := 0.0
+	if total > 0 {
+		percent = float64(used) * 100 / float64(total)
+	}
+	return []string{
+		label,
+		fmt.Sprintf("%d", total),
+		fmt.Sprintf("%d", used),
+		fmt.Sprintf("%d", total-used),
+		fmt.Sprintf("%.1f%%", percent),
+	}
+}
+
+func countUsed(bitmap []byte) int64 {
+	var n int64
+	for _, b := range bitmap {
+		if b == 1 {
+			n++
+		}
+	}
+	return n
+}
@@ -4,158 +4,187 @@ import (
 	"encoding/binary"
 	"fmt"
 	"os"
-	"time"
+	"proyecto1/report"
 	"proyecto1/state"
 	"proyecto1/structs"
-	"path/filepath"
-
-	"github.com/fogleman/gg"
+	"proyecto1/utils"
+	"sort"
+	"strings"
 )
 
-func DISK(id string, imagePath string) {
-	mp, found := state.GetMountedPartitionByID(id)
-	if !found {
-		fmt.Println("No se encontró el disco con ID:", id)
-		return
-	}
+// Colores de cada tipo de tramo del reporte disk.
+var diskColors = map[string]string{
+	"MBR":             "#ffcc66",
+	"EBR":             "#ffcc66",
+	"Primaria":        "#6699ff",
+	"Extendida":       "#99ff99",
+	"Lógica":          "#ff9999",
+	"Lógica inactiva": "#f2d9d9",
+	"Libre":           "#e6e6e6",
+}
+
+// diskSegment es un tramo del disco: el MBR, una partición, un EBR o un hueco libre.
+type diskSegment struct {
+	kind     string // clave de diskColors
+	name     string
+	start    int64
+	size     int64
+	fit      byte
+	children []diskSegment // EBR, lógicas y huecos de la extendida
+}
 
-	file, err := os.Open(mp.Path)
+// DISK arma el reporte de ocupación del disco a escala: el MBR, las primarias, la
+// extendida con cada EBR y lógica adentro, y los huecos libres tal como los calculan
+// utils.GetFreeSpaces y utils.GetFreeSpacesInExtended (los que usa fdisk para el ajuste).
+func DISK(id string, _ ReportOptions) (*report.Report, error) {
+	file, mp, err := openReportPartition(id)
 	if err != nil {
-		fmt.Println("Error al abrir el disco:", err)
-		return
+		return nil, err
 	}
 	defer file.Close()
 
-	var mbr structs.MBR
-	err = binary.Read(file, binary.LittleEndian, &mbr)
+	mbr, err := utils.ReadMBR(file)
 	if err != nil {
-		fmt.Println("Error al leer MBR:", err)
-		return
+		return nil, fmt.Errorf("error al leer MBR: %w", err)
 	}
 
-	const W = 800
-	const H = 1000
-	dc := gg.NewContext(W, H)
-	dc.SetRGB(1, 1, 1)
-	dc.Clear()
-
-	// Fuente
-	if err := dc.LoadFontFace("/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf", 16); err != nil {
-		fmt.Println("Error al cargar la fuente:", err)
-		return
-	}
-
-	y := 20
-
-	// Encabezado Disk Report
-	dc.SetRGB(0, 0.4, 0.4) // teal oscuro
-	dc.DrawRectangle(0, float64(y), W, 30)
-	dc.Fill()
-	dc.SetRGB(1, 1, 1)
-	dc.DrawStringAnchored("REPORTE DEL DISCO", W/2, float64(y)+15, 0.5, 0.5)
-	y += 40
-
-	// Datos generales del disco (MBR)
-	mbrFields := map[string]string{
-		"Tamaño del disco": fmt.Sprintf("%d bytes", mbr.Mbr_tamano),
-		"Fecha de creación": time.Unix(mbr.Mbr_fecha_creacion, 0).Format("2006-01-02 15:04"),
-		"Disk Signature":   fmt.Sprintf("%d", mbr.Mbr_dsk_signature),
-	}
-	for k, v := range mbrFields {
-		dc.SetRGB(0.9, 0.9, 0.9)
-		dc.DrawRectangle(0, float64(y), W, 25)
-		dc.Fill()
-		dc.SetRGB(0, 0, 0)
-		dc.DrawStringAnchored(k, 20, float64(y)+12, 0, 0.5)
-		dc.DrawStringAnchored(v, 250, float64(y)+12, 0, 0.5)
-		y += 25
+	// IDs de las particiones montadas de este disco; el EBR no guarda el ID
+	mountIDs := make(map[string]string)
+	for _, m := range state.GetMountedPartitions() {
+		if m.Path == mp.Path {
+			mountIDs[m.Name] = m.ID
+		}
 	}
 
-	// Calcular total ocupado y dibujar barras de particiones
-	totalSize := float64(mbr.Mbr_tamano)
-	xPos := 0.0
-	barHeight := 50.0
-	barY := float64(y) + 20
-
+	segments := []diskSegment{{kind: "MBR", name: "MBR", start: 0, size: int64(binary.Size(mbr))}}
+	var inactive []structs.Partition
 	for _, part := range mbr.Mbr_partitions {
-		if part.Part_status != '0' {
-			sizePercent := float64(part.Part_s) / totalSize
-			width := W * sizePercent
-
-			// Color según tipo
-			switch part.Part_type {
-			case 'P':
-				dc.SetRGB(0.4, 0.6, 1) // azul
-			case 'E':
-				dc.SetRGB(0.6, 1, 0.6) // verde
-			default:
-				dc.SetRGB(0.8, 0.8, 0.8) // gris
+		if part.Part_status != '1' {
+			// igual que GetFreeSpaces: borradas y desmontadas cuentan como libres
+			if part.Part_s > 0 {
+				inactive = append(inactive, part)
 			}
-			dc.DrawRectangle(xPos, barY, width, barHeight)
-			dc.Fill()
+			continue
+		}
+		seg := diskSegment{kind: "Primaria", name: reportName(part.Part_name[:]), start: part.Part_start, size: part.Part_s, fit: part.Part_fit}
+		if part.Part_type == 'E' {
+			seg.kind = "Extendida"
+			seg.children = extendedSegments(file, part)
+		}
+		segments = append(segments, seg)
+	}
+	freeSpaces := utils.GetFreeSpaces(&mbr)
+	for _, free := range freeSpaces {
+		name := "Libre"
+		for _, part := range inactive {
+			if part.Part_start >= free.Start && part.Part_start <= free.End {
+				name += fmt.Sprintf(" (%s inactiva)", reportName(part.Part_name[:]))
+			}
+		}
+		segments = append(segments, diskSegment{kind: "Libre", name: name, start: free.Start, size: free.Size})
+	}
+	sortSegments(segments)
 
-			// Texto de la partición
-			dc.SetRGB(0, 0, 0)
-			dc.DrawStringAnchored(string(part.Part_name[:]), xPos+width/2, barY+barHeight/2, 0.5, 0.5)
-			dc.DrawStringAnchored(fmt.Sprintf("%.2f%%", sizePercent*100), xPos+width/2, barY+barHeight/2+15, 0.5, 0.5)
+	var freeBytes, largest int64
+	for _, free := range freeSpaces {
+		freeBytes += free.Size
+		largest = max(largest, free.Size)
+	}
 
-			// Avanzar posición
-			xPos += width
+	totalSize := float64(mbr.Mbr_tamano)
+	var bar []report.Segment
+	table := &report.Table{Columns: []string{"Tramo", "Tipo", "Inicio", "Fin", "Tamaño", "%", "Ajuste", "ID"}}
+	add := func(seg diskSegment, nested bool) {
+		percent := float64(seg.size) / totalSize * 100
+		byteRange := fmt.Sprintf("%d - %d", seg.start, seg.start+seg.size-1)
+		detail := []string{byteRange}
+		mountID := ""
+		if seg.kind == "Primaria" || seg.kind == "Lógica" {
+			mountID = mountIDs[seg.name]
+		}
+		if mountID != "" {
+			detail = append(detail, "ID "+mountID)
+		}
+		bar = append(bar, report.Segment{Label: seg.name, Percent: percent, Color: diskColors[seg.kind], Nested: nested, Detail: detail})
+
+		table.Rows = append(table.Rows, []string{
+			seg.name,
+			seg.kind,
+			fmt.Sprintf("%d", seg.start),
+			fmt.Sprintf("%d", seg.start+seg.size-1),
+			fmt.Sprintf("%d", seg.size),
+			fmt.Sprintf("%.2f%%", percent),
+			fitLabel(seg.fit),
+			orDash(mountID),
+		})
+	}
+	for _, seg := range segments {
+		add(seg, false)
+		for _, child := range seg.children {
+			add(child, true)
+		}
+	}
 
-			// Si es extendida, mostrar EBRs como barras internas
-			if part.Part_type == 'E' {
-				ebrPos := part.Part_start
-				for ebrPos != -1 {
-					var ebr structs.EBR
-					_, err := file.Seek(ebrPos, 0)
-					if err != nil {
-						break
-					}
-					err = binary.Read(file, binary.LittleEndian, &ebr)
-					if err != nil {
-						break
-					}
-					if ebr.Part_status != '0' {
-						ebrPercent := float64(ebr.Part_s) / totalSize
-						width := W * ebrPercent
-						dc.SetRGB(1, 0.6, 0.6) // rosa
-						dc.DrawRectangle(xPos, barY, width, barHeight/2)
-						dc.Fill()
-						dc.SetRGB(0, 0, 0)
-						dc.DrawStringAnchored(string(ebr.Part_name[:]), xPos+width/2, barY+barHeight/4, 0.5, 0.5)
-						dc.DrawStringAnchored(fmt.Sprintf("%.2f%%", ebrPercent*100), xPos+width/2, barY+barHeight/4+12, 0.5, 0.5)
+	rep := &report.Report{Title: "REPORTE DEL DISCO"}
+	rep.Sections = append(rep.Sections,
+		report.Section{
+			Fields: []report.Field{
+				{Key: "Tamaño del disco", Value: fmt.Sprintf("%d bytes", mbr.Mbr_tamano)},
+				{Key: "Fecha de creación", Value: reportTime(mbr.Mbr_fecha_creacion)},
+				{Key: "Disk Signature", Value: fmt.Sprintf("%d", mbr.Mbr_dsk_signature)},
+				{Key: "Ajuste del disco", Value: fitLabel(mbr.Dsk_fit)},
+				{Key: "Libre fuera de la extendida", Value: fmt.Sprintf("%d bytes en %d huecos (mayor: %d)", freeBytes, len(freeSpaces), largest)},
+			},
+		},
+		report.Section{Title: "Distribución", Bar: bar},
+		report.Section{Title: "Tramos", Table: table},
+	)
+	return rep, nil
+}
 
-						xPos += width
-					}
-					ebrPos = ebr.Part_next
-				}
+// extendedSegments recorre la cadena de EBR de la extendida y devuelve, en orden, cada EBR
+// con su lógica y los huecos que quedan entre ellas. Las lógicas con estado 0 (desmontadas
+// o borradas con fast) que siguen en la cadena ocupan su espacio: fdisk no lo reutiliza.
+func extendedSegments(file *os.File, ext structs.Partition) []diskSegment {
+	ebrSize := int64(binary.Size(structs.EBR{}))
+	var segments []diskSegment
+	var logicals []structs.EBR
+	seen := make(map[int64]bool) // una cadena dañada podría cerrar un ciclo
+	for pos := ext.Part_start; pos != -1 && !seen[pos]; {
+		seen[pos] = true
+		ebr, err := utils.ReadEBR(file, pos)
+		if err != nil {
+			break
+		}
+		if ebr.Part_s > 0 {
+			logicals = append(logicals, ebr)
+			logical := diskSegment{kind: "Lógica", name: reportName(ebr.Part_name[:]), start: ebr.Part_start, size: ebr.Part_s, fit: ebr.Part_fit}
+			if ebr.Part_status != '1' {
+				logical.kind = "Lógica inactiva"
+				logical.name += " (inactiva)"
 			}
+			segments = append(segments,
+				diskSegment{kind: "EBR", name: "EBR", start: ebr.Part_start - ebrSize, size: ebrSize},
+				logical,
+			)
 		}
+		pos = ebr.Part_next
 	}
-
-	// Espacios libres si los hay
-	if xPos < float64(W) {
-		freePercent := (float64(W) - xPos) / float64(W)
-		dc.SetRGB(0.9, 0.9, 0.9)
-		dc.DrawRectangle(xPos, barY, float64(W)-xPos, barHeight)
-		dc.Fill()
-		dc.SetRGB(0, 0, 0)
-		dc.DrawStringAnchored("Libre", xPos+(float64(W)-xPos)/2, barY+barHeight/2, 0.5, 0.5)
-		dc.DrawStringAnchored(fmt.Sprintf("%.2f%%", freePercent*100), xPos+(float64(W)-xPos)/2, barY+barHeight/2+15, 0.5, 0.5)
+	for _, free := range utils.GetFreeSpacesInExtended(ext, logicals) {
+		segments = append(segments, diskSegment{kind: "Libre", name: "Libre", start: free.Start, size: free.Size})
 	}
+	sortSegments(segments)
+	return segments
+}
 
-	// Asegurarse de que la carpeta existe antes de guardar
-	dir := filepath.Dir(imagePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		fmt.Println("Error al crear las carpetas necesarias:", err)
-		return
-	}
+func sortSegments(segments []diskSegment) {
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].start < segments[j].start })
+}
 
-	// Guardar imagen
-	if err := dc.SavePNG(imagePath); err != nil {
-		fmt.Println("Error al guardar la imagen:", err)
-		return
+// fitLabel muestra el ajuste guardado (B, F o W); "-" si no hay.
+func fitLabel(fit byte) string {
+	if fit == 0 {
+		return "-"
 	}
-
-	fmt.Println("Reporte de disco generado en:", imagePath)
+	return strings.ToUpper(string(fit))
 }
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"proyecto1/state"
+)
+
+func TestDiskSegments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disco.mia")
+	t.Cleanup(func() {
+		state.CurrentSession = state.Session{}
+		state.GlobalMountedPartitions = nil
+	})
+	captureOutput(t, func() {
+		ExecuteMkdisk(1, "m", "ff", path)
+		ExecuteFdisk(path, "P1", "k", "p", "bf", 300, "", 0)
+		ExecuteFdisk(path, "E1", "k", "e", "ff", 400, "", 0)
+		ExecuteFdisk(path, "L1", "k", "l", "wf", 100, "", 0)
+		ExecuteFdisk(path, "L2", "k", "l", "ff", 50, "", 0)
+		ExecuteMount(path, "P1")
+		ExecuteMount(path, "L2")
+	})
+	if len(state.GlobalMountedPartitions) != 2 {
+		t.Fatal("no se pudieron montar P1 y L2")
+	}
+	ids := map[string]string{}
+	for _, m := range state.GlobalMountedPartitions {
+		ids[m.Name] = m.ID
+	}
+
+	rep, err := DISK(ids["P1"], ReportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bar, table := rep.Sections[1].Bar, rep.Sections[2].Table
+	if len(bar) != len(table.Rows) {
+		t.Fatalf("%d segmentos en la barra y %d filas en la tabla", len(bar), len(table.Rows))
+	}
+
+	var kinds []string
+	var topNext, extNext, extEnd int64
+	var percent float64
+	for i, row := range table.Rows {
+		start, _ := strconv.ParseInt(row[2], 10, 64)
+		end, _ := strconv.ParseInt(row[3], 10, 64)
+		size, _ := strconv.ParseInt(row[4], 10, 64)
+		if end-start+1 != size {
+			t.Errorf("%s: %d - %d no mide %d", row[0], start, end, size)
+		}
+		if want := row[0] + " " + row[2] + " - " + row[3]; bar[i].Label+" "+bar[i].Detail[0] != want {
+			t.Errorf("segmento %d = %s %v, se esperaba %s", i, bar[i].Label, bar[i].Detail, want)
+		}
+		kinds = append(kinds, row[1])
+
+		// Los tramos de primer nivel cubren el disco y los anidados la extendida, sin huecos
+		// ni solapes
+		if bar[i].Nested {
+			if start != extNext {
+				t.Errorf("%s empieza en %d, se esperaba %d", row[0], start, extNext)
+			}
+			extNext = end + 1
+			continue
+		}
+		if extNext != extEnd {
+			t.Errorf("la extendida termina en %d y sus tramos en %d", extEnd-1, extNext-1)
+		}
+		if start != topNext {
+			t.Errorf("%s empieza en %d, se esperaba %d", row[0], start, topNext)
+		}
+		topNext = end + 1
+		if row[1] == "Extendida" {
+			extNext, extEnd = start, end+1
+		}
+		percent += bar[i].Percent
+	}
+	if extNext != extEnd {
+		t.Errorf("la extendida termina en %d y sus tramos en %d", extEnd-1, extNext-1)
+	}
+	if topNext != 1<<20 {
+		t.Errorf("los tramos terminan en %d, el disco mide %d", topNext, 1<<20)
+	}
+	if percent < 99.99 || percent > 100.01 {
+		t.Errorf("los tramos de primer nivel suman %.2f%%", percent)
+	}
+
+	want := "MBR Primaria Extendida EBR Lógica EBR Lógica Libre Libre"
+	if got := strings.Join(kinds, " "); got != want {
+		t.Errorf("tramos = %s, se esperaba %s", got, want)
+	}
+	for _, row := range table.Rows {
+		wantID := map[string]string{"P1": ids["P1"], "L2": ids["L2"]}[row[0]]
+		if row[7] != orDash(wantID) {
+			t.Errorf("%s: ID %s, se esperaba %s", row[0], row[7], orDash(wantID))
+		}
+		wantFit := map[string]string{"P1": "B", "E1": "F", "L1": "W", "L2": "F"}[row[0]]
+		if row[6] != orDash(wantFit) {
+			t.Errorf("%s: ajuste %s, se esperaba %s", row[0], row[6], orDash(wantFit))
+		}
+	}
+}
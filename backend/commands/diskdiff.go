@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+
+	"proyecto1/dump"
+)
+
+// ExecuteDiskDiff compara dos imágenes de disco: campos del MBR, particiones, superbloque,
+// bits de los bitmaps, entradas de carpeta por ruta, inodos y bloques agregados,
+// liberados o modificados. Con 'name' solo se compara el contenido de esa partición.
+// Uso: diskdiff -a=<antes.mia> -b=<despues.mia> [-name=<partición>]
+func ExecuteDiskDiff(pathA, pathB, name string) {
+	a, err := dump.Read(pathA)
+	if err != nil {
+		fmt.Printf("Error con '%s': %v\n", pathA, err)
+		return
+	}
+	b, err := dump.Read(pathB)
+	if err != nil {
+		fmt.Printf("Error con '%s': %v\n", pathB, err)
+		return
+	}
+
+	sections, err := dump.Diff(a, b, name)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("diskdiff %s → %s\n", pathA, pathB)
+	if len(sections) == 0 {
+		fmt.Println("Sin diferencias.")
+		return
+	}
+	changes := 0
+	for _, s := range sections {
+		fmt.Printf("%s:\n", s.Title)
+		for _, line := range s.Lines {
+			fmt.Println("  " + line)
+		}
+		changes += len(s.Lines)
+	}
+	fmt.Printf("diskdiff: %d diferencia(s).\n", changes)
+}
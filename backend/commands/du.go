@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"proyecto1/fs"
+	"proyecto1/report"
+	"proyecto1/state"
+	"proyecto1/structs"
+	"proyecto1/users"
+	"sort"
+	"strings"
+)
+
+// duTotal acumula el uso de un subárbol o de un usuario.
+type duTotal struct {
+	inodes int64
+	blocks int64 // bloques de datos, de apuntadores y de ACL
+	bytes  int64 // suma de I_size
+}
+
+func (t *duTotal) add(o duTotal) {
+	t.inodes += o.inodes
+	t.blocks += o.blocks
+	t.bytes += o.bytes
+}
+
+// duRow es una carpeta del listado con el total de su subárbol.
+type duRow struct {
+	path  string
+	total duTotal
+}
+
+// duState recorre un subárbol sumando bloques y bytes.
+type duState struct {
+	file     *os.File
+	sb       structs.Superblock
+	cred     fs.Cred
+	maxDepth int // <0 sin límite
+
+	seen   map[int32]bool // los enlaces duros se cuentan una sola vez
+	byUID  map[int32]*duTotal
+	rows   []*duRow
+	denied []string // carpetas sin permiso de lectura: solo cuentan sus propios bloques
+}
+
+// ExecuteDu muestra el uso de espacio de una ruta de la partición de la sesión.
+// Uso: du [-path=/home] [-depth=N]
+func ExecuteDu(p string, depth int) {
+	if !state.CurrentSession.IsActive {
+		fmt.Println("Error: debes iniciar sesión para usar du.")
+		return
+	}
+	rep, err := DU(state.CurrentSession.PartitionID, ReportOptions{PathFileLS: p, Depth: depth})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if err := report.Render(os.Stdout, "txt", rep); err != nil {
+		fmt.Println("Error al mostrar el uso de espacio:", err)
+	}
+}
+
+// DU arma el reporte de uso de espacio desde opts.PathFileLS ("/" si está vacío): el
+// total de cada carpeta hasta opts.Depth niveles y el total por propietario.
+func DU(id string, opts ReportOptions) (*report.Report, error) {
+	if err := requireReportSession(); err != nil {
+		return nil, err
+	}
+	file, mp, sb, err := readReportSuperblock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	start := opts.PathFileLS
+	if start == "" {
+		start = "/"
+	}
+	start = path.Clean("/" + start)
+	inode, index, err := fs.FindInodeByPath(file, sb, start)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &duState{
+		file:     file,
+		sb:       sb,
+		cred:     fs.SessionCred(),
+		maxDepth: opts.Depth,
+		seen:     make(map[int32]bool),
+		byUID:    make(map[int32]*duTotal),
+	}
+	total := st.walk(index, inode, start, 0)
+	if len(st.rows) == 0 {
+		// la ruta es un archivo
+		st.rows = append(st.rows, &duRow{path: start, total: total})
+	}
+
+	blockSize := int64(sb.S_block_size)
+	tree := &report.Table{Columns: []string{"Ruta", "Inodos", "Bloques", "Bytes", "En disco"}}
+	for _, r := range st.rows {
+		tree.Rows = append(tree.Rows, duCells(r.path, r.total, blockSize))
+	}
+
+	names, _ := users.Load(file, sb) // sin users.txt solo se muestran los UID
+	uids := make([]int32, 0, len(st.byUID))
+	for uid := range st.byUID {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool {
+		a, b := st.byUID[uids[i]], st.byUID[uids[j]]
+		if a.blocks != b.blocks {
+			return a.blocks > b.blocks
+		}
+		return uids[i] < uids[j]
+	})
+	owners := &report.Table{Columns: []string{"UID", "Usuario", "Inodos", "Bloques", "Bytes", "En disco"}}
+	for _, uid := range uids {
+		name := "-"
+		if names != nil {
+			if u := names.UserByUID(uid); u != nil {
+				name = u.Name
+			}
+		}
+		owners.Rows = append(owners.Rows, append([]string{fmt.Sprintf("%d", uid)}, duCells(name, *st.byUID[uid], blockSize)...))
+	}
+
+	sections := []report.Section{
+		{Title: "Por carpeta", Table: tree},
+		{Title: "Por propietario", Table: owners},
+	}
+	if len(st.denied) > 0 {
+		sections = append(sections, report.Section{
+			Title: "Sin permiso de lectura (solo se cuentan sus propios bloques)",
+			Text:  strings.Join(st.denied, "\n"),
+		})
+	}
+	return &report.Report{
+		Title:    fmt.Sprintf("Reporte DU - %s en %s (%s)", start, mp.Name, mp.ID),
+		Sections: sections,
+	}, nil
+}
+
+// walk suma el inodo index y, si es carpeta, todo lo que cuelga de ella. Las carpetas
+// hasta maxDepth se agregan a rows antes que sus hijas.
+func (st *duState) walk(index int32, inode structs.Inode, p string, depth int) duTotal {
+	if st.seen[index] {
+		return duTotal{}
+	}
+	st.seen[index] = true
+
+	data, pointers, _ := fs.InodeBlocks(st.file, st.sb, inode) // si un apuntador está dañado, sus bloques no se cuentan
+	own := duTotal{inodes: 1, blocks: int64(len(data) + len(pointers)), bytes: int64(inode.I_size)}
+	if fs.HasACL(inode) {
+		own.blocks++
+	}
+	if st.byUID[inode.I_uid] == nil {
+		st.byUID[inode.I_uid] = &duTotal{}
+	}
+	st.byUID[inode.I_uid].add(own)
+
+	total := own
+	if inode.I_type != 0 {
+		return total
+	}
+
+	var row *duRow
+	if st.maxDepth < 0 || depth <= st.maxDepth {
+		row = &duRow{path: p}
+		st.rows = append(st.rows, row)
+	}
+	if !fs.CanRead(st.file, st.sb, inode, st.cred) || !fs.CanExec(st.file, st.sb, inode, st.cred) {
+		st.denied = append(st.denied, p)
+	} else {
+		for _, b := range data {
+			fb, err := fs.ReadFolderBlock(st.file, st.sb, b)
+			if err != nil {
+				continue
+			}
+			for _, entry := range fb.B_content {
+				name := reportName(entry.B_name[:])
+				if name == "" || name == "." || name == ".." || entry.B_inodo == -1 {
+					continue
+				}
+				child, err := fs.ReadInode(st.file, st.sb, entry.B_inodo)
+				if err != nil {
+					continue
+				}
+				total.add(st.walk(entry.B_inodo, child, path.Join(p, name), depth+1))
+			}
+		}
+	}
+	if row != nil {
+		row.total = total
+	}
+	return total
+}
+
+func duCells(label string, t duTotal, blockSize int64) []string {
+	return []string{
+		label,
+		fmt.Sprintf("%d", t.inodes),
+		fmt.Sprintf("%d", t.blocks),
+		fmt.Sprintf("%d", t.bytes),
+		fmt.Sprintf("%d", t.blocks*blockSize),
+	}
+}
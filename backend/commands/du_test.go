@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"proyecto1/report"
+	"proyecto1/structs"
+)
+
+// duRows indexa por ruta las filas de la tabla por carpeta del reporte du.
+func duRows(table *report.Table) map[string][]string {
+	rows := make(map[string][]string)
+	for _, r := range table.Rows {
+		rows[r[0]] = r
+	}
+	return rows
+}
+
+func TestDu(t *testing.T) {
+	id := newTestDisk(t, "2fs")
+	captureOutput(t, func() {
+		// 13 bloques de datos: el último va por el apuntador indirecto simple
+		ExecuteMkfile("/a/grande.txt", false, 13*structs.FILE_BLOCK_SIZE, "")
+		ExecuteLn("/a/b/f.txt", "/a/duro.txt", false)
+	})
+
+	rep, err := DU(id, ReportOptions{PathFileLS: "/", Depth: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := duRows(rep.Sections[0].Table)
+	want := map[string][2]string{
+		// carpeta b (1 bloque) y f.txt (3 bloques)
+		"/a/b": {"2", "4"},
+		// a ocupa 2 bloques con duro.txt; grande.txt 13 + 1 de apuntadores; f.txt se
+		// cuenta una sola vez aunque tenga dos enlaces
+		"/a": {"4", "20"},
+	}
+	for p, w := range want {
+		r, ok := tree[p]
+		if !ok {
+			t.Fatalf("falta la fila %s en %v", p, rep.Sections[0].Table.Rows)
+		}
+		if r[1] != w[0] || r[2] != w[1] {
+			t.Errorf("%s: inodos/bloques = %s/%s, se esperaba %s/%s", p, r[1], r[2], w[0], w[1])
+		}
+	}
+	// El I_size de una carpeta no crece con sus bloques; solo se comprueban los bytes de b
+	if got := tree["/a/b"][3]; got != fmt.Sprint(structs.FILE_BLOCK_SIZE+600) {
+		t.Errorf("/a/b: bytes = %s, se esperaba %d", got, structs.FILE_BLOCK_SIZE+600)
+	}
+
+	// Desde la raíz se cuenta todo lo que está en uso
+	_, sb := openTestDisk(t, id)
+	root := tree["/"]
+	if used := fmt.Sprint(sb.S_inodes_count - sb.S_free_inodes_count); root[1] != used {
+		t.Errorf("inodos en / = %s, el superbloque tiene %s en uso", root[1], used)
+	}
+	if used := fmt.Sprint(sb.S_blocks_count - sb.S_free_blocks_count); root[2] != used {
+		t.Errorf("bloques en / = %s, el superbloque tiene %s en uso", root[2], used)
+	}
+	owners := rep.Sections[1].Table.Rows
+	if len(owners) != 1 || owners[0][1] != "root" || owners[0][3] != root[2] {
+		t.Errorf("por propietario = %v, se esperaba solo root con %s bloques", owners, root[2])
+	}
+
+	// La profundidad limita las filas, no los totales
+	rep, err = DU(id, ReportOptions{PathFileLS: "/a", Depth: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := rep.Sections[0].Table.Rows; len(rows) != 1 || rows[0][2] != tree["/a"][2] {
+		t.Errorf("du -path=/a -depth=0 = %v, se esperaba solo /a con %s bloques", rows, tree["/a"][2])
+	}
+
+	rep, err = DU(id, ReportOptions{PathFileLS: "/a/grande.txt", Depth: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := rep.Sections[0].Table.Rows; len(rows) != 1 || rows[0][2] != "14" {
+		t.Errorf("du de un archivo = %v, se esperaba una fila con 14 bloques", rows)
+	}
+
+	if _, err := DU(id, ReportOptions{PathFileLS: "/no/existe"}); err == nil {
+		t.Error("du de una ruta inexistente no dio error")
+	}
+}
+
+func TestDf(t *testing.T) {
+	id := newTestDisk(t, "2fs")
+	_, sb := openTestDisk(t, id)
+	out := captureOutput(t, func() { ExecuteDf(id) })
+
+	used := sb.S_blocks_count - sb.S_free_blocks_count
+	row := regexp.MustCompile(fmt.Sprintf(`(?m)^Bloques\s+%d\s+%d\s+%d\s`, sb.S_blocks_count, used, sb.S_free_blocks_count))
+	if !row.MatchString(out) {
+		t.Errorf("df no muestra %d bloques usados de %d:\n%s", used, sb.S_blocks_count, out)
+	}
+	if strings.Contains(out, "Advertencia") {
+		t.Errorf("df advierte de contadores desfasados en un disco recién creado:\n%s", out)
+	}
+
+	// Un bloque marcado en el bitmap sin pasar por el superbloque desfasa los contadores
+	file, _ := openTestDisk(t, id)
+	if _, err := file.WriteAt([]byte{1}, int64(sb.S_bm_block_start+sb.S_blocks_count-1)); err != nil {
+		t.Fatal(err)
+	}
+	if out := captureOutput(t, func() { ExecuteDf(id) }); !strings.Contains(out, "Advertencia") {
+		t.Errorf("df no advierte que el bitmap y el superbloque no coinciden:\n%s", out)
+	}
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"proyecto1/dump"
+)
+
+// ExecuteDump vuelca la estructura completa del disco en 'path' (MBR, EBR, superbloques,
+// bitmaps, inodos y bloques en uso). Sin -out el volcado sale por la salida estándar.
+func ExecuteDump(path, format, out string) {
+	if path == "" {
+		fmt.Println("Error: el parámetro -path es obligatorio para dump.")
+		return
+	}
+	format = strings.ToLower(format)
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" {
+		fmt.Printf("Error: formato '%s' no soportado para dump (json).\n", format)
+		return
+	}
+
+	d, err := dump.Read(path)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		fmt.Println("Error al serializar el volcado:", err)
+		return
+	}
+
+	if out == "" {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(out, append(data, '\n'), 0644); err != nil {
+		fmt.Println("Error al escribir el volcado:", err)
+		return
+	}
+	fmt.Printf("Volcado de %s generado en: %s\n", path, out)
+}
@@ -56,7 +56,7 @@ func ExecuteEdit(path string, cont string) {
 	}
 
 	// Permisos lectura y escritura
-	if !tienePermisoLectura(inode, uid, gid) || !tienePermisoEscritura(inode, uid, gid) {
+	if !tienePermisoLectura(file, sb, inode, uid, gid) || !tienePermisoEscritura(file, sb, inode, uid, gid) {
 		fmt.Println("Error: no tienes permisos de lectura/escritura sobre este archivo.")
 		return
 	}
@@ -68,47 +68,13 @@ func ExecuteEdit(path string, cont string) {
 		return
 	}
 
-	// Liberar bloques antiguos
-	for i := range inode.I_block {
-		if inode.I_block[i] != -1 {
-			fs.MarkBlockAsFree(file, sb, inode.I_block[i], mountedPartition.Start)
-			inode.I_block[i] = -1
-		}
-	}
-
-	// Escribir nuevo contenido
-	blockSize := len(structs.FileBlock{}.B_content)
-	offset := 0
-	for offset < len(newContent) {
-		blockIndex, _ := fs.FindFreeBlock(file, sb)
-		fs.MarkBlockAsUsed(file, sb, blockIndex)
-
-		var fb structs.FileBlock
-		end := offset + blockSize
-		if end > len(newContent) {
-			end = len(newContent)
-		}
-		copy(fb.B_content[:], newContent[offset:end])
-		fs.WriteFileBlock(file, sb, blockIndex, fb)
-
-		// Asignar al primer bloque libre en inode
-		assigned := false
-		for i := range inode.I_block {
-			if inode.I_block[i] == -1 {
-				inode.I_block[i] = blockIndex
-				assigned = true
-				break
-			}
-		}
-		if !assigned {
-			fmt.Println("Error: no hay suficiente espacio en los punteros del inodo.")
-			break
-		}
-		offset = end
+	// Escribir nuevo contenido (libera los bloques antiguos y usa indirectos si hace falta)
+	if err := fs.WriteFileContent(file, sb, &inode, newContent); err != nil {
+		fmt.Println("Error al escribir el contenido:", err)
+		return
 	}
 
 	// Actualizar inodo
-	inode.I_size = int32(len(newContent))
 	inode.I_mtime = time.Now().Unix()
 	fs.WriteInode(file, sb, inodeIndex, inode)
 
@@ -50,39 +50,13 @@ func ExecuteFind(startPath string, namePattern string) {
 
 	uid, gid, _ := getUserIDs(file, sb, state.CurrentSession.User)
 
-	// --- 3. Buscar el inodo de la ruta base ---
-	startInodeIndex := int32(0)
-	if strings.Trim(startPath, "/") != "" {
-		parts := strings.Split(strings.Trim(startPath, "/"), "/")
-		for _, part := range parts {
-			inode, _ := fs.ReadInode(file, sb, startInodeIndex)
-			found := false
-			for _, blockNum := range inode.I_block {
-				if blockNum == -1 {
-					continue
-				}
-				fb, _ := fs.ReadFolderBlock(file, sb, blockNum)
-				for _, entry := range fb.B_content {
-					name := string(bytes.Trim(entry.B_name[:], "\x00"))
-					if name == part && entry.B_inodo != -1 {
-						startInodeIndex = entry.B_inodo
-						found = true
-						break
-					}
-				}
-				if found {
-					break
-				}
-			}
-			if !found {
-				fmt.Println("Error: la ruta base no existe.")
-				return
-			}
-		}
+	// --- 3. Buscar el inodo de la ruta base (sigue enlaces simbólicos) ---
+	startInode, startInodeIndex, err := fs.FindInodeByPath(file, sb, "/"+strings.TrimLeft(startPath, "/"))
+	if err != nil {
+		fmt.Println("Error: la ruta base no existe.")
+		return
 	}
-
-	startInode, _ := fs.ReadInode(file, sb, startInodeIndex)
-	if !tienePermisoLectura(startInode, uid, gid) {
+	if !tienePermisoLectura(file, sb, startInode, uid, gid) {
 		fmt.Println("Error: no tienes permiso de lectura en la carpeta base.")
 		return
 	}
@@ -106,7 +80,7 @@ func ExecuteFind(startPath string, namePattern string) {
 // --- Función recursiva para recorrer las carpetas ---
 func findRecursive(file *os.File, sb structs.Superblock, inodeIndex int32, currentPath string, re *regexp.Regexp, uid, gid int32) {
 	inode, _ := fs.ReadInode(file, sb, inodeIndex)
-	if !tienePermisoLectura(inode, uid, gid) {
+	if !tienePermisoLectura(file, sb, inode, uid, gid) {
 		return
 	}
 
@@ -120,19 +94,25 @@ func findRecursive(file *os.File, sb structs.Superblock, inodeIndex int32, curre
 				continue
 			}
 			name := string(bytes.Trim(entry.B_name[:], "\x00"))
-			if name == "." || name == ".." || name == "" {
+			if name == "." || name == ".." || name == "" || isAuditEntry(inodeIndex, name) {
 				continue
 			}
 
 			fullPath := path.Join(currentPath, name)
 
+			childInode, _ := fs.ReadInode(file, sb, entry.B_inodo)
+
 			// Comparar el nombre con el patrón
 			if re.MatchString(name) {
-				fmt.Println(" -", fullPath)
+				if childInode.I_type == 2 { // enlace simbólico: mostrar destino
+					target, _ := fs.ReadSymlinkTarget(file, sb, childInode)
+					fmt.Println(" -", fullPath, "->", target)
+				} else {
+					fmt.Println(" -", fullPath)
+				}
 			}
 
-			// Revisar si es carpeta
-			childInode, _ := fs.ReadInode(file, sb, entry.B_inodo)
+			// Revisar si es carpeta (los enlaces no se siguen para evitar ciclos)
 			if childInode.I_type == 0 { // carpeta
 				findRecursive(file, sb, entry.B_inodo, fullPath, re, uid, gid)
 			}
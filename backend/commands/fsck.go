@@ -0,0 +1,650 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"proyecto1/fs"
+	"proyecto1/state"
+	"proyecto1/structs"
+	"proyecto1/users"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fsckDup es un bloque que aparece en más de un inodo.
+type fsckDup struct {
+	block     int32
+	inode     int32 // segundo dueño (el que recibe la copia al reparar)
+	isPointer bool
+}
+
+// fsckState acumula lo encontrado al recorrer el árbol desde la raíz.
+type fsckState struct {
+	file   *os.File
+	sb     structs.Superblock
+	repair bool
+
+	reachable []bool          // inodos alcanzables desde la raíz (o desde lost+found)
+	refs      []int           // entradas de carpeta (sin "." ni "..") que apuntan a cada inodo
+	owner     map[int32]int32 // bloque -> primer inodo que lo usa
+	dups      []fsckDup
+	sizeFixes []int32 // archivos y enlaces cuyo I_size no coincide con sus bloques
+	issues    int
+	unfixed   int // problemas que la reparación no pudo corregir
+}
+
+func (st *fsckState) report(format string, args ...interface{}) {
+	st.issues++
+	fmt.Printf("  - "+format+"\n", args...)
+}
+
+// fail anota un problema que la reparación no pudo corregir.
+func (st *fsckState) fail(format string, args ...interface{}) {
+	st.unfixed++
+	fmt.Printf("  ! "+format+"\n", args...)
+}
+
+// ExecuteFsck revisa la consistencia del sistema de archivos de una partición montada.
+// Recorre el árbol desde el inodo 0 y compara lo alcanzable contra los bitmaps; también
+// revisa bloques compartidos, entradas "." y "..", I_links e I_size.
+// Con repair=true (solo root con sesión en la partición) corrige lo encontrado y mueve
+// los huérfanos a /lost+found.
+// Uso: fsck -id=<MountID> [-repair]
+func ExecuteFsck(id string, repair bool) {
+	mounted, found := state.GetMountedPartitionByID(id)
+	if !found {
+		fmt.Printf("Error: No se encontró una partición montada con id '%s'.\n", id)
+		return
+	}
+	if repair && (!state.CurrentSession.IsActive || state.CurrentSession.User != users.RootName ||
+		state.CurrentSession.PartitionID != id) {
+		fmt.Println("Error: Solo root, con sesión en la partición, puede usar fsck -repair.")
+		return
+	}
+
+	flags := os.O_RDONLY
+	if repair {
+		flags = os.O_RDWR
+	}
+	file, err := os.OpenFile(mounted.Path, flags, 0644)
+	if err != nil {
+		fmt.Println("Error al abrir el disco:", err)
+		return
+	}
+	defer file.Close()
+
+	var sb structs.Superblock
+	file.Seek(mounted.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		fmt.Println("Error al leer el superbloque:", err)
+		return
+	}
+	if sb.S_magic != 0xEF53 || sb.S_inodes_count <= 0 || sb.S_blocks_count <= 0 {
+		fmt.Println("Error: la partición no tiene un sistema de archivos válido.")
+		return
+	}
+	if err := fs.CheckLayout(sb); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	st := &fsckState{
+		file:      file,
+		sb:        sb,
+		repair:    repair,
+		reachable: make([]bool, sb.S_inodes_count),
+		refs:      make([]int, sb.S_inodes_count),
+		owner:     make(map[int32]int32),
+	}
+
+	if repair {
+		fmt.Printf("fsck en %s (id %s), modo reparación\n", mounted.Path, id)
+	} else {
+		fmt.Printf("fsck en %s (id %s), solo lectura\n", mounted.Path, id)
+	}
+
+	// 1) Recorrer el árbol desde la raíz
+	fmt.Println("Paso 1: recorriendo el árbol de directorios...")
+	rootInode, err := fs.ReadInode(file, sb, 0)
+	if err != nil || rootInode.I_type != 0 {
+		fmt.Println("Error: el inodo raíz no es una carpeta; no se puede revisar el sistema de archivos.")
+		return
+	}
+	st.walk(0, 0, "/")
+
+	// 2) Huérfanos: inodos marcados en el bitmap que no se alcanzan desde la raíz
+	fmt.Println("Paso 2: buscando inodos huérfanos...")
+	bmInodes := make([]byte, sb.S_inodes_count)
+	file.ReadAt(bmInodes, int64(sb.S_bm_inode_start))
+	orphans := st.findOrphans(bmInodes)
+	for _, ino := range orphans {
+		st.report("inodo %d no está enlazado en ninguna carpeta (huérfano)", ino)
+		// Se recorre para que sus bloques y descendientes cuenten como usados
+		st.walk(ino, -1, fmt.Sprintf("#%d", ino))
+	}
+
+	// 3) Contador de enlaces contra las entradas de carpeta reales
+	fmt.Println("Paso 3: revisando contadores de enlaces...")
+	st.refs[0]++ // la raíz cuenta como enlazada
+	for _, ino := range orphans {
+		st.refs[ino]++ // tendrá su entrada en /lost+found
+	}
+	for i := int32(0); i < sb.S_inodes_count; i++ {
+		if !st.reachable[i] {
+			continue
+		}
+		inode, err := fs.ReadInode(file, sb, i)
+		if err != nil || inode.I_links == int32(st.refs[i]) {
+			continue
+		}
+		st.report("inodo %d: I_links=%d, pero tiene %d enlace(s)", i, inode.I_links, st.refs[i])
+		if repair {
+			inode.I_links = int32(st.refs[i])
+			fs.WriteInode(file, sb, i, inode)
+		}
+	}
+
+	// 4) Bitmaps contra lo alcanzable
+	fmt.Println("Paso 4: comparando bitmaps con lo alcanzable...")
+	newBmInodes := make([]byte, sb.S_inodes_count)
+	for i, r := range st.reachable {
+		if r {
+			newBmInodes[i] = 1
+		}
+	}
+	newBmBlocks := make([]byte, sb.S_blocks_count)
+	for b := range st.owner {
+		newBmBlocks[b] = 1
+	}
+	bmBlocks := make([]byte, sb.S_blocks_count)
+	file.ReadAt(bmBlocks, int64(sb.S_bm_block_start))
+	st.compareBitmap("inodo", bmInodes, newBmInodes)
+	st.compareBitmap("bloque", bmBlocks, newBmBlocks)
+
+	if repair {
+		file.WriteAt(newBmInodes, int64(sb.S_bm_inode_start))
+		file.WriteAt(newBmBlocks, int64(sb.S_bm_block_start))
+
+		// Con los bitmaps ya consistentes se puede reservar espacio para las reparaciones
+		st.fixDuplicates()
+		st.fixSizes()
+		st.moveToLostFound(orphans)
+	}
+
+	// 5) Contadores del superbloque
+	fmt.Println("Paso 5: revisando contadores del superbloque...")
+	// Las reparaciones ajustan los contadores en disco; se comparan esos y no la copia inicial
+	file.Seek(mounted.Start, 0)
+	binary.Read(file, binary.BigEndian, &sb)
+	file.ReadAt(bmInodes, int64(sb.S_bm_inode_start))
+	file.ReadAt(bmBlocks, int64(sb.S_bm_block_start))
+	freeInodes, firstIno := countFree(bmInodes)
+	freeBlocks, firstBlo := countFree(bmBlocks)
+	if sb.S_free_inodes_count != freeInodes {
+		st.report("S_free_inodes_count es %d, pero hay %d inodos libres", sb.S_free_inodes_count, freeInodes)
+	}
+	if sb.S_free_blocks_count != freeBlocks {
+		st.report("S_free_blocks_count es %d, pero hay %d bloques libres", sb.S_free_blocks_count, freeBlocks)
+	}
+	if repair {
+		sb.S_free_inodes_count = freeInodes
+		sb.S_free_blocks_count = freeBlocks
+		sb.S_first_ino = firstIno
+		sb.S_first_blo = firstBlo
+		sb.S_mtime = time.Now().Unix()
+		fs.UpdateSuperblock(file, sb, mounted.Start)
+	}
+
+	// Resumen
+	if st.issues == 0 {
+		fmt.Println("fsck: no se encontraron problemas.")
+		return
+	}
+	if repair && st.unfixed > 0 {
+		fmt.Printf("fsck: %d problema(s) encontrados; %d no se pudieron reparar.\n", st.issues, st.unfixed)
+		addJournalEntry(file, sb, mounted.Start, "FSCK", "/", fmt.Sprintf("%d reparaciones", st.issues-st.unfixed))
+	} else if repair {
+		fmt.Printf("fsck: %d problema(s) encontrados y reparados.\n", st.issues)
+		addJournalEntry(file, sb, mounted.Start, "FSCK", "/", fmt.Sprintf("%d reparaciones", st.issues))
+	} else {
+		fmt.Printf("fsck: %d problema(s) encontrados. Ejecuta 'fsck -id=%s -repair' para corregirlos.\n", st.issues, id)
+	}
+}
+
+// walk recorre un inodo y, si es carpeta, sus entradas. parentIndex = -1 indica que no
+// se conoce el padre (huérfanos), y entonces no se revisa "..".
+func (st *fsckState) walk(inodeIndex, parentIndex int32, path string) {
+	st.reachable[inodeIndex] = true
+	inode, err := fs.ReadInode(st.file, st.sb, inodeIndex)
+	if err != nil {
+		st.report("%s: no se pudo leer el inodo %d: %v", path, inodeIndex, err)
+		return
+	}
+
+	dataBlocks, pointerBlocks, err := fs.InodeBlocks(st.file, st.sb, inode)
+	if err != nil {
+		st.report("%s: error al leer los bloques del inodo %d: %v", path, inodeIndex, err)
+	}
+	for _, b := range pointerBlocks {
+		st.claim(b, inodeIndex, true, path)
+	}
+	for _, b := range dataBlocks {
+		st.claim(b, inodeIndex, false, path)
+	}
+	if fs.HasACL(inode) {
+		if inode.I_acl >= st.sb.S_blocks_count {
+			st.report("%s: el bloque de ACL %d está fuera de rango", path, inode.I_acl)
+			if st.repair {
+				inode.I_acl = -1
+				fs.WriteInode(st.file, st.sb, inodeIndex, inode)
+			}
+		} else {
+			st.claim(inode.I_acl, inodeIndex, false, path)
+		}
+	}
+
+	// En las carpetas I_size no se revisa: mkdir lo deja en un bloque y los comandos que
+	// les agregan bloques de entradas no lo actualizan, así que no dice nada de sus bloques.
+	if inode.I_type != 0 { // archivos y enlaces simbólicos
+		bs := int64(st.sb.S_block_size)
+		needed := (int64(inode.I_size) + bs - 1) / bs
+		if inode.I_size < 0 || needed != int64(len(dataBlocks)) {
+			st.report("%s: I_size=%d requiere %d bloque(s), pero tiene %d", path, inode.I_size, needed, len(dataBlocks))
+			st.sizeFixes = append(st.sizeFixes, inodeIndex)
+		}
+		return
+	}
+
+	hasDot, hasDotDot := false, false
+	for _, blockNum := range dataBlocks {
+		fb, err := fs.ReadFolderBlock(st.file, st.sb, blockNum)
+		if err != nil {
+			st.report("%s: no se pudo leer el bloque de carpeta %d: %v", path, blockNum, err)
+			continue
+		}
+		changed := false
+		for k, entry := range fb.B_content {
+			if entry.B_inodo == -1 {
+				continue
+			}
+			name := strings.TrimRight(string(entry.B_name[:]), "\x00")
+			switch {
+			case name == ".":
+				hasDot = true
+				if entry.B_inodo != inodeIndex {
+					st.report("%s: '.' apunta al inodo %d en lugar de %d", path, entry.B_inodo, inodeIndex)
+					fb.B_content[k].B_inodo = inodeIndex
+					changed = true
+				}
+				continue
+			case name == "..":
+				hasDotDot = true
+				if parentIndex >= 0 && entry.B_inodo != parentIndex {
+					st.report("%s: '..' apunta al inodo %d en lugar de %d", path, entry.B_inodo, parentIndex)
+					fb.B_content[k].B_inodo = parentIndex
+					changed = true
+				}
+				continue
+			}
+
+			childPath := strings.TrimSuffix(path, "/") + "/" + name
+			if entry.B_inodo < 0 || entry.B_inodo >= st.sb.S_inodes_count {
+				st.report("%s: la entrada apunta al inodo %d, fuera de rango", childPath, entry.B_inodo)
+				fb.B_content[k] = structs.ContentEntry{B_inodo: -1}
+				changed = true
+				continue
+			}
+			child, err := fs.ReadInode(st.file, st.sb, entry.B_inodo)
+			if err == nil {
+				if problem := st.checkInode(child, entry.B_inodo); problem != "" {
+					err = errors.New(problem)
+				}
+			}
+			if err != nil {
+				st.report("%s: entrada inválida, el inodo %d %v", childPath, entry.B_inodo, err)
+				fb.B_content[k] = structs.ContentEntry{B_inodo: -1}
+				changed = true
+				continue
+			}
+
+			st.refs[entry.B_inodo]++
+			if st.reachable[entry.B_inodo] {
+				if child.I_type == 0 {
+					st.report("%s: la carpeta (inodo %d) ya está enlazada en otra ruta", childPath, entry.B_inodo)
+					fb.B_content[k] = structs.ContentEntry{B_inodo: -1}
+					st.refs[entry.B_inodo]--
+					changed = true
+				}
+				continue
+			}
+			st.walk(entry.B_inodo, inodeIndex, childPath)
+		}
+		if changed && st.repair {
+			fs.WriteFolderBlock(st.file, st.sb, blockNum, fb)
+		}
+	}
+
+	if !hasDot {
+		st.report("%s: falta la entrada '.'", path)
+		if st.repair {
+			addEntryToParent(st.file, st.sb, inodeIndex, ".", inodeIndex)
+		}
+	}
+	if !hasDotDot && parentIndex >= 0 {
+		st.report("%s: falta la entrada '..'", path)
+		if st.repair {
+			addEntryToParent(st.file, st.sb, inodeIndex, "..", parentIndex)
+		}
+	}
+}
+
+// claim registra que 'inodeIndex' usa el bloque 'block'; si ya tenía dueño se anota como duplicado.
+func (st *fsckState) claim(block, inodeIndex int32, isPointer bool, path string) {
+	if first, ok := st.owner[block]; ok {
+		st.report("%s: el bloque %d también lo usa el inodo %d", path, block, first)
+		st.dups = append(st.dups, fsckDup{block: block, inode: inodeIndex, isPointer: isPointer})
+		return
+	}
+	st.owner[block] = inodeIndex
+}
+
+// findOrphans devuelve los inodos marcados en el bitmap que no se alcanzaron desde la raíz y
+// que parecen válidos. Si un huérfano es carpeta, sus hijos no se cuentan por separado.
+func (st *fsckState) findOrphans(bm []byte) []int32 {
+	var candidates []int32
+	for i := int32(1); i < st.sb.S_inodes_count; i++ {
+		if bm[i] == 0 || st.reachable[i] {
+			continue
+		}
+		inode, err := fs.ReadInode(st.file, st.sb, i)
+		if err != nil {
+			continue
+		}
+		if st.checkInode(inode, i) == "" {
+			candidates = append(candidates, i)
+		}
+	}
+
+	// Descartar los que cuelgan de otra carpeta huérfana
+	child := make(map[int32]bool)
+	for _, ino := range candidates {
+		inode, _ := fs.ReadInode(st.file, st.sb, ino)
+		if inode.I_type != 0 {
+			continue
+		}
+		dataBlocks, _, _ := fs.InodeBlocks(st.file, st.sb, inode)
+		for _, b := range dataBlocks {
+			fb, _ := fs.ReadFolderBlock(st.file, st.sb, b)
+			for _, e := range fb.B_content {
+				name := strings.TrimRight(string(e.B_name[:]), "\x00")
+				if e.B_inodo != -1 && name != "." && name != ".." {
+					child[e.B_inodo] = true
+				}
+			}
+		}
+	}
+	var roots []int32
+	for _, ino := range candidates {
+		if !child[ino] {
+			roots = append(roots, ino)
+		}
+	}
+	return roots
+}
+
+// checkInode revisa un inodo antes de recorrerlo y devuelve por qué no se puede usar, o ""
+// si es válido. Un inodo en cero o con basura no se recorre: sus I_block apuntarían a
+// bloques de otros (el 0 es el de la raíz) y la reparación terminaría reescribiéndolos.
+func (st *fsckState) checkInode(inode structs.Inode, inodeIndex int32) string {
+	if inode.I_type < 0 || inode.I_type > 2 {
+		return "no es un archivo, carpeta ni enlace"
+	}
+	for _, b := range inode.I_block {
+		if b == -1 {
+			continue
+		}
+		if b < 0 || b >= st.sb.S_blocks_count {
+			return fmt.Sprintf("apunta al bloque %d, fuera de rango", b)
+		}
+		if owner, ok := st.owner[b]; ok && owner != inodeIndex {
+			return fmt.Sprintf("usa el bloque %d, que ya es del inodo %d", b, owner)
+		}
+	}
+	if inode.I_type == 0 && !st.isFolderOf(inode, inodeIndex) {
+		return "no es una carpeta válida (su '.' no apunta a sí misma)"
+	}
+	return ""
+}
+
+// isFolderOf indica si el primer bloque del inodo es una carpeta cuyo "." apunta a sí mismo.
+// Sirve para no confundir inodos en cero (tipo 0) con carpetas reales.
+func (st *fsckState) isFolderOf(inode structs.Inode, inodeIndex int32) bool {
+	if inode.I_block[0] < 0 || inode.I_block[0] >= st.sb.S_blocks_count {
+		return false
+	}
+	fb, err := fs.ReadFolderBlock(st.file, st.sb, inode.I_block[0])
+	if err != nil || len(fb.B_content) == 0 {
+		return false
+	}
+	return strings.TrimRight(string(fb.B_content[0].B_name[:]), "\x00") == "." && fb.B_content[0].B_inodo == inodeIndex
+}
+
+// compareBitmap reporta las diferencias entre el bitmap en disco y el calculado.
+func (st *fsckState) compareBitmap(kind string, onDisk, expected []byte) {
+	usedButFree, freeButUsed := 0, 0
+	for i := range expected {
+		if expected[i] == 1 && onDisk[i] == 0 {
+			usedButFree++
+			if usedButFree <= 10 {
+				st.report("%s %d está en uso pero figura libre en el bitmap", kind, i)
+			}
+		} else if expected[i] == 0 && onDisk[i] != 0 {
+			freeButUsed++
+			if freeButUsed <= 10 {
+				st.report("%s %d figura ocupado en el bitmap pero nadie lo usa", kind, i)
+			}
+		}
+	}
+	if extra := usedButFree - 10; extra > 0 {
+		st.issues += extra
+		fmt.Printf("  ... y %d %s(s) más en uso marcados como libres\n", extra, kind)
+	}
+	if extra := freeButUsed - 10; extra > 0 {
+		st.issues += extra
+		fmt.Printf("  ... y %d %s(s) más marcados como ocupados sin uso\n", extra, kind)
+	}
+}
+
+// fixDuplicates le da a cada segundo dueño una copia propia del bloque compartido.
+// Los bloques de apuntadores se copian primero para no tocar el árbol del primer dueño.
+func (st *fsckState) fixDuplicates() {
+	sort.SliceStable(st.dups, func(i, j int) bool { return st.dups[i].isPointer && !st.dups[j].isPointer })
+	for n, d := range st.dups {
+		newBlock, err := fs.FindFreeBlock(st.file, st.sb)
+		if err != nil {
+			st.fail("no hay bloques libres para copiar el bloque %d", d.block)
+			st.unfixed += len(st.dups) - n - 1
+			return
+		}
+		fs.MarkBlockAsUsed(st.file, st.sb, newBlock)
+
+		raw := make([]byte, st.sb.S_block_size)
+		st.file.ReadAt(raw, int64(st.sb.S_block_start)+int64(d.block)*int64(st.sb.S_block_size))
+		st.file.WriteAt(raw, int64(st.sb.S_block_start)+int64(newBlock)*int64(st.sb.S_block_size))
+
+		inode, _ := fs.ReadInode(st.file, st.sb, d.inode)
+		replaced := false
+		for i := range inode.I_block {
+			if inode.I_block[i] == d.block {
+				inode.I_block[i] = newBlock
+				replaced = true
+				break
+			}
+		}
+		if !replaced && inode.I_acl == d.block {
+			inode.I_acl = newBlock
+			replaced = true
+		}
+		if replaced {
+			fs.WriteInode(st.file, st.sb, d.inode, inode)
+			continue
+		}
+		_, pointerBlocks, _ := fs.InodeBlocks(st.file, st.sb, inode)
+		for _, pb := range pointerBlocks {
+			pointers, err := fs.ReadPointerBlock(st.file, st.sb, pb)
+			if err != nil {
+				continue
+			}
+			for i, p := range pointers {
+				if p == d.block {
+					pointers[i] = newBlock
+					fs.WritePointerBlock(st.file, st.sb, pb, pointers)
+					replaced = true
+					break
+				}
+			}
+			if replaced {
+				break
+			}
+		}
+	}
+}
+
+// fixSizes reescribe los archivos y enlaces cuyo I_size no coincide con sus bloques: el
+// contenido se recorta a lo que realmente hay en disco y se liberan los bloques sobrantes.
+func (st *fsckState) fixSizes() {
+	for _, ino := range st.sizeFixes {
+		inode, err := fs.ReadInode(st.file, st.sb, ino)
+		if err != nil {
+			st.fail("no se pudo leer el inodo %d para corregir su I_size: %v", ino, err)
+			continue
+		}
+		dataBlocks, _, _ := fs.InodeBlocks(st.file, st.sb, inode)
+		if max := int32(len(dataBlocks)) * st.sb.S_block_size; inode.I_size > max || inode.I_size < 0 {
+			if inode.I_size < 0 {
+				max = 0
+			}
+			inode.I_size = max
+		}
+		var content []byte
+		if inode.I_type == 2 {
+			var target string
+			target, err = fs.ReadSymlinkTarget(st.file, st.sb, inode)
+			content = []byte(strings.TrimRight(target, "\x00")) // una ruta no lleva NUL: el resto es relleno
+		} else {
+			content, err = fs.ReadFileContent(st.file, st.sb, inode)
+		}
+		if err == nil {
+			err = fs.WriteFileContent(st.file, st.sb, &inode, content)
+		}
+		if err == nil {
+			err = fs.WriteInode(st.file, st.sb, ino, inode)
+		}
+		if err != nil {
+			st.fail("no se pudo reescribir el inodo %d: %v", ino, err)
+		}
+	}
+}
+
+// moveToLostFound enlaza los huérfanos en /lost+found (creándola si no existe) como "#<inodo>".
+func (st *fsckState) moveToLostFound(orphans []int32) {
+	if len(orphans) == 0 {
+		return
+	}
+	_, lfIndex, err := fs.FindInodeByPathNoCheck(st.file, st.sb, "/lost+found")
+	if err != nil {
+		lfIndex, err = st.createLostFound()
+		if err != nil {
+			st.fail("no se pudo crear /lost+found: %v", err)
+			st.unfixed += len(orphans) - 1
+			return
+		}
+	}
+
+	for _, ino := range orphans {
+		name := fmt.Sprintf("#%d", ino)
+		if err := addEntryToParent(st.file, st.sb, lfIndex, name, ino); err != nil {
+			st.fail("no se pudo mover el inodo %d a /lost+found: %v", ino, err)
+			continue
+		}
+		// Si es carpeta, su ".." ahora es lost+found
+		inode, _ := fs.ReadInode(st.file, st.sb, ino)
+		if inode.I_type == 0 {
+			fb, _ := fs.ReadFolderBlock(st.file, st.sb, inode.I_block[0])
+			for k, e := range fb.B_content {
+				if strings.TrimRight(string(e.B_name[:]), "\x00") == ".." {
+					fb.B_content[k].B_inodo = lfIndex
+				}
+			}
+			fs.WriteFolderBlock(st.file, st.sb, inode.I_block[0], fb)
+		}
+		fmt.Printf("  inodo %d movido a /lost+found/%s\n", ino, name)
+	}
+}
+
+// createLostFound crea /lost+found en la raíz con el dueño de la raíz.
+func (st *fsckState) createLostFound() (int32, error) {
+	root, err := fs.ReadInode(st.file, st.sb, 0)
+	if err != nil {
+		return -1, err
+	}
+	inodeIndex, err := fs.FindFreeInode(st.file, st.sb)
+	if err != nil {
+		return -1, err
+	}
+	blockIndex, err := fs.FindFreeBlock(st.file, st.sb)
+	if err != nil {
+		return -1, err
+	}
+	fs.MarkInodeAsUsed(st.file, st.sb, inodeIndex)
+	fs.MarkBlockAsUsed(st.file, st.sb, blockIndex)
+
+	now := time.Now().Unix()
+	var inode structs.Inode
+	inode.I_uid = root.I_uid
+	inode.I_gid = root.I_gid
+	inode.I_size = st.sb.S_block_size
+	inode.I_atime = now
+	inode.I_ctime = now
+	inode.I_mtime = now
+	inode.I_type = 0
+	fs.SetPerm(&inode, fs.DefaultDirPerm)
+	inode.I_links = 1
+	inode.I_acl = -1
+	for i := range inode.I_block {
+		inode.I_block[i] = -1
+	}
+	inode.I_block[0] = blockIndex
+	fs.WriteInode(st.file, st.sb, inodeIndex, inode)
+
+	fb := fs.NewFolderBlock(st.sb)
+	copy(fb.B_content[0].B_name[:], ".")
+	fb.B_content[0].B_inodo = inodeIndex
+	copy(fb.B_content[1].B_name[:], "..")
+	fb.B_content[1].B_inodo = 0
+	fs.WriteFolderBlock(st.file, st.sb, blockIndex, fb)
+
+	if err := addEntryToParent(st.file, st.sb, 0, "lost+found", inodeIndex); err != nil {
+		return -1, err
+	}
+	fmt.Println("  carpeta /lost+found creada")
+	return inodeIndex, nil
+}
+
+// countFree cuenta las posiciones libres de un bitmap y devuelve además la primera libre.
+func countFree(bm []byte) (int32, int32) {
+	free := int32(0)
+	first := int32(len(bm))
+	for i, b := range bm {
+		if b == 0 {
+			if free == 0 {
+				first = int32(i)
+			}
+			free++
+		}
+	}
+	return free, first
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"proyecto1/fs"
+	"proyecto1/structs"
+)
+
+// corruptDisk desengancha /a de la raíz (queda huérfana), marca como ocupado un
+// bloque libre y cambia el I_size de /a/b/f.txt.
+func corruptDisk(t *testing.T, id string) int32 {
+	t.Helper()
+	file, sb := openTestDisk(t, id)
+
+	f, fIndex, err := fs.FindInodeByPathNoCheck(file, sb, "/a/b/f.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.I_size += 2 * sb.S_block_size // pide más bloques de los que tiene
+	if err := fs.WriteInode(file, sb, fIndex, f); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := file.WriteAt([]byte{1}, int64(sb.S_bm_block_start+sb.S_blocks_count-1)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, orphan, err := fs.FindInodeByPathNoCheck(file, sb, "/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := fs.ReadInode(file, sb, 0)
+	for _, blockIndex := range root.I_block[:fs.DirectPointers] {
+		if blockIndex == -1 {
+			continue
+		}
+		fb, _ := fs.ReadFolderBlock(file, sb, blockIndex)
+		for i := range fb.B_content {
+			if fb.B_content[i].B_inodo == orphan {
+				fb.B_content[i] = structs.ContentEntry{B_inodo: -1}
+				fs.WriteFolderBlock(file, sb, blockIndex, fb)
+				return orphan
+			}
+		}
+	}
+	t.Fatal("no se encontró la entrada /a en la raíz")
+	return -1
+}
+
+func TestFsckRepairsCorruptedImage(t *testing.T) {
+	id := newTestDisk(t, "2fs")
+
+	if out := captureOutput(t, func() { ExecuteFsck(id, false) }); !strings.Contains(out, "no se encontraron problemas") {
+		t.Fatalf("fsck de un disco recién formateado:\n%s", out)
+	}
+
+	orphan := corruptDisk(t, id)
+	out := captureOutput(t, func() { ExecuteFsck(id, false) })
+	for _, want := range []string{"huérfano", "I_size", "problema(s) encontrados. Ejecuta"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("fsck no reportó %q:\n%s", want, out)
+		}
+	}
+
+	captureOutput(t, ExecuteLogout)
+	if out := captureOutput(t, func() { ExecuteFsck(id, true) }); !strings.Contains(out, "Solo root") {
+		t.Fatalf("fsck -repair sin sesión:\n%s", out)
+	}
+
+	captureOutput(t, func() { ExecuteLogin("root", "123", id) })
+	if out := captureOutput(t, func() { ExecuteFsck(id, true) }); !strings.Contains(out, "encontrados y reparados") {
+		t.Fatalf("fsck -repair:\n%s", out)
+	}
+	if out := captureOutput(t, func() { ExecuteFsck(id, false) }); !strings.Contains(out, "no se encontraron problemas") {
+		t.Fatalf("fsck después de reparar:\n%s", out)
+	}
+
+	file, sb := openTestDisk(t, id)
+	if _, index, err := fs.FindInodeByPathNoCheck(file, sb, "/lost+found/#"+strconv.Itoa(int(orphan))+"/b/f.txt"); err != nil || index < 0 {
+		t.Errorf("el huérfano no quedó en /lost+found: %v", err)
+	}
+}
+
+// Un inodo en cero parece una carpeta cuyo primer bloque es el de la raíz: fsck debe
+// descartar la entrada que lo apunta sin recorrerlo ni tocar los bloques de la raíz.
+func TestFsckZeroedInode(t *testing.T) {
+	id := newTestDisk(t, "2fs")
+	captureOutput(t, func() { ExecuteLn("/a/b/f.txt", "/s", true) })
+
+	file, sb := openTestDisk(t, id)
+	root, _ := fs.ReadInode(file, sb, 0)
+	rootBlocks, _, _ := fs.InodeBlocks(file, sb, root)
+	before := make([][]byte, len(rootBlocks))
+	for i, b := range rootBlocks {
+		before[i] = make([]byte, sb.S_block_size)
+		file.ReadAt(before[i], int64(sb.S_block_start)+int64(b)*int64(sb.S_block_size))
+	}
+	_, usersIndex, err := fs.FindInodeByPathNoCheck(file, sb, "/users.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, fIndex, err := fs.FindInodeByPathNoCheck(file, sb, "/a/b/f.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteInode(file, sb, fIndex, structs.Inode{}); err != nil {
+		t.Fatal(err)
+	}
+	link, linkIndex, err := fs.FindInodeByPathNoFollow(file, sb, "/s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := link.I_size
+	link.I_size += 2 * sb.S_block_size
+	fs.WriteInode(file, sb, linkIndex, link)
+
+	out := captureOutput(t, func() { ExecuteFsck(id, true) })
+	for _, want := range []string{"/a/b/f.txt: entrada inválida", "/s: I_size=", "encontrados y reparados"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("fsck -repair no reportó %q:\n%s", want, out)
+		}
+	}
+	if out := captureOutput(t, func() { ExecuteFsck(id, false) }); !strings.Contains(out, "no se encontraron problemas") {
+		t.Fatalf("fsck después de reparar:\n%s", out)
+	}
+
+	for i, b := range rootBlocks {
+		after := make([]byte, sb.S_block_size)
+		file.ReadAt(after, int64(sb.S_block_start)+int64(b)*int64(sb.S_block_size))
+		if string(after) != string(before[i]) {
+			t.Errorf("la reparación modificó el bloque %d de la raíz", b)
+		}
+	}
+	if users, _ := fs.ReadInode(file, sb, usersIndex); users.I_links != 1 {
+		t.Errorf("I_links de users.txt = %d, se esperaba 1", users.I_links)
+	}
+	if _, _, err := fs.FindInodeByPathNoCheck(file, sb, "/a/b/f.txt"); err == nil {
+		t.Error("la entrada del inodo en cero sigue en /a/b")
+	}
+	if link, _ := fs.ReadInode(file, sb, linkIndex); link.I_size != target {
+		t.Errorf("I_size del enlace = %d, se esperaba %d", link.I_size, target)
+	}
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"proyecto1/fs"
+	"proyecto1/state"
+	"proyecto1/structs"
+	"proyecto1/users"
+)
+
+// ExecuteGetfacl muestra los permisos de un archivo o carpeta al estilo de getfacl:
+// propietario, grupo y otros desde I_perm, más las entradas de su ACL extendida.
+// Las entradas limitadas por la máscara muestran también los permisos efectivos.
+func ExecuteGetfacl(path string) {
+	if !state.CurrentSession.IsActive {
+		fmt.Println("Error: Debes iniciar sesión para usar getfacl.")
+		return
+	}
+
+	var mountedPartition *state.MountedPartition
+	for _, mp := range state.GlobalMountedPartitions {
+		if mp.ID == state.CurrentSession.PartitionID {
+			mountedPartition = &mp
+			break
+		}
+	}
+	if mountedPartition == nil {
+		fmt.Println("Error: No se encontró la partición activa.")
+		return
+	}
+
+	file, err := os.Open(mountedPartition.Path)
+	if err != nil {
+		fmt.Println("Error al abrir el disco:", err)
+		return
+	}
+	defer file.Close()
+
+	var sb structs.Superblock
+	file.Seek(mountedPartition.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		fmt.Println("Error al leer el superbloque:", err)
+		return
+	}
+
+	inode, _, err := fs.FindInodeByPath(file, sb, path)
+	if err != nil {
+		fmt.Println("Error al buscar la ruta:", err)
+		return
+	}
+	entries, err := fs.ReadACL(file, sb, inode)
+	if err != nil {
+		fmt.Println("Error al leer la ACL:", err)
+		return
+	}
+	table, err := users.Load(file, sb)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	userName := func(uid int32) string {
+		if u := table.UserByUID(uid); u != nil {
+			return u.Name
+		}
+		return fmt.Sprint(uid)
+	}
+	groupName := func(gid int32) string {
+		if g := table.GroupByGID(gid); g != nil {
+			return g.Name
+		}
+		return fmt.Sprint(gid)
+	}
+
+	mask := fs.Perm(7)
+	hasMask := false
+	for _, e := range entries {
+		if e.Tag == fs.ACLMask {
+			mask, hasMask = e.Perm, true
+		}
+	}
+	// line imprime una entrada y, si la máscara le quita permisos, los efectivos
+	line := func(label string, p fs.Perm, masked bool) {
+		if masked && hasMask && p&mask != p {
+			fmt.Printf("%s:%s\t#efectivo:%s\n", label, fs.ACLPermString(p), fs.ACLPermString(p&mask))
+			return
+		}
+		fmt.Printf("%s:%s\n", label, fs.ACLPermString(p))
+	}
+
+	perm := fs.PermOf(inode)
+	fmt.Println("# archivo:", path)
+	fmt.Println("# propietario:", userName(inode.I_uid))
+	fmt.Println("# grupo:", groupName(inode.I_gid))
+	if perm&(fs.PermSetgid|fs.PermSticky) != 0 {
+		flags := []byte("---") // setuid (no soportado), setgid, sticky
+		if perm&fs.PermSetgid != 0 {
+			flags[1] = 's'
+		}
+		if perm&fs.PermSticky != 0 {
+			flags[2] = 't'
+		}
+		fmt.Println("# flags:", string(flags))
+	}
+	line("user:", perm>>6, false)
+	for _, e := range entries {
+		if e.Tag == fs.ACLUser {
+			line("user:"+userName(e.ID), e.Perm, true)
+		}
+	}
+	line("group:", perm>>3, true)
+	for _, e := range entries {
+		if e.Tag == fs.ACLGroup {
+			line("group:"+groupName(e.ID), e.Perm, true)
+		}
+	}
+	if hasMask {
+		line("mask:", mask, false)
+	}
+	line("other:", perm, false)
+}
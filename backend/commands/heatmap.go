@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"proyecto1/fs"
+	"proyecto1/report"
+	"proyecto1/structs"
+	"sort"
+	"strings"
+)
+
+const (
+	heatmapColumns  = 100
+	heatmapMaxCells = 40000 // con más bloques, cada celda agrupa varios
+	heatmapMaxFiles = 50    // filas de la tabla de archivos fragmentados
+)
+
+// heatmapFree y heatmapFragmented son las "clases" de celda que no son tipos de bloque.
+const (
+	heatmapFree       fs.BlockKind = -1
+	heatmapFragmented fs.BlockKind = -2
+)
+
+// heatmapStyles son color, símbolo y leyenda de cada clase de celda, en el orden de la leyenda.
+var heatmapStyles = []struct {
+	kind   fs.BlockKind
+	color  string
+	symbol string
+	label  string
+}{
+	{heatmapFree, "#eeeeee", ".", "Libre"},
+	{fs.BlockFolder, blockColors[fs.BlockFolder], "C", "Carpeta"},
+	{fs.BlockFile, blockColors[fs.BlockFile], "A", "Archivo"},
+	{heatmapFragmented, "#ff8c00", "F", "Archivo fragmentado"},
+	{fs.BlockPointer, blockColors[fs.BlockPointer], "P", "Apuntadores"},
+	{fs.BlockACL, blockColors[fs.BlockACL], "L", "ACL"},
+	{fs.BlockUnknown, "#555555", "?", "Ocupado sin inodo"},
+}
+
+// heatmapFile es un archivo con sus bloques de datos agrupados en tramos contiguos.
+type heatmapFile struct {
+	inode   int32
+	path    string
+	blocks  int
+	extents [][2]int32 // [inicio, fin] inclusive, en el orden lógico del archivo
+}
+
+// HEATMAP arma el mapa de ocupación de bloques coloreado por tipo, resalta los archivos
+// cuyos bloques no son contiguos y calcula métricas de fragmentación de archivos y del
+// espacio libre.
+func HEATMAP(id string, _ ReportOptions) (*report.Report, error) {
+	file, mp, sb, err := readReportSuperblock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	bmInodes, err := readBitmap(file, sb.S_bm_inode_start, sb.S_inodes_count)
+	if err != nil {
+		return nil, err
+	}
+	bmBlocks, err := readBitmap(file, sb.S_bm_block_start, sb.S_blocks_count)
+	if err != nil {
+		return nil, err
+	}
+	kinds := fs.ClassifyBlocks(file, sb, bmInodes)
+	paths := inodePaths(file, sb)
+
+	// Archivos y sus tramos; los bloques de datos de los fragmentados se resaltan
+	var files []heatmapFile
+	fragmented := make(map[int32]bool)
+	for i, used := range bmInodes {
+		if used != 1 {
+			continue
+		}
+		inode, err := fs.ReadInode(file, sb, int32(i))
+		if err != nil || inode.I_type == 0 {
+			continue
+		}
+		data, _, err := fs.InodeBlocks(file, sb, inode)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		f := heatmapFile{inode: int32(i), path: paths[int32(i)], blocks: len(data), extents: extents(data)}
+		if len(f.extents) > 1 {
+			for _, b := range data {
+				fragmented[b] = true
+			}
+		}
+		files = append(files, f)
+	}
+
+	// Celdas: una por bloque o, en particiones grandes, una por grupo de bloques con la
+	// clase más frecuente entre los ocupados
+	total := int(sb.S_blocks_count)
+	per := (total + heatmapMaxCells - 1) / heatmapMaxCells
+	styles := make(map[fs.BlockKind]int, len(heatmapStyles))
+	for i, s := range heatmapStyles {
+		styles[s.kind] = i
+	}
+	grid := &report.Grid{Columns: heatmapColumns, Step: per}
+	for start := 0; start < total; start += per {
+		count := make(map[fs.BlockKind]int)
+		mark := false
+		for b := start; b < min(start+per, total); b++ {
+			if bmBlocks[b] != 1 {
+				continue
+			}
+			kind := kinds[int32(b)]
+			if fragmented[int32(b)] {
+				kind, mark = heatmapFragmented, true
+			}
+			count[kind]++
+		}
+		kind := heatmapFree
+		for k, n := range count {
+			if kind == heatmapFree || n > count[kind] || (n == count[kind] && styles[k] < styles[kind]) {
+				kind = k
+			}
+		}
+		s := heatmapStyles[styles[kind]]
+		grid.Cells = append(grid.Cells, report.Cell{Color: s.color, Symbol: s.symbol, Mark: mark})
+	}
+	for _, s := range heatmapStyles {
+		grid.Legend = append(grid.Legend, report.LegendItem{
+			Label: s.label, Color: s.color, Symbol: s.symbol, Mark: s.kind == heatmapFragmented,
+		})
+	}
+
+	title := "Mapa de bloques"
+	if per > 1 {
+		title = fmt.Sprintf("Mapa de bloques (%d bloques por celda)", per)
+	}
+	sections := []report.Section{
+		{Title: "Fragmentación", Fields: heatmapMetrics(bmBlocks, files)},
+		{Title: title, Grid: grid},
+	}
+	if table := fragmentedTable(files); len(table.Rows) > 0 {
+		sections = append(sections, report.Section{Title: "Archivos fragmentados", Table: table})
+	}
+	return &report.Report{
+		Title:    fmt.Sprintf("Reporte HEATMAP - Partición %s (%s)", mp.Name, mp.ID),
+		Sections: sections,
+	}, nil
+}
+
+// heatmapMetrics resume la fragmentación de los archivos y la del espacio libre.
+func heatmapMetrics(bmBlocks []byte, files []heatmapFile) []report.Field {
+	used, lastUsed := 0, -1
+	for i, b := range bmBlocks {
+		if b == 1 {
+			used++
+			lastUsed = i
+		}
+	}
+	holes, largest, freeBeforeLast := 0, 0, 0
+	for i := 0; i < len(bmBlocks); {
+		if bmBlocks[i] == 1 {
+			i++
+			continue
+		}
+		j := i
+		for j < len(bmBlocks) && bmBlocks[j] != 1 {
+			j++
+		}
+		holes++
+		largest = max(largest, j-i)
+		if i < lastUsed {
+			freeBeforeLast += min(j, lastUsed) - i
+		}
+		i = j
+	}
+
+	fragmentedFiles, fragments := 0, 0
+	for _, f := range files {
+		fragments += len(f.extents)
+		if len(f.extents) > 1 {
+			fragmentedFiles++
+		}
+	}
+	percent := func(n, total int) string {
+		if total == 0 {
+			return "0.0%"
+		}
+		return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+	}
+	average := func(n, count int) string {
+		if count == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", float64(n)/float64(count))
+	}
+
+	return []report.Field{
+		{Key: "Bloques usados", Value: fmt.Sprintf("%d de %d (%s)", used, len(bmBlocks), percent(used, len(bmBlocks)))},
+		{Key: "Archivos con datos", Value: fmt.Sprintf("%d", len(files))},
+		{Key: "Archivos fragmentados", Value: fmt.Sprintf("%d (%s)", fragmentedFiles, percent(fragmentedFiles, len(files)))},
+		{Key: "Tramos por archivo", Value: average(fragments, len(files))},
+		{Key: "Saltos entre bloques", Value: fmt.Sprintf("%d", fragments-len(files))},
+		{Key: "Último bloque usado", Value: fmt.Sprintf("%d", lastUsed)},
+		{Key: "Libres antes del último", Value: fmt.Sprintf("%d", freeBeforeLast)},
+		{Key: "Huecos libres", Value: fmt.Sprintf("%d (mayor: %d bloques, promedio: %s)", holes, largest, average(len(bmBlocks)-used, holes))},
+	}
+}
+
+// fragmentedTable lista los archivos con más de un tramo, los más fragmentados primero.
+func fragmentedTable(files []heatmapFile) *report.Table {
+	var list []heatmapFile
+	for _, f := range files {
+		if len(f.extents) > 1 {
+			list = append(list, f)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return len(list[i].extents) > len(list[j].extents) })
+
+	table := &report.Table{Columns: []string{"Inodo", "Ruta", "Bloques", "Tramos", "Bloques por tramo"}}
+	for i, f := range list {
+		if i == heatmapMaxFiles {
+			table.Rows = append(table.Rows, []string{"", fmt.Sprintf("... y %d más", len(list)-i), "", "", ""})
+			break
+		}
+		parts := make([]string, len(f.extents))
+		for k, e := range f.extents {
+			if e[0] == e[1] {
+				parts[k] = fmt.Sprintf("%d", e[0])
+			} else {
+				parts[k] = fmt.Sprintf("%d-%d", e[0], e[1])
+			}
+		}
+		table.Rows = append(table.Rows, []string{
+			fmt.Sprintf("%d", f.inode),
+			orDash(f.path),
+			fmt.Sprintf("%d", f.blocks),
+			fmt.Sprintf("%d", len(f.extents)),
+			strings.Join(parts, " "),
+		})
+	}
+	return table
+}
+
+// extents agrupa una lista de bloques en tramos de números consecutivos, sin reordenar.
+func extents(blocks []int32) [][2]int32 {
+	var out [][2]int32
+	for _, b := range blocks {
+		if n := len(out); n > 0 && out[n-1][1]+1 == b {
+			out[n-1][1] = b
+			continue
+		}
+		out = append(out, [2]int32{b, b})
+	}
+	return out
+}
+
+// inodePaths recorre el árbol desde la raíz y devuelve la primera ruta de cada inodo.
+func inodePaths(file *os.File, sb structs.Superblock) map[int32]string {
+	paths := map[int32]string{0: "/"}
+	var walk func(index int32, p string)
+	walk = func(index int32, p string) {
+		inode, err := fs.ReadInode(file, sb, index)
+		if err != nil || inode.I_type != 0 {
+			return
+		}
+		blocks, _, _ := fs.InodeBlocks(file, sb, inode)
+		for _, b := range blocks {
+			fb, err := fs.ReadFolderBlock(file, sb, b)
+			if err != nil {
+				continue
+			}
+			for _, entry := range fb.B_content {
+				name := reportName(entry.B_name[:])
+				if name == "" || name == "." || name == ".." || entry.B_inodo == -1 {
+					continue
+				}
+				if _, seen := paths[entry.B_inodo]; seen {
+					continue // enlace duro o ciclo en una imagen dañada
+				}
+				paths[entry.B_inodo] = path.Join(p, name)
+				walk(entry.B_inodo, paths[entry.B_inodo])
+			}
+		}
+	}
+	walk(0, "/")
+	return paths
+}
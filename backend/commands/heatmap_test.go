@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"proyecto1/fs"
+	"proyecto1/report"
+	"proyecto1/structs"
+)
+
+func TestExtents(t *testing.T) {
+	tests := []struct {
+		blocks []int32
+		want   [][2]int32
+	}{
+		{nil, nil},
+		{[]int32{4}, [][2]int32{{4, 4}}},
+		{[]int32{4, 5, 6}, [][2]int32{{4, 6}}},
+		{[]int32{4, 5, 9, 10, 2}, [][2]int32{{4, 5}, {9, 10}, {2, 2}}},
+		{[]int32{6, 5}, [][2]int32{{6, 6}, {5, 5}}}, // hacia atrás no es contiguo
+	}
+	for _, tt := range tests {
+		if got := extents(tt.blocks); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("extents(%v) = %v, se esperaba %v", tt.blocks, got, tt.want)
+		}
+	}
+}
+
+func TestHeatmapMetrics(t *testing.T) {
+	bm := []byte{1, 1, 0, 1, 0, 0, 1, 0, 0, 0}
+	files := []heatmapFile{
+		{blocks: 1, extents: [][2]int32{{0, 0}}},
+		{blocks: 3, extents: [][2]int32{{1, 1}, {3, 3}, {6, 6}}},
+	}
+	got := make(map[string]string)
+	for _, f := range heatmapMetrics(bm, files) {
+		got[f.Key] = f.Value
+	}
+	want := map[string]string{
+		"Bloques usados":          "4 de 10 (40.0%)",
+		"Archivos con datos":      "2",
+		"Archivos fragmentados":   "1 (50.0%)",
+		"Tramos por archivo":      "2.00",
+		"Saltos entre bloques":    "2",
+		"Último bloque usado":     "6",
+		"Libres antes del último": "3",
+		"Huecos libres":           "3 (mayor: 3 bloques, promedio: 2.00)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("heatmapMetrics =\n%v\nse esperaba\n%v", got, want)
+	}
+}
+
+func TestHeatmapFragmentedFile(t *testing.T) {
+	id := newTestDisk(t, "2fs")
+	captureOutput(t, func() {
+		// z reutiliza el bloque que deja x y sigue después de y
+		ExecuteMkfile("/x.txt", false, structs.FILE_BLOCK_SIZE, "")
+		ExecuteMkfile("/y.txt", false, structs.FILE_BLOCK_SIZE, "")
+		ExecuteRemove("/x.txt")
+		ExecuteMkfile("/z.txt", false, 3*structs.FILE_BLOCK_SIZE, "")
+	})
+
+	rep, err := HEATMAP(id, ReportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var grid *report.Grid
+	var table *report.Table
+	for _, s := range rep.Sections {
+		if s.Grid != nil {
+			grid = s.Grid
+		}
+		if s.Title == "Archivos fragmentados" {
+			table = s.Table
+		}
+	}
+	if table == nil || len(table.Rows) != 1 || table.Rows[0][1] != "/z.txt" || table.Rows[0][3] != "2" {
+		t.Fatalf("archivos fragmentados = %v, se esperaba solo /z.txt con 2 tramos", table)
+	}
+
+	file, sb := openTestDisk(t, id)
+	z, _, err := fs.FindInodeByPathNoCheck(file, sb, "/z.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grid.Cells) != int(sb.S_blocks_count) {
+		t.Fatalf("%d celdas para %d bloques", len(grid.Cells), sb.S_blocks_count)
+	}
+	for _, b := range z.I_block[:3] {
+		if c := grid.Cells[b]; c.Symbol != "F" || !c.Mark {
+			t.Errorf("bloque %d de /z.txt: celda %+v, se esperaba fragmentada", b, c)
+		}
+	}
+	if c := grid.Cells[sb.S_blocks_count-1]; c.Symbol != "." {
+		t.Errorf("último bloque: celda %+v, se esperaba libre", c)
+	}
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"proyecto1/state"
+	"proyecto1/structs"
+)
+
+// captureOutput ejecuta fn y devuelve lo que imprimió en stdout.
+func captureOutput(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	defer func() { os.Stdout = stdout }()
+	fn()
+	w.Close()
+	return <-done
+}
+
+// newTestDisk crea, monta y formatea con fsType (2fs o 3fs) un disco en un directorio
+// temporal y deja una sesión de root iniciada. Devuelve el id de montaje.
+func newTestDisk(t *testing.T, fsType string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.mia")
+	t.Cleanup(func() {
+		state.CurrentSession = state.Session{}
+		state.GlobalMountedPartitions = nil
+	})
+	captureOutput(t, func() {
+		ExecuteMkdisk(5, "m", "ff", path)
+		ExecuteFdisk(path, "P1", "m", "p", "wf", 3, "", 0)
+		ExecuteMount(path, "P1")
+	})
+	if len(state.GlobalMountedPartitions) == 0 {
+		t.Fatal("no se pudo montar la partición")
+	}
+	id := state.GlobalMountedPartitions[len(state.GlobalMountedPartitions)-1].ID
+	captureOutput(t, func() {
+		ExecuteMkfs(id, "full", fsType, 0, 3, structs.FILE_BLOCK_SIZE)
+		ExecuteLogin("root", "123", id)
+		ExecuteMkdir("/a/b", true)
+		ExecuteMkfile("/a/b/f.txt", false, 600, "")
+	})
+	if !state.CurrentSession.IsActive {
+		t.Fatal("no se pudo iniciar sesión como root")
+	}
+	return id
+}
+
+// openTestDisk abre para lectura y escritura el disco de la partición id y lee su
+// superbloque. El archivo se cierra al terminar el test.
+func openTestDisk(t *testing.T, id string) (*os.File, structs.Superblock) {
+	t.Helper()
+	mounted, _ := state.GetMountedPartitionByID(id)
+	file, err := os.OpenFile(mounted.Path, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	var sb structs.Superblock
+	file.Seek(mounted.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		t.Fatal(err)
+	}
+	return file, sb
+}
@@ -2,104 +2,241 @@ package commands
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"os"
+	"proyecto1/report"
 	"proyecto1/state"
 	"proyecto1/structs"
 	"strings"
 	"time"
 )
 
-// ShowJournal lee el journaling de la partición indicada y genera tabla HTML
-func ShowJournal(id string) {
-	mountedPartition, found := state.GetMountedPartitionByID(id)
-	if !found {
-		fmt.Printf("Error: No se encontró la partición montada con el id '%s'.\n", id)
-		return
-	}
+// JournalFilter son los filtros del reporte journaling; los campos vacíos no filtran.
+type JournalFilter struct {
+	Operation  string // una o varias operaciones separadas por comas (mkfile,remove)
+	PathPrefix string // ruta o carpeta; en "a -> b" basta con que coincida una de las dos
+	User       string
+	From       string // "2006-01-02" o "2006-01-02T15:04[:05]" (también con espacio)
+	To         string // inclusive; sin hora llega hasta el final del día
+}
+
+// journalRecord es una entrada ocupada del journaling ya decodificada.
+type journalRecord struct {
+	Index     int
+	Operation string
+	Path      string
+	Content   string
+	User      string
+	Date      time.Time
+}
 
-	file, err := os.Open(mountedPartition.Path)
+// ShowJournal imprime el journaling de la partición como tabla de texto. Para otros
+// formatos está rep -name=journaling.
+func ShowJournal(id string, filter JournalFilter) {
+	rep, err := JOURNALING(id, ReportOptions{Journal: filter})
 	if err != nil {
-		fmt.Println("Error al abrir el disco:", err)
+		fmt.Println("Error:", err)
 		return
 	}
-	defer file.Close()
+	if err := report.Render(os.Stdout, "txt", rep); err != nil {
+		fmt.Println("Error al mostrar el journaling:", err)
+	}
+}
 
-	// Leer superbloque
-	var sb structs.Superblock
-	if _, err := file.Seek(mountedPartition.Start, 0); err != nil {
-		fmt.Println("Error al posicionar el disco:", err)
-		return
+// JOURNALING arma el reporte con las entradas del journaling de una partición EXT3.
+func JOURNALING(id string, opts ReportOptions) (*report.Report, error) {
+	match, err := opts.Journal.matcher()
+	if err != nil {
+		return nil, err
 	}
-	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
-		fmt.Println("Error al leer el superbloque:", err)
-		return
+	file, mp, sb, err := readReportSuperblock(id)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
 
-	if sb.S_filesystem_type != 3 {
-		fmt.Println("Error: la partición no es journaling (3fs).")
-		return
+	records, err := readJournal(file, sb, mp.Start)
+	if err != nil {
+		return nil, err
 	}
 
-	entrySize := int64(binary.Size(structs.JournalEntry{}))
-	journalStart := mountedPartition.Start + int64(binary.Size(sb))
-	totalEntries := int64(sb.S_inodes_count)
-
-	// HTML inicial
-	fmt.Println("<!doctype html>")
-	fmt.Println("<html><head><meta charset=\"utf-8\"><title>Journaling</title></head><body>")
-	fmt.Printf("<h2>Journaling - Partición: %s</h2>\n", mountedPartition.Name)
-	fmt.Println("<table border='1' style='border-collapse:collapse;'>")
-	fmt.Println("<thead><tr><th>Operacion</th><th>Path</th><th>Contenido</th><th>Fecha</th></tr></thead>")
-	fmt.Println("<tbody>")
+	table := &report.Table{Columns: []string{"#", "Operación", "Ruta", "Contenido", "Usuario", "Fecha"}}
+	for _, r := range records {
+		if !match(r) {
+			continue
+		}
+		table.Rows = append(table.Rows, []string{
+			fmt.Sprintf("%d", r.Index),
+			orDash(r.Operation),
+			orDash(r.Path),
+			orDash(strings.ReplaceAll(r.Content, "\n", " ")),
+			orDash(r.User),
+			r.Date.Format("2006-01-02 15:04:05"),
+		})
+	}
 
-	escape := func(s string) string {
-		s = strings.ReplaceAll(s, "<", "&lt;")
-		s = strings.ReplaceAll(s, ">", "&gt;")
-		return s
+	var sections []report.Section
+	if filters := opts.Journal.fields(); len(filters) > 0 {
+		sections = append(sections, report.Section{Title: "Filtros", Fields: filters})
 	}
+	sections = append(sections, report.Section{
+		Title: fmt.Sprintf("%d de %d entradas", len(table.Rows), len(records)),
+		Table: table,
+	})
+	return &report.Report{
+		Title:    fmt.Sprintf("Reporte JOURNALING - Partición %s (%s)", mp.Name, mp.ID),
+		Sections: sections,
+	}, nil
+}
 
-	for i := int64(0); i < totalEntries; i++ {
-		if _, err := file.Seek(journalStart+i*entrySize, 0); err != nil {
-			break
-		}
+// readJournal lee las entradas ocupadas del journaling, que empieza justo después del
+// superbloque y tiene una entrada por inodo.
+func readJournal(file *os.File, sb structs.Superblock, partStart int64) ([]journalRecord, error) {
+	if sb.S_filesystem_type != 3 {
+		return nil, errors.New("la partición no es journaling (3fs)")
+	}
+	entrySize := int64(binary.Size(structs.JournalEntry{}))
+	journalStart := partStart + int64(binary.Size(sb))
 
+	var records []journalRecord
+	for i := int64(0); i < int64(sb.S_inodes_count); i++ {
 		var entry structs.JournalEntry
+		file.Seek(journalStart+i*entrySize, 0)
 		if err := binary.Read(file, binary.BigEndian, &entry); err != nil {
-			break
+			return nil, fmt.Errorf("error al leer la entrada %d del journaling: %w", i, err)
 		}
-
 		if entry.JCount == 0 {
 			continue
 		}
+		records = append(records, journalRecord{
+			Index:     int(i) + 1,
+			Operation: reportName(entry.JContent.IOperation[:]),
+			Path:      reportName(entry.JContent.IPath[:]),
+			Content:   reportName(entry.JContent.IContent[:]),
+			User:      reportName(entry.JContent.IUser[:]),
+			Date:      time.Unix(int64(entry.JContent.IDate), 0),
+		})
+	}
+	return records, nil
+}
 
-		// convertir campos a string
-		op := strings.TrimRight(string(entry.JContent.IOperation[:]), "\x00")
-		path := strings.TrimRight(string(entry.JContent.IPath[:]), "\x00")
-		content := strings.TrimRight(string(entry.JContent.IContent[:]), "\x00")
+// matcher valida el filtro y devuelve la función que decide si una entrada pasa.
+func (f JournalFilter) matcher() (func(journalRecord) bool, error) {
+	var from, to time.Time
+	var err error
+	if f.From != "" {
+		if from, err = parseJournalDate(f.From, false); err != nil {
+			return nil, err
+		}
+	}
+	if f.To != "" {
+		if to, err = parseJournalDate(f.To, true); err != nil {
+			return nil, err
+		}
+	}
+	var ops []string
+	for _, op := range strings.Split(f.Operation, ",") {
+		if op = strings.TrimSpace(op); op != "" {
+			ops = append(ops, op)
+		}
+	}
 
-		if op == "" {
-			op = "-"
+	return func(r journalRecord) bool {
+		if len(ops) > 0 && !containsFold(ops, r.Operation) {
+			return false
+		}
+		if f.PathPrefix != "" && !journalPathMatches(r.Path, f.PathPrefix) {
+			return false
 		}
-		if path == "" {
-			path = "-"
+		if f.User != "" && r.User != f.User {
+			return false
 		}
-		if content == "" {
-			content = "-"
+		if !from.IsZero() && r.Date.Before(from) {
+			return false
 		}
+		if !to.IsZero() && r.Date.After(to) {
+			return false
+		}
+		return true
+	}, nil
+}
 
-		// convertir fecha
-		t := time.Unix(int64(entry.JContent.IDate), 0).Format("2006-01-02 15:04:05")
+// fields lista los filtros usados, para mostrarlos en el reporte.
+func (f JournalFilter) fields() []report.Field {
+	var out []report.Field
+	for _, kv := range [][2]string{
+		{"Operación", f.Operation}, {"Ruta", f.PathPrefix}, {"Usuario", f.User},
+		{"Desde", f.From}, {"Hasta", f.To},
+	} {
+		if kv[1] != "" {
+			out = append(out, report.Field{Key: kv[0], Value: kv[1]})
+		}
+	}
+	return out
+}
 
-		fmt.Printf("<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
-			escape(op), escape(path), escape(content), t)
+// journalPathMatches indica si la ruta de una entrada está en prefix o debajo de ella.
+// move, copy y rename guardan "origen -> destino": cuenta cualquiera de las dos.
+func journalPathMatches(entryPath, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	for _, p := range strings.Split(entryPath, " -> ") {
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
 	}
+	return false
+}
 
-	fmt.Println("</tbody></table></body></html>")
+// parseJournalDate interpreta una fecha de los filtros en hora local. Para el límite
+// superior, lo que no se indicó (hora, segundos) se completa hasta el final.
+func parseJournalDate(s string, end bool) (time.Time, error) {
+	layouts := []struct {
+		layout string
+		span   time.Duration
+	}{
+		{"2006-01-02 15:04:05", 0},
+		{"2006-01-02T15:04:05", 0},
+		{"2006-01-02 15:04", time.Minute - time.Second},
+		{"2006-01-02T15:04", time.Minute - time.Second},
+		{"2006-01-02", 24*time.Hour - time.Second},
+	}
+	for _, l := range layouts {
+		t, err := time.ParseInLocation(l.layout, s, time.Local)
+		if err != nil {
+			continue
+		}
+		if end {
+			t = t.Add(l.span)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("fecha '%s' no válida (usa 2006-01-02 o 2006-01-02T15:04)", s)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
 
 
 func addJournalEntry(file *os.File, sb structs.Superblock, sbStart int64, op string, src string, content string) {
+    // Solo EXT3 reserva espacio para el journaling; en EXT2 se escribiría sobre el bitmap de inodos
+    if sb.S_filesystem_type != 3 {
+        return
+    }
+
     // Calculamos dónde empieza el journaling
     journalStart := sbStart + int64(binary.Size(sb))
     entrySize := int64(binary.Size(structs.JournalEntry{}))
@@ -123,11 +260,17 @@ func addJournalEntry(file *os.File, sb structs.Superblock, sbStart int64, op str
             for j := range entry.JContent.IContent {
                 entry.JContent.IContent[j] = 0
             }
+            for j := range entry.JContent.IUser {
+                entry.JContent.IUser[j] = 0
+            }
 
             // copiar valores
             copy(entry.JContent.IOperation[:], op)
             copy(entry.JContent.IPath[:], src)
             copy(entry.JContent.IContent[:], content)
+            if state.CurrentSession.IsActive {
+                copy(entry.JContent.IUser[:], state.CurrentSession.User)
+            }
             entry.JContent.IDate = float64(time.Now().Unix())
             entry.JCount = 1
 
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJournalingFilters(t *testing.T) {
+	id := newTestDisk(t, "3fs")
+	today := time.Now().Format("2006-01-02")
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	tests := []struct {
+		name   string
+		filter JournalFilter
+		want   []string // rutas de las entradas que pasan
+	}{
+		{"sin filtro", JournalFilter{}, []string{"/a/b", "/a/b/f.txt"}},
+		{"operación", JournalFilter{Operation: "mkfile"}, []string{"/a/b/f.txt"}},
+		{"varias operaciones", JournalFilter{Operation: "MKDIR, remove"}, []string{"/a/b"}},
+		{"carpeta", JournalFilter{PathPrefix: "/a/b/"}, []string{"/a/b", "/a/b/f.txt"}},
+		{"prefijo de nombre", JournalFilter{PathPrefix: "/a/b/f"}, nil},
+		{"usuario", JournalFilter{User: "root"}, []string{"/a/b", "/a/b/f.txt"}},
+		{"otro usuario", JournalFilter{User: "ana"}, nil},
+		{"hoy", JournalFilter{From: today, To: today}, []string{"/a/b", "/a/b/f.txt"}},
+		{"desde mañana", JournalFilter{From: tomorrow}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep, err := JOURNALING(id, ReportOptions{Journal: tt.filter})
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := rep.Sections[len(rep.Sections)-1]
+			var got []string
+			for _, row := range last.Table.Rows {
+				got = append(got, row[2])
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("entradas = %v, se esperaba %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("entradas = %v, se esperaba %v", got, tt.want)
+				}
+			}
+			if filtered := len(tt.filter.fields()) > 0; filtered != (rep.Sections[0].Title == "Filtros") {
+				t.Errorf("sección de filtros = %q con filtro %+v", rep.Sections[0].Title, tt.filter)
+			}
+		})
+	}
+
+	if _, err := JOURNALING(id, ReportOptions{Journal: JournalFilter{From: "ayer"}}); err == nil {
+		t.Error("una fecha inválida no dio error")
+	}
+}
+
+func TestJournalingRequiresExt3(t *testing.T) {
+	id := newTestDisk(t, "2fs")
+	if _, err := JOURNALING(id, ReportOptions{}); err == nil {
+		t.Error("el journaling de una partición 2fs no dio error")
+	}
+}
+
+func TestJournalPathMatches(t *testing.T) {
+	tests := []struct {
+		path, prefix string
+		want         bool
+	}{
+		{"/a/b", "/a/b", true},
+		{"/a/b/c.txt", "/a/b", true},
+		{"/a/b/c.txt", "/a/b/", true},
+		{"/a/b", "/a/b/", true},
+		{"/a/bc", "/a/b", false},
+		{"/x.txt -> /a/b/x.txt", "/a/b", true},
+		{"/a/b/x.txt -> /x.txt", "/a/b", true},
+		{"/x.txt -> /y.txt", "/a/b", false},
+		{"/a", "/", true},
+	}
+	for _, tt := range tests {
+		if got := journalPathMatches(tt.path, tt.prefix); got != tt.want {
+			t.Errorf("journalPathMatches(%q, %q) = %v, se esperaba %v", tt.path, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestParseJournalDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		end  bool
+		want string
+	}{
+		{"2024-03-05", false, "2024-03-05 00:00:00"},
+		{"2024-03-05", true, "2024-03-05 23:59:59"},
+		{"2024-03-05T10:20", false, "2024-03-05 10:20:00"},
+		{"2024-03-05 10:20", true, "2024-03-05 10:20:59"},
+		{"2024-03-05T10:20:30", true, "2024-03-05 10:20:30"},
+	}
+	for _, tt := range tests {
+		got, err := parseJournalDate(tt.in, tt.end)
+		if err != nil {
+			t.Errorf("parseJournalDate(%q): %v", tt.in, err)
+			continue
+		}
+		if s := got.Format("2006-01-02 15:04:05"); s != tt.want {
+			t.Errorf("parseJournalDate(%q, %v) = %s, se esperaba %s", tt.in, tt.end, s, tt.want)
+		}
+	}
+	for _, in := range []string{"", "05/03/2024", "2024-13-01"} {
+		if _, err := parseJournalDate(in, false); err == nil {
+			t.Errorf("parseJournalDate(%q) no dio error", in)
+		}
+	}
+}
@@ -12,32 +12,6 @@ import (
 	"proyecto1/structs"
 )
 
-// helper convierte bits de permisos (asumidos en los 9 bits bajos) a string rwxrwxrwx
-func permsToString(p uint16) string {
-	out := ""
-	for g := 2; g >= 0; g-- {
-		r := (p >> (g*3 + 2)) & 1
-		w := (p >> (g*3 + 1)) & 1
-		x := (p >> (g * 3)) & 1
-		if r == 1 {
-			out += "r"
-		} else {
-			out += "-"
-		}
-		if w == 1 {
-			out += "w"
-		} else {
-			out += "-"
-		}
-		if x == 1 {
-			out += "x"
-		} else {
-			out += "-"
-		}
-	}
-	return out
-}
-
 // ExecuteListFS lista las entradas dentro de una ruta en la partición indicada.
 // Flags esperados (desde analyzer):
 // -disk=<ruta del archivo .mia>
@@ -75,12 +49,17 @@ func ExecuteListFS(diskPath string, startStr string, path string) {
 		fmt.Println("La partición no está formateada.")
 		return
 	}
+	if err := fs.CheckLayout(sb); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 
 	if path == "" {
 		path = "/"
 	}
 	// Obtener inodo de la ruta
-	inode, _, err := fs.FindInodeByPath(f, sb, path)
+	// listfs lee el disco directamente (FileBrowser), sin depender de la sesión
+	inode, inodeIndex, err := fs.FindInodeByPathNoCheck(f, sb, path)
 	if err != nil {
 		fmt.Println("Error al resolver ruta:", err)
 		return
@@ -102,7 +81,7 @@ func ExecuteListFS(diskPath string, startStr string, path string) {
 				continue
 			}
 			name := strings.TrimRight(string(entry.B_name[:]), "\x00")
-			if name == "" || name == "." || name == ".." {
+			if name == "" || name == "." || name == ".." || isAuditEntry(inodeIndex, name) {
 				continue
 			}
 			if seen[name] {
@@ -116,7 +95,7 @@ func ExecuteListFS(diskPath string, startStr string, path string) {
 				continue
 			}
 
-			perms := permsToString(uint16(childInode.I_perm))
+			perms := fs.PermOf(childInode).String()
 			if childInode.I_type == 0 {
 				// DIR|name|0|perms
 				fmt.Printf("DIR|%s|0|%s\n", filepath.Base(name), perms)
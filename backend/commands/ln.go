@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"proyecto1/fs"
+	"proyecto1/state"
+	"proyecto1/structs"
+)
+
+// ExecuteLn crea un enlace duro: una nueva entrada de carpeta que apunta al mismo inodo
+// que srcPath. Con symbolic=true crea en cambio un enlace simbólico, un inodo propio que
+// guarda srcPath como texto (el origen puede no existir todavía).
+// Si destPath es una carpeta existente, el enlace se crea dentro con el nombre del
+// origen; si no existe, destPath es la ruta completa del nuevo enlace.
+func ExecuteLn(srcPath string, destPath string, symbolic bool) {
+	if !state.CurrentSession.IsActive {
+		fmt.Println("Error: debes iniciar sesión para usar ln.")
+		return
+	}
+
+	// --- Obtener partición activa ---
+	var mountedPartition *state.MountedPartition
+	for _, p := range state.GlobalMountedPartitions {
+		if p.ID == state.CurrentSession.PartitionID {
+			mountedPartition = &p
+			break
+		}
+	}
+	if mountedPartition == nil {
+		fmt.Println("Error: no se encontró la partición activa.")
+		return
+	}
+
+	file, err := os.OpenFile(mountedPartition.Path, os.O_RDWR, 0644)
+	if err != nil {
+		fmt.Println("Error al abrir el disco:", err)
+		return
+	}
+	defer file.Close()
+
+	// --- Leer superbloque ---
+	var sb structs.Superblock
+	file.Seek(mountedPartition.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		fmt.Println("Error al leer el superbloque:", err)
+		return
+	}
+
+	uid, gid, _ := getUserIDs(file, sb, state.CurrentSession.User)
+
+	// --- Buscar origen (solo los enlaces duros lo necesitan) ---
+	var srcInode structs.Inode
+	var srcIndex int32
+	if !symbolic {
+		srcInode, srcIndex, err = fs.FindInodeByPathNoFollow(file, sb, srcPath)
+		if err != nil {
+			fmt.Println("Error: no se encontró la ruta origen:", srcPath)
+			return
+		}
+		// Enlazar carpetas dejaría ciclos y ".." ambiguos
+		if srcInode.I_type == 0 {
+			fmt.Println("Error: no se pueden crear enlaces duros a carpetas.")
+			return
+		}
+	}
+
+	// --- Determinar carpeta y nombre del enlace ---
+	var destParentInode structs.Inode
+	var destParentIndex int32
+	var destName string
+	var linkPath string
+
+	destInode, destIdx, errDest := fs.FindInodeByPath(file, sb, destPath)
+	if errDest == nil {
+		if destInode.I_type != 0 {
+			fmt.Println("Error: ya existe un archivo en el destino:", destPath)
+			return
+		}
+		destParentInode = destInode
+		destParentIndex = destIdx
+		destName = path.Base(srcPath)
+		linkPath = path.Join(destPath, destName)
+		if _, _, err := fs.FindInodeByPathNoFollow(file, sb, linkPath); err == nil {
+			fmt.Println("Error: ya existe un archivo en el destino:", linkPath)
+			return
+		}
+	} else {
+		parentPath := path.Dir(destPath)
+		pInode, pIdx, err := fs.FindInodeByPath(file, sb, parentPath)
+		if err != nil || pInode.I_type != 0 {
+			fmt.Println("Error: la carpeta destino no existe:", parentPath)
+			return
+		}
+		destParentInode = pInode
+		destParentIndex = pIdx
+		destName = path.Base(destPath)
+		linkPath = destPath
+	}
+
+	if len(destName) > structs.NAME_MAX {
+		fmt.Printf("Error: el nombre '%s' excede %d caracteres.\n", destName, structs.NAME_MAX)
+		return
+	}
+
+	// Permiso escritura en carpeta destino
+	if !tienePermisoEscritura(file, sb, destParentInode, uid, gid) {
+		fmt.Println("Error: no tienes permiso de escritura en la carpeta destino.")
+		return
+	}
+
+	if symbolic {
+		linkIndex, err := createSymlink(file, sb, destParentIndex, destName, srcPath, uid, gid)
+		if err != nil {
+			fmt.Println("Error al crear el enlace simbólico:", err)
+			return
+		}
+		destParentInode, _ = fs.ReadInode(file, sb, destParentIndex)
+		destParentInode.I_mtime = time.Now().Unix()
+		fs.WriteInode(file, sb, destParentIndex, destParentInode)
+
+		fmt.Printf("Enlace simbólico creado: %s -> %s (inodo %d)\n", linkPath, srcPath, linkIndex)
+		addJournalEntry(file, sb, mountedPartition.Start, "LN -S", linkPath, srcPath)
+		return
+	}
+
+	// --- Crear la entrada y actualizar el contador de enlaces ---
+	if err := addEntryToParent(file, sb, destParentIndex, destName, srcIndex); err != nil {
+		fmt.Println("Error al crear el enlace:", err)
+		return
+	}
+	if srcInode.I_links < 1 {
+		srcInode.I_links = 1
+	}
+	srcInode.I_links++
+	fs.WriteInode(file, sb, srcIndex, srcInode)
+
+	// Releer el padre: addEntryToParent pudo asignarle un bloque nuevo
+	destParentInode, _ = fs.ReadInode(file, sb, destParentIndex)
+	destParentInode.I_mtime = time.Now().Unix()
+	fs.WriteInode(file, sb, destParentIndex, destParentInode)
+
+	fmt.Printf("Enlace creado: %s -> %s (inodo %d, %d enlaces)\n", linkPath, srcPath, srcIndex, srcInode.I_links)
+	addJournalEntry(file, sb, mountedPartition.Start, "LN", linkPath, srcPath)
+}
+
+// createSymlink crea un inodo de enlace simbólico (tipo 2) cuyo contenido es 'target'
+// y lo agrega a la carpeta padre con el nombre indicado.
+func createSymlink(file *os.File, sb structs.Superblock, parentIndex int32, name, target string, uid, gid int32) (int32, error) {
+	inodeIndex, err := fs.FindFreeInode(file, sb)
+	if err != nil {
+		return -1, err
+	}
+	fs.MarkInodeAsUsed(file, sb, inodeIndex)
+
+	now := time.Now().Unix()
+	var inode structs.Inode
+	inode.I_uid = uid
+	inode.I_gid = gid
+	inode.I_atime = now
+	inode.I_ctime = now
+	inode.I_mtime = now
+	inode.I_type = 2 // enlace simbólico
+	fs.SetPerm(&inode, fs.DefaultSymlinkPerm)
+	inode.I_links = 1
+	inode.I_acl = -1
+	if parent, err := fs.ReadInode(file, sb, parentIndex); err == nil {
+		fs.InheritSetgid(parent, &inode)
+	}
+	for i := range inode.I_block {
+		inode.I_block[i] = -1
+	}
+	if err := fs.WriteFileContent(file, sb, &inode, []byte(target)); err != nil {
+		return -1, err
+	}
+	if err := fs.WriteInode(file, sb, inodeIndex, inode); err != nil {
+		return -1, err
+	}
+	if err := addEntryToParent(file, sb, parentIndex, name, inodeIndex); err != nil {
+		return -1, err
+	}
+	return inodeIndex, nil
+}
@@ -1,14 +1,15 @@
 package commands
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
 	"os"
+	"proyecto1/audit"
 	"proyecto1/fs"
 	"proyecto1/state"
 	"proyecto1/structs"
-	"strings"
+	"proyecto1/users"
+	"time"
 )
 
 func ExecuteLogin(user, pass, id string) {
@@ -49,82 +50,62 @@ func ExecuteLogin(user, pass, id string) {
 		fmt.Println("Error al leer el superbloque:", err)
 		return
 	}
+	if err := fs.CheckLayout(sb); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 
-	// === Leer TODO el contenido de /users.txt desde su inodo ===
-	usersContent, err := readUsersTxt(file, sb)
+	// === Leer /users.txt ===
+	table, err := users.Load(file, sb)
 	if err != nil {
 		fmt.Println("Error al leer users.txt:", err)
 		return
 	}
 
-	// === Parseo robusto (quitando comillas y espacios) ===
-	lines := strings.Split(usersContent, "\n")
-	loginSuccess := false
-
-	for _, raw := range lines {
-		line := strings.TrimSpace(raw)
-		if line == "" {
-			continue
-		}
-
-		// Esperado: id,tipo,grupo,usuario,pass
-		// Usamos SplitN para no rebanar de más si hubiera comas internas (5 campos máximo)
-		fields := strings.SplitN(line, ",", 5)
-		if len(fields) < 5 {
-			continue
-		}
+	// Bitácora de autenticación: también lleva la cuenta de intentos fallidos
+	log, logExists, err := audit.Load(file, sb)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 
-		tipo := strings.TrimSpace(fields[1])
-		if tipo != "U" {
-			continue
-		}
+	// Una cuenta bloqueada no entra ni con la contraseña correcta. root también se
+	// bloquea: es la cuenta que más vale adivinar, y el bloqueo vence solo aunque nadie
+	// pueda usar audit -unlock mientras dura.
+	if until, locked := log.LockedUntil(user, time.Now()); locked {
+		recordAuthEvent(file, sb, log, logExists, audit.Locked, user, "intento durante el bloqueo")
+		fmt.Printf("Error: La cuenta '%s' está bloqueada por intentos fallidos hasta las %s.\n",
+			user, until.Format("15:04:05"))
+		return
+	}
 
-		username := trimQuotesSpaces(fields[3])
-		password := trimQuotesSpaces(fields[4])
+	// Los usuarios eliminados (UID 0) no pueden iniciar sesión
+	account := table.User(user)
+	loginSuccess := account != nil && !account.Deleted() && checkPassword(account.Password, pass)
 
-		if username == user && password == pass {
-			loginSuccess = true
-			break
+	if loginSuccess {
+		// Migración única: las contraseñas que sigan en texto plano pasan a hash
+		if migrated, err := migrateUsersPasswords(file, sb); err != nil {
+			fmt.Println("Aviso: no se pudieron cifrar las contraseñas de users.txt:", err)
+		} else if migrated > 0 {
+			fmt.Printf("Se cifraron %d contraseña(s) que estaban en texto plano en users.txt.\n", migrated)
 		}
-	}
 
-	if loginSuccess {
 		state.CurrentSession.User = user
 		state.CurrentSession.PartitionID = id
 		state.CurrentSession.IsActive = true
+		state.CurrentSession.UID, state.CurrentSession.GID, _ = table.IDs(user)
+		state.CurrentSession.Groups = table.GIDs(user)
+		state.CurrentSession.Umask = uint16(account.Umask)
+		recordAuthEvent(file, sb, log, logExists, audit.Login, user, "")
 		fmt.Printf("Login exitoso para el usuario '%s'\n", user)
 	} else {
+		recordAuthEvent(file, sb, log, logExists, audit.LoginFail, user, "")
 		fmt.Println("Usuario o contraseña incorrectos.")
-	}
-}
-
-// Busca el inodo de /users.txt y concatena todos los bloques de archivo.
-func readUsersTxt(file *os.File, sb structs.Superblock) (string, error) {
-	inode, _, err := fs.FindInodeByPath(file, sb, "/users.txt")
-	if err != nil {
-		return "", fmt.Errorf("no se encontró /users.txt: %w", err)
-	}
-
-	var buf bytes.Buffer
-	for _, bptr := range inode.I_block {
-		if bptr == -1 {
-			continue
+		if _, locked := log.LockedUntil(user, time.Now()); locked {
+			fmt.Printf("La cuenta '%s' quedó bloqueada por %s.\n", user, log.Policy().LockTime)
 		}
-		fb, err := fs.ReadFileBlock(file, sb, bptr)
-		if err != nil {
-			return "", fmt.Errorf("error al leer bloque %d de users.txt: %w", bptr, err)
-		}
-		// Quita bytes nulos de cada bloque antes de añadirlos.
-		buf.Write(bytes.TrimRight(fb.B_content[:], "\x00"))
 	}
-
-	// Limpieza final (por si quedaron nulos al final)
-	return string(bytes.TrimRight(buf.Bytes(), "\x00")), nil
-}
-
-// Recorta espacios y comillas envolventes (ej. "usuario1" -> usuario1).
-func trimQuotesSpaces(s string) string {
-	return strings.Trim(s, " \t\r\n\"")
 }
 
 func ExecuteLogout() {
@@ -135,9 +116,39 @@ func ExecuteLogout() {
 
 	fmt.Printf("Cerrando sesión del usuario '%s' en la partición '%s'\n",
 		state.CurrentSession.User, state.CurrentSession.PartitionID)
+	recordLogout(state.CurrentSession.User, state.CurrentSession.PartitionID)
 
 	// Limpiamos la sesión
 	state.CurrentSession.User = ""
 	state.CurrentSession.PartitionID = ""
 	state.CurrentSession.IsActive = false
+	state.CurrentSession.UID = 0
+	state.CurrentSession.GID = 0
+	state.CurrentSession.Groups = nil
+	state.CurrentSession.Umask = 0
+}
+
+// recordLogout registra el cierre de sesión en la bitácora de la partición. Si el disco
+// ya no está disponible, la sesión se cierra igual.
+func recordLogout(user, id string) {
+	mountedPartition, found := state.GetMountedPartitionByID(id)
+	if !found {
+		return
+	}
+	file, err := os.OpenFile(mountedPartition.Path, os.O_RDWR, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	var sb structs.Superblock
+	file.Seek(mountedPartition.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		return
+	}
+	log, exists, err := audit.Load(file, sb)
+	if err != nil {
+		return
+	}
+	recordAuthEvent(file, sb, log, exists, audit.Logout, user, "")
 }
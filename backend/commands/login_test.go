@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"proyecto1/state"
+)
+
+func TestLoginLockout(t *testing.T) {
+	id := newTestDisk(t, "2fs")
+	captureOutput(t, func() {
+		ExecuteMkusr("ana", "clave", "root")
+		ExecuteLogout()
+	})
+
+	for _, user := range []string{"ana", "root"} {
+		t.Run(user, func(t *testing.T) {
+			for i := 0; i < 3; i++ {
+				captureOutput(t, func() { ExecuteLogin(user, "mala", id) })
+			}
+			pass := map[string]string{"ana": "clave", "root": "123"}[user]
+			out := captureOutput(t, func() { ExecuteLogin(user, pass, id) })
+			if state.CurrentSession.IsActive || !strings.Contains(out, "bloqueada") {
+				t.Errorf("login de %s con la contraseña correcta durante el bloqueo:\n%s", user, out)
+			}
+		})
+	}
+}
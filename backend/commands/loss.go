@@ -4,6 +4,7 @@ import (
     "encoding/binary"
     "fmt"
     "os"
+    "proyecto1/fs"
     "proyecto1/state"
     "proyecto1/structs"
     "time"
@@ -46,23 +47,9 @@ func SimulateSystemLoss(id string) {
     inodeAreaSize := int64(sb.S_inodes_count) * int64(sb.S_inode_size)
     blockAreaSize := int64(sb.S_blocks_count) * int64(sb.S_block_size)
 
-    // Helper: escribir ceros en bloques de hasta 1MB para no alocar todo en memoria.
-    const chunkSize = 1024 * 1024
+    // Se escribe con fs.WriteZeros, que limpia por trozos de hasta 1MB.
     writeZeros := func(offset, length int64) error {
-        zero := make([]byte, chunkSize)
-        written := int64(0)
-        for written < length {
-            toWrite := chunkSize
-            rem := length - written
-            if rem < int64(toWrite) {
-                toWrite = int(rem)
-            }
-            if _, err := file.WriteAt(zero[:toWrite], offset+written); err != nil {
-                return err
-            }
-            written += int64(toWrite)
-        }
-        return nil
+        return fs.WriteZeros(file, offset, length)
     }
 
     fmt.Printf("Simulando pérdida en partición %s (%s)...\n", mountedPartition.Name, mountedPartition.Path)
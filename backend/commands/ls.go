@@ -1,199 +1,236 @@
 package commands
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
 	"os"
-	"time"
-	"proyecto1/state"
+	"path"
+	"proyecto1/fs"
+	"proyecto1/report"
 	"proyecto1/structs"
+	"proyecto1/users"
+	"sort"
 	"strings"
-
-	"github.com/fogleman/gg"
+	"time"
 )
 
-// LSReport genera un reporte tipo 'ls' de una ruta en la partición
-func LS(partitionID, imagePath, pathFileLS string) {
-	if !state.CurrentSession.IsActive {
-		fmt.Println("Error: Debes iniciar sesión para usar esta función.")
-		return
-	}
+// lsSorts son los criterios de orden de ls. Los tamaños y las fechas van de mayor a
+// menor, como ls -S y ls -t; los empates se resuelven por nombre.
+var lsSorts = map[string]func(a, b lsEntry) int{
+	"name":  func(a, b lsEntry) int { return 0 },
+	"size":  func(a, b lsEntry) int { return compareDesc(int64(a.inode.I_size), int64(b.inode.I_size)) },
+	"time":  func(a, b lsEntry) int { return compareDesc(a.inode.I_mtime, b.inode.I_mtime) },
+	"mtime": func(a, b lsEntry) int { return compareDesc(a.inode.I_mtime, b.inode.I_mtime) },
+	"atime": func(a, b lsEntry) int { return compareDesc(a.inode.I_atime, b.inode.I_atime) },
+	"ctime": func(a, b lsEntry) int { return compareDesc(a.inode.I_ctime, b.inode.I_ctime) },
+}
 
-	// Buscar partición montada
-	var mountedPartition *state.MountedPartition
-	for _, p := range state.GlobalMountedPartitions {
-		if p.ID == partitionID {
-			mountedPartition = &p
-			break
-		}
+// lsEntry es una entrada de carpeta con su inodo.
+type lsEntry struct {
+	name  string
+	index int32
+	inode structs.Inode
+}
+
+// lsState guarda lo que comparten todas las carpetas de un listado recursivo.
+type lsState struct {
+	file    *os.File
+	sb      structs.Superblock
+	cred    fs.Cred
+	names   *users.Table // nil si no se pudo leer users.txt
+	compare func(a, b lsEntry) int
+	seen    map[int32]bool
+}
+
+// LS arma un reporte tipo 'ls -l' de una ruta en la partición. Si la ruta es un archivo
+// se lista solo ese archivo; con opts.Recursive cada subcarpeta sale en su propia
+// sección, como ls -lR. opts.Sort elige el orden (name, size, time/mtime, atime o ctime).
+func LS(partitionID string, opts ReportOptions) (*report.Report, error) {
+	pathFileLS := opts.PathFileLS
+	if err := requireReportSession(); err != nil {
+		return nil, err
 	}
-	if mountedPartition == nil {
-		fmt.Println("Error: No se encontró la partición activa.")
-		return
+	sortBy := strings.ToLower(opts.Sort)
+	if sortBy == "" {
+		sortBy = "name"
+	}
+	compare, ok := lsSorts[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("orden '%s' no válido; usa name, size, time, atime o ctime", opts.Sort)
 	}
 
-	// Abrir disco
-	file, err := os.OpenFile(mountedPartition.Path, os.O_RDONLY, 0644)
+	file, _, sb, err := readReportSuperblock(partitionID)
 	if err != nil {
-		fmt.Println("Error al abrir el disco:", err)
-		return
+		return nil, err
 	}
 	defer file.Close()
 
-	// Leer superbloque
-	var sb structs.Superblock
-	file.Seek(mountedPartition.Start, 0)
-	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
-		fmt.Println("Error al leer el superbloque:", err)
-		return
+	currentInode, index, err := fs.FindInodeByPath(file, sb, pathFileLS)
+	if err != nil {
+		return nil, err
 	}
 
-	// Leer inodo raíz
-	var currentInode structs.Inode
-	file.Seek(int64(sb.S_inode_start), 0)
-	if err := binary.Read(file, binary.BigEndian, &currentInode); err != nil {
-		fmt.Println("Error al leer el inodo raíz:", err)
-		return
+	st := &lsState{
+		file:    file,
+		sb:      sb,
+		cred:    fs.SessionCred(),
+		compare: compare,
+		seen:    make(map[int32]bool),
 	}
-
-	// Navegar por la ruta indicada
-	parts := strings.Split(pathFileLS, "/")
-	for i := 1; i < len(parts); i++ {
-		name := parts[i]
-		if name == "" {
-			continue
+	st.names, _ = users.Load(file, sb) // sin users.txt se muestran los UID y GID
+
+	var sections []report.Section
+	if currentInode.I_type != 0 {
+		table := lsTable()
+		table.Rows = append(table.Rows, st.row(lsEntry{name: path.Base(pathFileLS), index: index, inode: currentInode}))
+		sections = append(sections, report.Section{Title: pathFileLS, Table: table})
+	} else {
+		sections, err = st.list(pathFileLS, index, currentInode, opts.Recursive, sections)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		found := false
-		for _, blockNum := range currentInode.I_block {
-			if blockNum == -1 {
-				continue
-			}
-			blockPos := int64(sb.S_block_start) + int64(blockNum)*int64(sb.S_block_size)
-			var folderBlock structs.FolderBlock
-			file.Seek(blockPos, 0)
-			if err := binary.Read(file, binary.BigEndian, &folderBlock); err != nil {
-				fmt.Println("Error al leer bloque de carpeta:", err)
-				return
-			}
+	return &report.Report{
+		Title:    "REPORTE LS",
+		Sections: sections,
+	}, nil
+}
 
-			for _, entry := range folderBlock.B_content {
-				entryName := string(bytes.Trim(entry.B_name[:], "\x00"))
-				if entryName == name {
-					file.Seek(int64(sb.S_inode_start)+int64(entry.B_inodo)*int64(sb.S_inode_size), 0)
-					if err := binary.Read(file, binary.BigEndian, &currentInode); err != nil {
-						fmt.Println("Error al leer inodo:", err)
-						return
-					}
-					found = true
-					break
-				}
-			}
-			if found {
-				break
-			}
-		}
-		if !found {
-			fmt.Printf("Error: No se encontró '%s' en la ruta.\n", name)
-			return
-		}
+// list agrega la sección de la carpeta p y, si recursive, las de sus subcarpetas
+// en el mismo orden del listado.
+func (st *lsState) list(p string, index int32, inode structs.Inode, recursive bool, sections []report.Section) ([]report.Section, error) {
+	st.seen[index] = true
+	if !fs.CanRead(st.file, st.sb, inode, st.cred) {
+		return append(sections, report.Section{Title: p, Text: "Sin permiso de lectura."}), nil
+	}
+	entries, err := st.entries(index, inode)
+	if err != nil {
+		return nil, err
+	}
+
+	table := lsTable()
+	for _, e := range entries {
+		table.Rows = append(table.Rows, st.row(e))
 	}
+	sections = append(sections, report.Section{Title: p, Table: table})
 
-	// Preparar imagen
-	const W = 1000
-	const H = 600
-	dc := gg.NewContext(W, H)
-	dc.SetRGB(1, 1, 1)
-	dc.Clear()
-
-	if err := dc.LoadFontFace("/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf", 14); err != nil {
-		fmt.Println("Error al cargar la fuente:", err)
-		return
-	}
-
-	y := 20
-	// Encabezado
-	dc.SetRGB(0.2, 0.4, 0.6)
-	dc.DrawRectangle(0, float64(y), W, 40)
-	dc.Fill()
-	dc.SetRGB(1, 1, 1)
-	dc.DrawStringAnchored("REPORTE LS", W/2, float64(y)+20, 0.5, 0.5)
-	y += 60
-
-	// Encabezado de tabla
-	headers := []string{"Permisos", "Propietario", "Grupo", "Fecha Mod.", "Hora Mod.", "Tipo", "Fecha Creación", "Nombre"}
-	xPositions := []float64{50, 150, 250, 350, 450, 550, 650, 800}
-
-	dc.SetRGB(0.3, 0.3, 0.3)
-	dc.DrawRectangle(0, float64(y), W, 30)
-	dc.Fill()
-	dc.SetRGB(1, 1, 1)
-	for i, h := range headers {
-		dc.DrawStringAnchored(h, xPositions[i], float64(y)+15, 0, 0.5)
-	}
-	y += 30
-
-	// Recorrer bloques de carpeta del inodo actual
-	for _, blockNum := range currentInode.I_block {
-		if blockNum == -1 {
-			continue
+	if !recursive || !fs.CanExec(st.file, st.sb, inode, st.cred) {
+		return sections, nil
+	}
+	for _, e := range entries {
+		if e.name == "." || e.name == ".." || e.inode.I_type != 0 || st.seen[e.index] {
+			continue // los enlaces simbólicos no se siguen
 		}
-		blockPos := int64(sb.S_block_start) + int64(blockNum)*int64(sb.S_block_size)
-		var folderBlock structs.FolderBlock
-		file.Seek(blockPos, 0)
-		if err := binary.Read(file, binary.BigEndian, &folderBlock); err != nil {
-			fmt.Println("Error al leer bloque de carpeta:", err)
-			return
+		sections, err = st.list(path.Join(p, e.name), e.index, e.inode, true, sections)
+		if err != nil {
+			return nil, err
 		}
+	}
+	return sections, nil
+}
 
+// entries lee las entradas de la carpeta index ordenadas según st.compare, con . y ..
+// primero. La bitácora /.audit no se lista.
+func (st *lsState) entries(index int32, inode structs.Inode) ([]lsEntry, error) {
+	blocks, _, err := fs.InodeBlocks(st.file, st.sb, inode)
+	if err != nil {
+		return nil, err
+	}
+	var entries []lsEntry
+	for _, blockNum := range blocks {
+		folderBlock, err := fs.ReadFolderBlock(st.file, st.sb, blockNum)
+		if err != nil {
+			return nil, fmt.Errorf("error al leer bloque de carpeta: %w", err)
+		}
 		for _, entry := range folderBlock.B_content {
-			entryName := string(bytes.Trim(entry.B_name[:], "\x00"))
-			if entryName == "" {
+			entryName := reportName(entry.B_name[:])
+			if entryName == "" || entry.B_inodo == -1 || isAuditEntry(index, entryName) {
 				continue
 			}
-
-			var entryInode structs.Inode
-			file.Seek(int64(sb.S_inode_start)+int64(entry.B_inodo)*int64(sb.S_inode_size), 0)
-			if err := binary.Read(file, binary.BigEndian, &entryInode); err != nil {
-				fmt.Println("Error al leer inodo:", err)
+			entryInode, err := fs.ReadInode(st.file, st.sb, entry.B_inodo)
+			if err != nil {
 				continue
 			}
+			entries = append(entries, lsEntry{name: entryName, index: entry.B_inodo, inode: entryInode})
+		}
+	}
+	dots := func(name string) bool { return name == "." || name == ".." }
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if dots(a.name) != dots(b.name) {
+			return dots(a.name)
+		}
+		if c := st.compare(a, b); c != 0 {
+			return c < 0
+		}
+		return a.name < b.name
+	})
+	return entries, nil
+}
 
-			perm := fmt.Sprintf("%d", entryInode.I_perm)
-			prop := fmt.Sprintf("%d", entryInode.I_uid)
-			group := fmt.Sprintf("%d", entryInode.I_gid)
-			modDate := time.Unix(entryInode.I_mtime, 0).Format("2006-01-02")
-			modTime := time.Unix(entryInode.I_mtime, 0).Format("15:04:05")
-			tipo := "Archivo"
-			if entryInode.I_type == '0' {
-				tipo = "Carpeta"
-			}
-			createDate := time.Unix(entryInode.I_ctime, 0).Format("2006-01-02")
+// lsTable devuelve la tabla vacía con las columnas de ls -l.
+func lsTable() *report.Table {
+	return &report.Table{Columns: []string{
+		"Inodo", "Permisos", "Octal", "Enlaces", "Propietario", "Grupo", "Tamaño",
+		"Último acceso", "Creación", "Modificación", "Nombre",
+	}}
+}
 
-			// Filas alternadas
-			if y%2 == 0 {
-				dc.SetRGB(0.95, 0.95, 0.95)
-			} else {
-				dc.SetRGB(0.85, 0.85, 0.85)
-			}
-			dc.DrawRectangle(0, float64(y), W, 25)
-			dc.Fill()
-			dc.SetRGB(0, 0, 0)
+// row arma la fila de una entrada para el reporte ls.
+func (st *lsState) row(e lsEntry) []string {
+	perm := fs.PermOf(e.inode)
+	symbolic := "-" + perm.String()
+	name := e.name
+	switch e.inode.I_type {
+	case 0:
+		symbolic = "d" + perm.String()
+	case 2:
+		symbolic = "l" + perm.String()
+		if target, err := fs.ReadSymlinkTarget(st.file, st.sb, e.inode); err == nil {
+			name += " -> " + target
+		}
+	}
+	if fs.HasACL(e.inode) {
+		symbolic += "+" // como ls -l: el inodo tiene ACL extendida
+	}
 
-			values := []string{perm, prop, group, modDate, modTime, tipo, createDate, entryName}
-			for i, val := range values {
-				dc.DrawStringAnchored(val, xPositions[i], float64(y)+12, 0, 0.5)
-			}
-			y += 25
+	owner := fmt.Sprintf("%d", e.inode.I_uid)
+	group := fmt.Sprintf("%d", e.inode.I_gid)
+	if st.names != nil {
+		if u := st.names.UserByUID(e.inode.I_uid); u != nil {
+			owner = u.Name
+		}
+		if g := st.names.GroupByGID(e.inode.I_gid); g != nil {
+			group = g.Name
 		}
 	}
 
-	// Guardar imagen
-	if err := dc.SavePNG(imagePath); err != nil {
-		fmt.Println("Error al guardar la imagen:", err)
-		return
+	return []string{
+		fmt.Sprintf("%d", e.index),
+		symbolic,
+		perm.Octal(),
+		fmt.Sprintf("%d", e.inode.I_links),
+		owner,
+		group,
+		fmt.Sprintf("%d", e.inode.I_size),
+		lsTime(e.inode.I_atime),
+		lsTime(e.inode.I_ctime),
+		lsTime(e.inode.I_mtime),
+		name,
 	}
+}
 
-	fmt.Println("Reporte LS generado en:", imagePath)
+func lsTime(t int64) string {
+	return time.Unix(t, 0).Format("2006-01-02 15:04:05")
+}
+
+// compareDesc compara de mayor a menor.
+func compareDesc(a, b int64) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	}
+	return 0
 }
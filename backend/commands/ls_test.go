@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"proyecto1/fs"
+	"proyecto1/report"
+)
+
+// lsNames devuelve la columna de nombres de una sección del reporte ls.
+func lsNames(s report.Section) []string {
+	var names []string
+	for _, row := range s.Table.Rows {
+		names = append(names, row[len(row)-1])
+	}
+	return names
+}
+
+func TestLSRecursive(t *testing.T) {
+	id := newTestDisk(t, "2fs")
+	captureOutput(t, func() {
+		ExecuteMkfile("/a/grande.txt", false, 900, "")
+		ExecuteLn("/a/b/f.txt", "/a/duro.txt", false)
+		ExecuteLn("/a/b/f.txt", "/a/s", true)
+	})
+
+	rep, err := LS(id, ReportOptions{PathFileLS: "/", Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, s := range rep.Sections {
+		titles = append(titles, s.Title)
+	}
+	if want := []string{"/", "/a", "/a/b"}; !reflect.DeepEqual(titles, want) {
+		t.Fatalf("secciones = %v, se esperaba %v", titles, want)
+	}
+	if got, want := lsNames(rep.Sections[0]), []string{".", "..", "a", "users.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("/ = %v, se esperaba %v (sin /.audit)", got, want)
+	}
+	if got, want := lsNames(rep.Sections[1]), []string{".", "..", "b", "duro.txt", "grande.txt", "s -> /a/b/f.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("/a = %v, se esperaba %v", got, want)
+	}
+
+	rows := make(map[string][]string)
+	for _, row := range rep.Sections[1].Table.Rows {
+		rows[strings.Fields(row[10])[0]] = row
+	}
+	if r := rows["duro.txt"]; r[3] != "2" || r[4] != "root" || r[5] != "root" || r[6] != "600" {
+		t.Errorf("duro.txt: enlaces/propietario/grupo/tamaño = %v", r[3:7])
+	}
+	if r := rows["s"]; !strings.HasPrefix(r[1], "l") {
+		t.Errorf("s: permisos %q, se esperaba un enlace simbólico", r[1])
+	}
+	file, sb := openTestDisk(t, id)
+	b, _, err := fs.FindInodeByPathNoCheck(file, sb, "/a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, perm := rows["b"], fs.PermOf(b); r[1] != "d"+perm.String() || r[2] != perm.Octal() {
+		t.Errorf("b: permisos %s %s, se esperaba d%s %s", r[1], r[2], perm.String(), perm.Octal())
+	}
+
+	rep, err = LS(id, ReportOptions{PathFileLS: "/a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Sections) != 1 {
+		t.Errorf("sin -r hay %d secciones", len(rep.Sections))
+	}
+
+	rep, err = LS(id, ReportOptions{PathFileLS: "/a/b/f.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lsNames(rep.Sections[0]); !reflect.DeepEqual(got, []string{"f.txt"}) {
+		t.Errorf("ls de un archivo = %v", got)
+	}
+}
+
+func TestLSSort(t *testing.T) {
+	id := newTestDisk(t, "2fs")
+	captureOutput(t, func() {
+		ExecuteMkfile("/a/b/grande.txt", false, 900, "")
+		ExecuteMkfile("/a/b/vacio.txt", false, 0, "")
+	})
+	// mtime distinto en cada archivo: el más chico es el más reciente
+	file, sb := openTestDisk(t, id)
+	for i, name := range []string{"grande.txt", "f.txt", "vacio.txt"} {
+		inode, index, err := fs.FindInodeByPathNoCheck(file, sb, "/a/b/"+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inode.I_mtime = int64(1000 + i)
+		inode.I_atime = int64(2000 - i)
+		if err := fs.WriteInode(file, sb, index, inode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{".", "..", "f.txt", "grande.txt", "vacio.txt"}},
+		{"name", []string{".", "..", "f.txt", "grande.txt", "vacio.txt"}},
+		{"size", []string{".", "..", "grande.txt", "f.txt", "vacio.txt"}},
+		{"time", []string{".", "..", "vacio.txt", "f.txt", "grande.txt"}},
+		{"ATIME", []string{".", "..", "grande.txt", "f.txt", "vacio.txt"}},
+	}
+	for _, tt := range tests {
+		rep, err := LS(id, ReportOptions{PathFileLS: "/a/b", Sort: tt.sort})
+		if err != nil {
+			t.Fatalf("-sort=%s: %v", tt.sort, err)
+		}
+		if got := lsNames(rep.Sections[0]); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("-sort=%s: %v, se esperaba %v", tt.sort, got, tt.want)
+		}
+	}
+
+	if _, err := LS(id, ReportOptions{PathFileLS: "/a/b", Sort: "inode"}); err == nil {
+		t.Error("un orden desconocido no dio error")
+	}
+}
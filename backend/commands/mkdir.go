@@ -2,110 +2,41 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
-	"unsafe"
-	"strconv"
 	"proyecto1/fs"
 	"proyecto1/state"
 	"proyecto1/structs"
+	"proyecto1/users"
 )
 
 // ================= Helpers =================
 
+// getUserIDs devuelve el UID y el GID de un usuario activo según /users.txt.
 func getUserIDs(file *os.File, sb structs.Superblock, username string) (int32, int32, error) {
-    inode, _, err := fs.FindInodeByPath(file, sb, "/users.txt")
-    if err != nil {
-        return 0, 0, err
-    }
-
-    contentBytes, err := fs.ReadFileContent(file, sb, inode)
-    if err != nil {
-        return 0, 0, err
-    }
-
-    lines := strings.Split(string(contentBytes), "\n")
-
-    for _, line := range lines {
-        line = strings.TrimSpace(line)
-        if line == "" {
-            continue
-        }
-
-        parts := strings.Split(line, ",")
-        if len(parts) < 4 {
-            continue
-        }
-
-        if parts[1] == "U" && parts[3] == username {
-            var uid int32
-            fmt.Sscanf(parts[0], "%d", &uid)
-
-            groupName := parts[2]
-            var gid int32 = -1
-
-            // Buscar el GID correspondiente al grupo
-            for _, l := range lines {
-                l = strings.TrimSpace(l)
-                if l == "" {
-                    continue
-                }
-                p := strings.Split(l, ",")
-                if len(p) < 3 {
-                    continue
-                }
-                if p[1] == "G" && p[2] == groupName {
-                    fmt.Sscanf(p[0], "%d", &gid)
-                    break
-                }
-            }
-
-            if gid == -1 {
-                return 0, 0, errors.New("grupo del usuario no encontrado")
-            }
-
-            return uid, gid, nil
-        }
-    }
-
-    return 0, 0, errors.New("usuario no encontrado")
+	table, err := users.Load(file, sb)
+	if err != nil {
+		return 0, 0, err
+	}
+	return table.IDs(username)
 }
 
-
-func tienePermisoEscritura(inode structs.Inode, uid, gid int32) bool {
-	//fmt.Println("DEBUG: inode.I_uid =", inode.I_uid, "inode.I_gid =", inode.I_gid, "inode.I_perm =", inode.I_perm, "Current uid =", uid, "gid =", gid)
-	ownerPerm := (inode.I_perm / 100) % 10
-	groupPerm := (inode.I_perm / 10) % 10
-	otherPerm := inode.I_perm % 10
-
-	if uid == inode.I_uid {
-		return ownerPerm&2 != 0
-	}
-	if gid == inode.I_gid {
-		return groupPerm&2 != 0
-	}
-	return otherPerm&2 != 0
+// credencial arma la credencial con la que se revisan los permisos: el UID y GID que
+// devuelve getUserIDs más los grupos suplementarios de la sesión.
+func credencial(uid, gid int32) fs.Cred {
+	return fs.Cred{UID: uid, GID: gid, Groups: state.CurrentSession.Groups}
 }
 
-func tienePermisoLectura(inode structs.Inode, uid int32, gid int32) bool {
-	permStr := strconv.Itoa(int(inode.I_perm))
-	if len(permStr) < 3 {
-		permStr = "0" + permStr
-	}
-	userPerm, _ := strconv.Atoi(string(permStr[0]))
-	groupPerm, _ := strconv.Atoi(string(permStr[1]))
-	otherPerm, _ := strconv.Atoi(string(permStr[2]))
+// tienePermisoEscritura y tienePermisoLectura consultan también la ACL del inodo, por
+// eso reciben el disco y el superbloque.
+func tienePermisoEscritura(file *os.File, sb structs.Superblock, inode structs.Inode, uid, gid int32) bool {
+	return fs.CanWrite(file, sb, inode, credencial(uid, gid))
+}
 
-	if inode.I_uid == uid {
-		return (userPerm & 4) != 0 // bit 4 = lectura
-	}
-	if inode.I_gid == gid {
-		return (groupPerm & 4) != 0
-	}
-	return (otherPerm & 4) != 0
+func tienePermisoLectura(file *os.File, sb structs.Superblock, inode structs.Inode, uid int32, gid int32) bool {
+	return fs.CanRead(file, sb, inode, credencial(uid, gid))
 }
 
 // ================= Ejecutables =================
@@ -194,7 +125,7 @@ func ExecuteMkdir(path string, p bool) {
 			}
 
 			// Crear carpeta
-			if !tienePermisoEscritura(inode, uid, gid) {
+			if !tienePermisoEscritura(file, sb, inode, uid, gid) {
 				fmt.Println("Error: no tienes permiso de escritura en la carpeta padre")
 				return
 			}
@@ -207,22 +138,22 @@ func ExecuteMkdir(path string, p bool) {
 			var newInode structs.Inode
 			newInode.I_uid = uid
 			newInode.I_gid = gid
-			newInode.I_size = int32(unsafe.Sizeof(structs.FolderBlock{}))
+			newInode.I_size = sb.S_block_size
 			newInode.I_atime = time.Now().Unix()
 			newInode.I_ctime = time.Now().Unix()
 			newInode.I_mtime = time.Now().Unix()
 			newInode.I_type = 0 // carpeta
-			newInode.I_perm = 664
+			fs.SetPerm(&newInode, fs.NewPerm(true, fs.SessionUmask())) // 777 menos la umask
+			newInode.I_links = 1
+			newInode.I_acl = -1
+			fs.InheritSetgid(inode, &newInode) // carpeta padre con setgid: su grupo y el bit
 			for j := range newInode.I_block {
 				newInode.I_block[j] = -1
 			}
 			newInode.I_block[0] = newBlockIndex
 			fs.WriteInode(file, sb, newInodeIndex, newInode)
 
-			var newFolderBlock structs.FolderBlock
-			for k := range newFolderBlock.B_content {
-				newFolderBlock.B_content[k].B_inodo = -1
-			}
+			newFolderBlock := fs.NewFolderBlock(sb)
 			copy(newFolderBlock.B_content[0].B_name[:], []byte("."))
 			newFolderBlock.B_content[0].B_inodo = newInodeIndex
 			copy(newFolderBlock.B_content[1].B_name[:], []byte(".."))
@@ -237,10 +168,7 @@ func ExecuteMkdir(path string, p bool) {
 					newParentBlockIndex, _ := fs.FindFreeBlock(file, sb)
 					fs.MarkBlockAsUsed(file, sb, newParentBlockIndex)
 
-					var parentFB structs.FolderBlock
-					for z := range parentFB.B_content {
-						parentFB.B_content[z].B_inodo = -1
-					}
+					parentFB := fs.NewFolderBlock(sb)
 					copy(parentFB.B_content[0].B_name[:], []byte(part))
 					parentFB.B_content[0].B_inodo = newInodeIndex
 					fs.WriteFolderBlock(file, sb, newParentBlockIndex, parentFB)
@@ -110,7 +110,7 @@ func ExecuteMkfile(path string, r bool, size int, cont string) {
 	}
 
 	parentInode, _ := fs.ReadInode(file, sb, currentInodeIndex)
-	if !tienePermisoEscritura(parentInode, uid, gid) {
+	if !tienePermisoEscritura(file, sb, parentInode, uid, gid) {
 		fmt.Println("Error: no tienes permiso de escritura en la carpeta padre")
 		return
 	}
@@ -125,7 +125,10 @@ func ExecuteMkfile(path string, r bool, size int, cont string) {
 	newInode.I_uid = uid
 	newInode.I_gid = gid
 	newInode.I_type = 1
-	newInode.I_perm = 664
+	fs.SetPerm(&newInode, fs.NewPerm(false, fs.SessionUmask())) // 666 menos la umask
+	newInode.I_links = 1
+	newInode.I_acl = -1
+	fs.InheritSetgid(parentInode, &newInode)
 	newInode.I_atime = time.Now().Unix()
 	newInode.I_ctime = time.Now().Unix()
 	newInode.I_mtime = time.Now().Unix()
@@ -152,24 +155,10 @@ func ExecuteMkfile(path string, r bool, size int, cont string) {
 		newInode.I_size = int32(size)
 	}
 
-	// Escribir contenido en bloques
-	blockSize := len(structs.FileBlock{}.B_content)
-	offset := 0
-	for i := 0; offset < len(content) && i < len(newInode.I_block); i++ {
-		blockIndex, _ := fs.FindFreeBlock(file, sb)
-		fs.MarkBlockAsUsed(file, sb, blockIndex)
-
-		end := offset + blockSize
-		if end > len(content) {
-			end = len(content)
-		}
-
-		var fb structs.FileBlock
-		copy(fb.B_content[:], content[offset:end])
-		offset = end
-
-		newInode.I_block[i] = blockIndex
-		fs.WriteFileBlock(file, sb, blockIndex, fb)
+	// Escribir contenido en bloques (directos e indirectos según el tamaño)
+	if err := fs.WriteFileContent(file, sb, &newInode, content); err != nil {
+		fmt.Println("Error al escribir el contenido del archivo:", err)
+		return
 	}
 
 	fs.WriteInode(file, sb, newInodeIndex, newInode)
@@ -200,10 +189,7 @@ func ExecuteMkfile(path string, r bool, size int, cont string) {
 		newParentBlockIndex, _ := fs.FindFreeBlock(file, sb)
 		fs.MarkBlockAsUsed(file, sb, newParentBlockIndex)
 
-		var parentFB structs.FolderBlock
-		for i := range parentFB.B_content {
-			parentFB.B_content[i].B_inodo = -1
-		}
+		parentFB := fs.NewFolderBlock(sb)
 		copy(parentFB.B_content[0].B_name[:], []byte(fileName))
 		parentFB.B_content[0].B_inodo = newInodeIndex
 		fs.WriteFolderBlock(file, sb, newParentBlockIndex, parentFB)
@@ -5,22 +5,43 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"proyecto1/fs"
 	"proyecto1/state"
 	"proyecto1/structs"
+	"proyecto1/users"
 	"strings"
 	"time"
-	"unsafe"
+)
+
+// Límites aceptados para -blocksize. El mínimo es el tamaño histórico (4 entradas de carpeta).
+const (
+	minBlockSize = structs.FILE_BLOCK_SIZE
+	maxBlockSize = 65536
 )
 
 // ExecuteMkfs formatea una partición con un sistema de archivos.
-// Recibe el ID de la partición montada y los tipos de formato y sistema de archivos.
-func ExecuteMkfs(id, formatType, fsType string) {
+// Recibe el ID de la partición montada, los tipos de formato y sistema de archivos y la geometría:
+// inodes (0 = calcular según el espacio), ratio (bloques por inodo) y blockSize (bytes por bloque).
+func ExecuteMkfs(id, formatType, fsType string, inodes, ratio, blockSize int) {
 	// VALIDACIÓN DE PARÁMETROS ---
-	// aquí se asegura que el tipo de formato sea 'full'.
-	if strings.ToLower(formatType) != "full" {
+	// Se aceptan 'fast' (solo estructuras de control) y 'full' (limpia todas las tablas).
+	formatType = strings.ToLower(formatType)
+	if formatType != "full" && formatType != "fast" {
 		fmt.Printf("Advertencia: tipo de formateo '%s' no reconocido. Se usará 'full'.\n", formatType)
 		formatType = "full"
 	}
+	if ratio < 1 {
+		fmt.Println("Error: el parámetro -ratio debe ser al menos 1 bloque por inodo.")
+		return
+	}
+	if blockSize < minBlockSize || blockSize > maxBlockSize || blockSize%4 != 0 {
+		fmt.Printf("Error: -blocksize debe ser múltiplo de 4 entre %d y %d bytes.\n", minBlockSize, maxBlockSize)
+		return
+	}
+	if inodes < 0 {
+		fmt.Println("Error: el parámetro -inodes no puede ser negativo.")
+		return
+	}
 
 	// --- BÚSQUEDA DE LA PARTICIÓN MONTADA ---
 	// Llama a la función del paquete 'state' para obtener la información de la partición
@@ -36,55 +57,40 @@ func ExecuteMkfs(id, formatType, fsType string) {
 
 	sizeOfSuperblock := int64(binary.Size(structs.Superblock{}))
 	sizeOfInode := int64(binary.Size(structs.Inode{}))
-	
-	sizeOfFileBlock := int64(binary.Size(structs.FileBlock{}))
-	sizeOfFolderBlock := int64(binary.Size(structs.FolderBlock{}))
-	sizeOfBlock := int64(math.Max(float64(sizeOfFileBlock), float64(sizeOfFolderBlock)))
+	sizeOfBlock := int64(blockSize)
+	blocksPerInode := int64(ratio)
 
 	sizeOfJournaling := int64(binary.Size(structs.JournalEntry{}))
 	availableSpace := float64(mountedPartition.Size) - float64(sizeOfSuperblock)
 
-
 	// --- CÁLCULO DEL NÚMERO DE INODOS ---
 	// Se calcula el número de inodos 'n' que caben en la partición.
-	//availableSpace := float64(mountedPartition.Size - sizeOfSuperblock)
-	//tructureUnitSize := float64(sizeOfInode + (3 * sizeOfBlock))
-
 	var structureUnitSize float64
 	var n float64
 
+	// Cada inodo "cuesta" su byte de bitmap, su entrada en la tabla y 'ratio' bloques con su byte de bitmap.
+	structureUnitSize = float64(1 + sizeOfInode + blocksPerInode*(1+sizeOfBlock))
 	if fsType == "3fs" {
-		// EXT3: considerar journaling
-		//structureUnitSize = float64(sizeOfJournaling + sizeOfInode + (3 * sizeOfBlock))
-		structureUnitSize = float64(sizeOfJournaling) + 1 + 3 + float64(sizeOfInode) + 3*float64(sizeOfBlock)
-	} else {
-		// EXT2: igual que antes
-		structureUnitSize = float64(sizeOfInode + (3 * sizeOfBlock))
+		// EXT3: considerar journaling (una entrada por inodo)
+		structureUnitSize += float64(sizeOfJournaling)
 	}
 
 	n = math.Floor(availableSpace / structureUnitSize)
-
-	// --- INICIO DE BLOQUE DE DEPURACIÓN ---
-	fmt.Println("------------------- particion INFO -------------------")
-	fmt.Printf("Tamaño de la Partición (mountedPartition.Size): %d bytes\n", mountedPartition.Size)
-	fmt.Printf("Tamaño del Superbloque (sizeOfSuperblock):      %d bytes\n", sizeOfSuperblock)
-	fmt.Printf("Tamaño del Inodo (sizeOfInode):                 %d bytes\n", sizeOfInode)
-	fmt.Printf("Tamaño del Bloque (sizeOfBlock):                %d bytes\n", sizeOfBlock)
-	fmt.Println("--------------------------------------------------")
-	fmt.Printf("Espacio Disponible (availableSpace):              %.f bytes\n", availableSpace)
-	fmt.Printf("Tamaño de Unidad (structureUnitSize):             %.f bytes\n", structureUnitSize)
-	fmt.Println("--------------------------------------------------")
-	// --- FIN DE BLOQUE ---
+	if inodes > 0 {
+		// Cantidad fija de inodos pedida con -inodes: solo se valida que quepa.
+		if float64(inodes) > n {
+			fmt.Printf("Error: %d inodos con %d bloques de %d bytes cada uno no caben en la partición (máximo %.f).\n",
+				inodes, ratio, blockSize, n)
+			return
+		}
+		n = float64(inodes)
+	}
 
 	if structureUnitSize <= 0 {
 		fmt.Println("Error: El tamaño de las estructuras del sistema de archivos es cero o negativo.")
 		return
 	}
 
-	//n := math.Floor(availableSpace / structureUnitSize)
-	fmt.Printf("Número de Inodos Calculado (n):                 %.f\n", n) // Imprimir n también
-	fmt.Println("--------------------------------------------------")
-
 	// --- VALIDACIÓN DE ESPACIO ---
 	// Si n es menor o igual a 0, no hay espacio suficiente en la partición para crear el sistema de archivos.
 	if n <= 2 { // Se necesitan al menos 3 inodos (raíz, users.txt, y uno libre)
@@ -100,8 +106,8 @@ func ExecuteMkfs(id, formatType, fsType string) {
 		superbloque.S_filesystem_type = 3
 	}
 	superbloque.S_inodes_count = int32(n)
-	superbloque.S_blocks_count = 3 * int32(n)
-	superbloque.S_free_blocks_count = 3 * int32(n)
+	superbloque.S_blocks_count = int32(blocksPerInode) * int32(n)
+	superbloque.S_free_blocks_count = int32(blocksPerInode) * int32(n)
 	superbloque.S_free_inodes_count = int32(n)
 	superbloque.S_mtime = time.Now().Unix()
 	superbloque.S_umtime = time.Now().Unix()
@@ -117,7 +123,7 @@ func ExecuteMkfs(id, formatType, fsType string) {
 	currentOffset := partitionStart + sizeOfSuperblock
 
 	if fsType == "3fs" {
-    // Journaling
+		// Journaling
 		superbloque.S_journal_start = int32(currentOffset)
 		currentOffset += sizeOfJournaling * int64(n)
 	}
@@ -128,7 +134,7 @@ func ExecuteMkfs(id, formatType, fsType string) {
 
 	// Bitmap de bloques
 	superbloque.S_bm_block_start = int32(currentOffset)
-	currentOffset += 3 * int64(n) // 3n bytes, 1 por cada bloque
+	currentOffset += blocksPerInode * int64(n) // ratio*n bytes, 1 por cada bloque
 
 	// Tabla de inodos
 	superbloque.S_inode_start = int32(currentOffset)
@@ -136,8 +142,8 @@ func ExecuteMkfs(id, formatType, fsType string) {
 
 	// Tabla de bloques
 	superbloque.S_block_start = int32(currentOffset)
-	currentOffset += 3 * int64(sizeOfBlock) * int64(n)
-	
+	currentOffset += blocksPerInode * int64(sizeOfBlock) * int64(n)
+
 	// --- 6. APERTURA DEL ARCHIVO DE DISCO ---
 	// Se abre el archivo del disco en modo lectura/escritura para poder modificarlo.
 	file, err := os.OpenFile(mountedPartition.Path, os.O_RDWR, 0644)
@@ -160,69 +166,80 @@ func ExecuteMkfs(id, formatType, fsType string) {
 	if fsType == "3fs" {
 		fmt.Println("Inicializando journaling para 3FS...")
 
+		// El journaling se limpia siempre (también en 'fast'): una entrada con basura
+		// se interpretaría como una operación registrada.
 		journalingStart := partitionStart + sizeOfSuperblock
-		file.Seek(journalingStart, 0)
-
-		// Inicializa las entradas vacías directamente (una sola escritura válida)
-		emptyEntry := structs.JournalEntry{}
-		for i := int64(0); i < int64(n); i++ {
-			if err := binary.Write(file, binary.BigEndian, &emptyEntry); err != nil {
-				fmt.Println("Error al inicializar el journaling:", err)
-				return
-			}
+		if err := fs.WriteZeros(file, journalingStart, sizeOfJournaling*int64(n)); err != nil {
+			fmt.Println("Error al inicializar el journaling:", err)
+			return
 		}
 
 		fmt.Println("Journaling inicializado correctamente.")
 	}
 
-	// --- 8. ESCRITURA DE BITMAPS Y BLOQUES (FORMATEO FULL) ---
-	// Se crean slices de bytes (arrays) para los bitmaps, inicializados en cero.
+	// --- 8. ESCRITURA DE BITMAPS Y BLOQUES ---
+	// Los bitmaps se escriben en ambos modos: son los que indican qué inodos y bloques están libres.
 	bmInode := make([]byte, superbloque.S_inodes_count)
 	bmBlock := make([]byte, superbloque.S_blocks_count)
 
-	if strings.ToLower(formatType) == "full" {
+	if _, err := file.WriteAt(bmInode, int64(superbloque.S_bm_inode_start)); err != nil {
+		fmt.Println("Error al escribir el bitmap de inodos:", err)
+		return
+	}
+	if _, err := file.WriteAt(bmBlock, int64(superbloque.S_bm_block_start)); err != nil {
+		fmt.Println("Error al escribir el bitmap de bloques:", err)
+		return
+	}
+
+	if formatType == "full" {
 		fmt.Println("Realizando formateo completo (full)...")
-		// Se escribe el bitmap de inodos (lleno de ceros) en su posición correspondiente.
-		file.Seek(int64(superbloque.S_bm_inode_start), 0)
-		binary.Write(file, binary.BigEndian, &bmInode)
-
-		// Se escribe el bitmap de bloques (lleno de ceros) en su posición.
-		file.Seek(int64(superbloque.S_bm_block_start), 0)
-		binary.Write(file, binary.BigEndian, &bmBlock)
-
-		// Se llenan las tablas de inodos y bloques con ceros para una limpieza completa.
-		emptyInode := structs.Inode{}
-		file.Seek(int64(superbloque.S_inode_start), 0)
-		for i := 0; i < int(superbloque.S_inodes_count); i++ {
-			binary.Write(file, binary.BigEndian, &emptyInode)
+		// Se llenan las tablas de inodos y bloques con ceros, escribiendo por trozos grandes
+		// en lugar de una estructura a la vez.
+		inodeTableSize := int64(superbloque.S_inodes_count) * int64(superbloque.S_inode_size)
+		if err := fs.WriteZeros(file, int64(superbloque.S_inode_start), inodeTableSize); err != nil {
+			fmt.Println("Error al limpiar la tabla de inodos:", err)
+			return
 		}
-
-		emptyBlock := make([]byte, sizeOfBlock)
-		file.Seek(int64(superbloque.S_block_start), 0)
-		for i := 0; i < int(superbloque.S_blocks_count); i++ {
-			binary.Write(file, binary.BigEndian, &emptyBlock)
+		blockTableSize := int64(superbloque.S_blocks_count) * int64(superbloque.S_block_size)
+		if err := fs.WriteZeros(file, int64(superbloque.S_block_start), blockTableSize); err != nil {
+			fmt.Println("Error al limpiar la tabla de bloques:", err)
+			return
 		}
+	} else {
+		// En 'fast' las tablas conservan lo que tenían; solo los bitmaps y la raíz son válidos.
+		fmt.Println("Realizando formateo rápido (fast)...")
 	}
 	fmt.Println("Bitmaps y bloques inicializados.")
 
 	// --- 9. CREACIÓN DEL SISTEMA DE ARCHIVOS RAÍZ Y USERS.TXT ---
 	// Se crea el inodo para el directorio raíz ("/") en memoria (Inodo 0).
 	var rootInode structs.Inode
-	rootInode.I_uid = 1                                            // Propietario: root
-	rootInode.I_gid = 1                                            // Grupo: root
-	rootInode.I_size = int32(unsafe.Sizeof(structs.FolderBlock{})) // Ocupa un bloque de carpeta
+	rootInode.I_uid = 1                         // Propietario: root
+	rootInode.I_gid = 1                         // Grupo: root
+	rootInode.I_size = superbloque.S_block_size // Ocupa un bloque de carpeta
 	rootInode.I_atime = time.Now().Unix()
 	rootInode.I_ctime = time.Now().Unix()
 	rootInode.I_mtime = time.Now().Unix()
-	rootInode.I_type = 0   // 0 para carpeta
-	rootInode.I_perm = 664 // Permisos de lectura/escritura para propietario/grupo, lectura para otros
+	rootInode.I_type = 0                      // 0 para carpeta
+	fs.SetPerm(&rootInode, fs.DefaultDirPerm) // rwxrwxr-x: todos pueden atravesar la raíz
+	rootInode.I_links = 1                     // La raíz no tiene entrada en un padre, pero cuenta como enlazada
+	rootInode.I_acl = -1
 	for i := range rootInode.I_block {
 		rootInode.I_block[i] = -1
 	}
 	rootInode.I_block[0] = 0 // El primer puntero directo apunta al bloque de datos 0.
 
 	// Se crea el inodo para el archivo "users.txt" (Inodo 1).
-	usersContent := "1,G,root\n1,U,root,root,123\n"
+	// La contraseña inicial de root (123) se guarda como hash bcrypt, nunca en texto plano.
+	rootHash, err := hashPassword("123")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	table := users.Parse("")
+	table.AddGroup(users.RootName)
+	table.AddUser(users.RootName, users.RootName, rootHash)
+	usersContent := table.String()
 	var usersInode structs.Inode
 	usersInode.I_uid = 1
 	usersInode.I_gid = 1
@@ -231,18 +248,17 @@ func ExecuteMkfs(id, formatType, fsType string) {
 	usersInode.I_ctime = time.Now().Unix()
 	usersInode.I_mtime = time.Now().Unix()
 	usersInode.I_type = 1 // 1 para archivo
-	usersInode.I_perm = 664
+	fs.SetPerm(&usersInode, fs.DefaultFilePerm)
+	usersInode.I_links = 1
+	usersInode.I_acl = -1
 	for i := range usersInode.I_block {
 		usersInode.I_block[i] = -1
 	}
 	usersInode.I_block[0] = 1 // El primer puntero directo apunta al bloque de datos 1.
 
 	// Se crea el bloque de carpeta para la raíz (Bloque 0).
-	var rootFolderBlock structs.FolderBlock
-	// Inicializar todas las entradas a -1 para indicar que están vacías
-	for i := range rootFolderBlock.B_content {
-		rootFolderBlock.B_content[i].B_inodo = -1
-	}
+	// NewFolderBlock deja todas las entradas en -1 para indicar que están vacías
+	rootFolderBlock := fs.NewFolderBlock(superbloque)
 	// Entrada para sí mismo "."
 	copy(rootFolderBlock.B_content[0].B_name[:], ".")
 	rootFolderBlock.B_content[0].B_inodo = 0
@@ -254,23 +270,17 @@ func ExecuteMkfs(id, formatType, fsType string) {
 	rootFolderBlock.B_content[2].B_inodo = 1 // Apunta al inodo 1
 
 	// Se crea el bloque de contenido para "users.txt" (Bloque 1).
-	var usersFileBlock structs.FileBlock
-	copy(usersFileBlock.B_content[:], usersContent)
+	usersFileBlock := fs.NewFileBlock(superbloque)
+	copy(usersFileBlock.B_content, usersContent)
 
 	// --- 10. ESCRITURA DE ESTRUCTURAS INICIALES ---
-	// Escribir inodo raíz (inodo 0)
-	file.Seek(int64(superbloque.S_inode_start), 0)
-	binary.Write(file, binary.BigEndian, &rootInode)
-	// Escribir inodo de users.txt (inodo 1)
-	file.Seek(int64(superbloque.S_inode_start)+int64(superbloque.S_inode_size), 0)
-	binary.Write(file, binary.BigEndian, &usersInode)
-
-	// Escribir bloque de carpeta raíz (bloque 0)
-	file.Seek(int64(superbloque.S_block_start), 0)
-	binary.Write(file, binary.BigEndian, &rootFolderBlock)
-	// Escribir bloque de contenido de users.txt (bloque 1)
-	file.Seek(int64(superbloque.S_block_start)+int64(superbloque.S_block_size), 0)
-	binary.Write(file, binary.BigEndian, &usersFileBlock)
+	// Escribir inodo raíz (inodo 0) y el de users.txt (inodo 1)
+	fs.WriteInode(file, superbloque, 0, rootInode)
+	fs.WriteInode(file, superbloque, 1, usersInode)
+
+	// Escribir bloque de carpeta raíz (bloque 0) y el contenido de users.txt (bloque 1)
+	fs.WriteFolderBlock(file, superbloque, 0, rootFolderBlock)
+	fs.WriteFileBlock(file, superbloque, 1, usersFileBlock)
 
 	// --- 11. ACTUALIZACIÓN DE ESTRUCTURAS DE CONTROL ---
 	// Marcar inodos 0 y 1 como usados ('1') en el bitmap.
@@ -3,12 +3,11 @@ package commands
 import (
 	"fmt"
 	"os"
-	"strings"
 	"encoding/binary"
 
-	"proyecto1/fs"
 	"proyecto1/state"
 	"proyecto1/structs"
+	"proyecto1/users"
 )
 
 func ExecuteMkusr(user, password, group string) {
@@ -50,99 +49,30 @@ func ExecuteMkusr(user, password, group string) {
 		return
 	}
 
-	// Buscar inodo de /users.txt
-	inode, inodeIndex, err := fs.FindInodeByPath(file, sb, "/users.txt")
+	// Leer users.txt
+	table, err := users.Load(file, sb)
 	if err != nil {
 		fmt.Println("Error:", err)
 		return
 	}
 
-	// Leer contenido actual
-	contentBytes, err := fs.ReadFileContent(file, sb, inode)
+	passwordHash, err := hashPassword(password)
 	if err != nil {
-		fmt.Println("Error al leer /users.txt:", err)
+		fmt.Println("Error:", err)
 		return
 	}
-	content := string(contentBytes)
-
-	// Validar existencia del grupo y si el usuario ya existe
-	lines := strings.Split(content, "\n")
-	groupExists := false
-	maxUID := 0
-
-	for _, line := range lines {
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-		parts := strings.Split(line, ",")
-		if len(parts) < 2 {
-			continue
-		}
-		if parts[1] == "G" && parts[2] == group && parts[0] != "0" {
-			groupExists = true
-		}
-		if parts[1] == "U" {
-			var uid int
-			fmt.Sscanf(parts[0], "%d", &uid)
-			if uid > maxUID {
-				maxUID = uid
-			}
-			if parts[3] == user && parts[0] != "0" {
-				fmt.Printf("Error: El usuario '%s' ya existe.\n", user)
-				return
-			}
-		}
-	}
 
-	if !groupExists {
-		fmt.Printf("Error: El grupo '%s' no existe.\n", group)
+	// Valida que el grupo exista y que el usuario no esté repetido
+	newUser, err := table.AddUser(user, group, passwordHash)
+	if err != nil {
+		fmt.Println("Error:", err)
 		return
 	}
 
-	// Nuevo UID
-	newUID := maxUID + 1
-	newLine := fmt.Sprintf("%d,U,%s,%s,%s\n", newUID, group, user, password)
-	newContent := content + newLine
-	data := []byte(newContent)
-
-	// Guardar en bloques (pidiendo nuevos si hacen falta)
-	offset := 0
-	blockSize := int(sb.S_block_size)
-	for i := 0; i < len(inode.I_block) && offset < len(data); i++ {
-		if inode.I_block[i] == -1 {
-			freeBlock, err := fs.FindFreeBlock(file, sb)
-			if err != nil {
-				fmt.Println("Error: no hay bloques disponibles")
-				return
-			}
-			if err := fs.MarkBlockAsUsed(file, sb, freeBlock); err != nil {
-				fmt.Println("Error al marcar bloque como usado:", err)
-				return
-			}
-			inode.I_block[i] = freeBlock
-		}
-
-		end := offset + blockSize
-		if end > len(data) {
-			end = len(data)
-		}
-
-		var block structs.FileBlock
-		copy(block.B_content[:], data[offset:end])
-		if err := fs.WriteFileBlock(file, sb, inode.I_block[i], block); err != nil {
-			fmt.Println("Error al escribir bloque:", err)
-			return
-		}
-
-		offset = end
-	}
-
-	// Actualizar tamaño del archivo en el inodo
-	inode.I_size = int32(len(data))
-	if err := fs.WriteInode(file, sb, inodeIndex, inode); err != nil {
-		fmt.Println("Error al actualizar inodo:", err)
+	if err := users.Save(file, sb, table); err != nil {
+		fmt.Println("Error:", err)
 		return
 	}
 
-	fmt.Printf("Usuario '%s' creado exitosamente con UID %d en el grupo '%s'\n", user, newUID, group)
-}
\ No newline at end of file
+	fmt.Printf("Usuario '%s' creado exitosamente con UID %d en el grupo '%s'\n", user, newUser.UID, group)
+}
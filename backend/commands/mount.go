@@ -1,8 +1,10 @@
 package commands
 
 import (
+	"encoding/binary"
 	"fmt"
 	"os"
+	"proyecto1/fs"
 	"proyecto1/state"   // Importamos el paquete de estado para acceder a la lista global.
 	"proyecto1/structs" // Importamos las estructuras de MBR, EBR, etc.
 	"proyecto1/utils"   // Importamos las herramientas para leer/escribir en el disco.
@@ -88,6 +90,7 @@ func ExecuteMount(path, name string) {
 				return
 			}
 			fmt.Printf("Partición primaria '%s' montada exitosamente con el ID: %s\n", name, id)
+			warnOldLayout(file, p.Part_start)
 			return
 		}
 	}
@@ -135,6 +138,7 @@ func ExecuteMount(path, name string) {
 					return
 				}
 				fmt.Printf("Partición lógica '%s' montada exitosamente con el ID: %s\n", name, id)
+				warnOldLayout(file, currentEBR.Part_start)
 				return
 			}
 			if currentEBR.Part_next == -1 {
@@ -153,6 +157,19 @@ func ExecuteMount(path, name string) {
 	fmt.Printf("Error: no se encontró la partición con el nombre '%s'.\n", name)
 }
 
+// warnOldLayout avisa si la partición tiene un sistema de archivos con el formato anterior.
+// Queda montada igual para poder volver a formatearla con mkfs; los demás comandos la rechazan.
+func warnOldLayout(file *os.File, start int64) {
+	var sb structs.Superblock
+	file.Seek(start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		return
+	}
+	if err := fs.CheckLayout(sb); err != nil {
+		fmt.Println("Advertencia:", err)
+	}
+}
+
 // ExecuteMounted muestra todas las particiones montadas.
 func ExecuteMounted() {
 	// Revisa si la lista global está vacía.
@@ -50,13 +50,13 @@ func ExecuteMove(srcPath string, destPath string) {
 	uid, gid, _ := getUserIDs(file, sb, state.CurrentSession.User)
 
 	// --- Buscar origen ---
-	srcInode, srcIndex, err := fs.FindInodeByPath(file, sb, srcPath)
+	srcInode, srcIndex, err := fs.FindInodeByPathNoFollow(file, sb, srcPath)
 	if err != nil {
 		fmt.Println("Error: no se encontró la ruta origen:", srcPath)
 		return
 	}
 
-	if !tienePermisoEscritura(srcInode, uid, gid) {
+	if !tienePermisoEscritura(file, sb, srcInode, uid, gid) {
 		fmt.Println("Error: no tienes permiso de escritura sobre el origen.")
 		return
 	}
@@ -68,6 +68,10 @@ func ExecuteMove(srcPath string, destPath string) {
 		fmt.Println("Error: no se encontró la carpeta padre del origen:", srcParentPath)
 		return
 	}
+	if fs.StickyDenies(srcParentInode, srcInode, credencial(uid, gid)) {
+		fmt.Println("Error: la carpeta de origen tiene sticky bit; solo el propietario puede mover", srcPath)
+		return
+	}
 
 	// --- Determinar destino ---
 	var destParentInode structs.Inode
@@ -115,7 +119,7 @@ func ExecuteMove(srcPath string, destPath string) {
 	}
 
 	// --- Verificar permisos escritura en destino ---
-	if !tienePermisoEscritura(destParentInode, uid, gid) {
+	if !tienePermisoEscritura(file, sb, destParentInode, uid, gid) {
 		fmt.Println("Error: no tienes permiso de escritura en la carpeta destino.")
 		return
 	}
@@ -176,10 +180,7 @@ func addEntryToParent(file *os.File, sb structs.Superblock, parentIndex int32, n
 		newBlockIndex, _ := fs.FindFreeBlock(file, sb)
 		fs.MarkBlockAsUsed(file, sb, newBlockIndex)
 
-		var fb structs.FolderBlock
-		for i := range fb.B_content {
-			fb.B_content[i].B_inodo = -1
-		}
+		fb := fs.NewFolderBlock(sb)
 		copy(fb.B_content[0].B_name[:], []byte(name))
 		fb.B_content[0].B_inodo = inodeIndex
 		fs.WriteFolderBlock(file, sb, newBlockIndex, fb)
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"proyecto1/state"
+	"proyecto1/structs"
+	"proyecto1/users"
+)
+
+// ExecutePasswd cambia la contraseña de un usuario en /users.txt.
+// root puede cambiar la de cualquier usuario; los demás solo la propia y
+// deben indicar la contraseña actual en oldPass.
+func ExecutePasswd(user, pass, oldPass string) {
+	if !state.CurrentSession.IsActive {
+		fmt.Println("Error: Debes iniciar sesión para usar passwd.")
+		return
+	}
+
+	isRoot := state.CurrentSession.User == "root"
+	if user == "" {
+		user = state.CurrentSession.User
+	}
+	if !isRoot && user != state.CurrentSession.User {
+		fmt.Println("Error: Solo el usuario root puede cambiar la contraseña de otros usuarios.")
+		return
+	}
+	if !isRoot && oldPass == "" {
+		fmt.Println("Error: Debes indicar la contraseña actual con -old.")
+		return
+	}
+	if pass == "" {
+		fmt.Println("Error: La nueva contraseña no puede estar vacía.")
+		return
+	}
+
+	// Obtener partición activa
+	var mountedPartition *state.MountedPartition
+	for _, p := range state.GlobalMountedPartitions {
+		if p.ID == state.CurrentSession.PartitionID {
+			mountedPartition = &p
+			break
+		}
+	}
+	if mountedPartition == nil {
+		fmt.Println("Error: No se encontró la partición activa.")
+		return
+	}
+
+	file, err := os.OpenFile(mountedPartition.Path, os.O_RDWR, 0644)
+	if err != nil {
+		fmt.Println("Error al abrir el disco:", err)
+		return
+	}
+	defer file.Close()
+
+	// Leer superbloque
+	var sb structs.Superblock
+	file.Seek(mountedPartition.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		fmt.Println("Error al leer el superbloque:", err)
+		return
+	}
+
+	// Leer users.txt
+	table, err := users.Load(file, sb)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	account := table.User(user)
+	if account == nil || account.Deleted() {
+		fmt.Printf("Error: El usuario '%s' no existe.\n", user)
+		return
+	}
+	if !isRoot && !checkPassword(account.Password, oldPass) {
+		fmt.Println("Error: La contraseña actual es incorrecta.")
+		return
+	}
+
+	hash, err := hashPassword(pass)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	account.Password = hash
+
+	if err := users.Save(file, sb, table); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	fmt.Printf("Contraseña del usuario '%s' actualizada correctamente.\n", user)
+	addJournalEntry(file, sb, mountedPartition.Start, "PASSWD", "/users.txt", user)
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"proyecto1/structs"
+	"proyecto1/users"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Las contraseñas de /users.txt se guardan como hash bcrypt, que ya incluye su sal.
+// Un hash bcrypt empieza con "$2a$", "$2b$" o "$2y$" y nunca contiene comas,
+// así que cabe en el quinto campo de la línea sin cambiar el formato.
+
+// hashPassword genera el hash bcrypt de una contraseña en texto plano.
+func hashPassword(plain string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("no se pudo cifrar la contraseña: %w", err)
+	}
+	return string(hash), nil
+}
+
+// isPasswordHash indica si el campo de contraseña ya es un hash bcrypt.
+func isPasswordHash(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") || strings.HasPrefix(stored, "$2y$")
+}
+
+// checkPassword compara una contraseña contra lo guardado en users.txt.
+// Acepta texto plano para las particiones formateadas antes del cambio; esas
+// entradas se convierten a hash con migrateUsersPasswords al iniciar sesión.
+func checkPassword(stored, plain string) bool {
+	if isPasswordHash(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(plain)) == nil
+	}
+	return stored == plain
+}
+
+// migrateUsersPasswords reescribe /users.txt cambiando las contraseñas que sigan en
+// texto plano por su hash. Devuelve cuántas entradas se convirtieron.
+func migrateUsersPasswords(file *os.File, sb structs.Superblock) (int, error) {
+	table, err := users.Load(file, sb)
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, u := range table.Users() {
+		if isPasswordHash(u.Password) {
+			continue
+		}
+		hash, err := hashPassword(u.Password)
+		if err != nil {
+			return migrated, err
+		}
+		u.Password = hash
+		migrated++
+	}
+	if migrated == 0 {
+		return 0, nil
+	}
+
+	if err := users.Save(file, sb, table); err != nil {
+		return 0, err
+	}
+	return migrated, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"os"
+	"proyecto1/fs"
 	"proyecto1/state"
 	"proyecto1/structs"
 	"strings"
@@ -39,6 +40,10 @@ func RecoveryFileSystem(id string) {
 		fmt.Println("Error al leer el superbloque:", err)
 		return
 	}
+	if err := fs.CheckLayout(superbloque); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 
 	if superbloque.S_filesystem_type != 3 {
 		fmt.Println("Error: La partición no utiliza el sistema de archivos EXT3 (3fs).")
@@ -47,7 +52,7 @@ func RecoveryFileSystem(id string) {
 
 	// --- LECTURA DEL JOURNALING ---
 	journalingStart := partitionStart + int64(binary.Size(superbloque))
-	journalingSize := int64(50) * int64(superbloque.S_inodes_count)
+	journalingSize := int64(binary.Size(structs.JournalEntry{})) * int64(superbloque.S_inodes_count)
 	file.Seek(journalingStart, 0)
 
 	fmt.Println("Procesando entradas del journaling...")
@@ -127,6 +132,10 @@ func ExecuteRecovery(id string) {
 		fmt.Println("Error al leer superbloque:", err)
 		return
 	}
+	if err := fs.CheckLayout(sb); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 
 	totalInodes := int(sb.S_inodes_count)
 	totalBlocks := int(sb.S_blocks_count)
@@ -159,7 +168,7 @@ func ExecuteRecovery(id string) {
 		}
 
 		// Determinar si el inodo está en uso: tipo válido o bloques asignados o tamaño > 0
-		if ino.I_type == 0 || ino.I_type == 1 || ino.I_size > 0 {
+		if ino.I_type == 0 || ino.I_type == 1 || ino.I_type == 2 || ino.I_size > 0 {
 			// marcar inodo usado
 			bmInode[i] = 1
 			usedInodes++
@@ -51,14 +51,14 @@ func ExecuteRemove(filePath string) {
 	uid, gid, _ := getUserIDs(file, sb, state.CurrentSession.User)
 
 	// --- Buscar el inodo del archivo/carpeta ---
-	inode, _, err := fs.FindInodeByPath(file, sb, filePath)
+	inode, inodeIndex, err := fs.FindInodeByPathNoFollow(file, sb, filePath)
 	if err != nil {
 		fmt.Println("Error: el archivo o carpeta no existe:", filePath)
 		return
 	}
 
 	// --- Validar permisos ---
-	if !tienePermisoEscritura(inode, uid, gid) {
+	if !tienePermisoEscritura(file, sb, inode, uid, gid) {
 		fmt.Println("Error: no tienes permisos para eliminar este archivo o carpeta.")
 		return
 	}
@@ -70,14 +70,23 @@ func ExecuteRemove(filePath string) {
 		fmt.Println("Error: no se encontró la carpeta padre:", parentPath)
 		return
 	}
+	if fs.StickyDenies(parentInode, inode, credencial(uid, gid)) {
+		fmt.Println("Error: la carpeta padre tiene sticky bit; solo el propietario puede eliminar", filePath)
+		return
+	}
 
 	// --- Eliminar archivo o carpeta ---
 	if inode.I_type == 0 {
-		// Carpeta → eliminación recursiva
-		removeFolderRecursively(file, sb, inode.I_uid, mountedPartition.Start)
+		// Carpeta → eliminación recursiva; el contenido también respeta los permisos y
+		// el sticky bit de las subcarpetas
+		if !canDeleteFolderRecursively(file, sb, inode, uid, gid) {
+			fmt.Println("Error: no tienes permisos para eliminar todo el contenido de", filePath)
+			return
+		}
+		removeFolderRecursively(file, sb, inodeIndex, mountedPartition.Start)
 	} else {
 		// Archivo
-		removeFile(file, sb, inode.I_uid, mountedPartition.Start)
+		removeFile(file, sb, inodeIndex, mountedPartition.Start)
 	}
 
 	// --- Eliminar entrada del padre ---
@@ -92,18 +101,27 @@ func ExecuteRemove(filePath string) {
 	fmt.Println("Eliminación completada exitosamente:", filePath)
 }
 
-// removeFile elimina los bloques y el inodo de un archivo.
+// removeFile quita un enlace de un archivo. Los bloques (incluidos los de apuntadores) y el
+// inodo solo se liberan cuando se elimina el último enlace.
 func removeFile(file *os.File, sb structs.Superblock, inodeIndex int32, sbStart int64) {
 	inode, _ := fs.ReadInode(file, sb, inodeIndex)
-	for _, blockNum := range inode.I_block {
-		if blockNum != -1 {
-			fs.MarkBlockAsFree(file, sb, blockNum, sbStart)
-		}
+	if inode.I_links > 1 {
+		inode.I_links--
+		fs.WriteInode(file, sb, inodeIndex, inode)
+		return
+	}
+	dataBlocks, pointerBlocks, _ := fs.InodeBlocks(file, sb, inode)
+	for _, blockNum := range append(dataBlocks, pointerBlocks...) {
+		fs.MarkBlockAsFree(file, sb, blockNum, sbStart)
+	}
+	if fs.HasACL(inode) {
+		fs.MarkBlockAsFree(file, sb, inode.I_acl, sbStart)
 	}
 	fs.MarkInodeAsFree(file, sb, inodeIndex, sbStart)
 }
 
-// canDeleteFolderRecursively verifica que el usuario tenga permiso de escritura en todos los elementos.
+// canDeleteFolderRecursively verifica que el usuario tenga permiso de escritura en todos los
+// elementos y que ninguna carpeta con sticky bit le impida borrar entradas ajenas.
 func canDeleteFolderRecursively(file *os.File, sb structs.Superblock, inode structs.Inode, uid, gid int32) bool {
 	for _, blockNum := range inode.I_block {
 		if blockNum == -1 {
@@ -119,7 +137,10 @@ func canDeleteFolderRecursively(file *os.File, sb structs.Superblock, inode stru
 			if name == "." || name == ".." {
 				continue
 			}
-			if !tienePermisoEscritura(childInode, uid, gid) {
+			if !tienePermisoEscritura(file, sb, childInode, uid, gid) {
+				return false
+			}
+			if fs.StickyDenies(inode, childInode, credencial(uid, gid)) {
 				return false
 			}
 			if childInode.I_type == 0 {
@@ -158,6 +179,9 @@ func removeFolderRecursively(file *os.File, sb structs.Superblock, inodeIndex in
 		}
 		fs.MarkBlockAsFree(file, sb, blockNum, sbStart)
 	}
+	if fs.HasACL(inode) {
+		fs.MarkBlockAsFree(file, sb, inode.I_acl, sbStart)
+	}
 	fs.MarkInodeAsFree(file, sb, inodeIndex, sbStart)
 }
 
@@ -90,10 +90,15 @@ func ExecuteRename(path string, newName string) {
 	}
 
 	parentInode, _ := fs.ReadInode(file, sb, currentInodeIndex)
-	if !tienePermisoEscritura(parentInode, uid, gid) {
+	if !tienePermisoEscritura(file, sb, parentInode, uid, gid) {
 		fmt.Println("Error: no tienes permiso de escritura en la carpeta padre.")
 		return
 	}
+	if targetInode, _, err := fs.FindInodeByPathNoFollow(file, sb, path); err == nil &&
+		fs.StickyDenies(parentInode, targetInode, credencial(uid, gid)) {
+		fmt.Println("Error: la carpeta padre tiene sticky bit; solo el propietario puede renombrar", path)
+		return
+	}
 
 	// --- 5. Verificar existencia del nuevo nombre ---
 	for _, blockNum := range parentInode.I_block {
@@ -1,42 +1,179 @@
 package commands
 
-import "strings"
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"proyecto1/fs"
+	"proyecto1/report"
+	"proyecto1/state"
+	"proyecto1/structs"
+	"strings"
+	"time"
+)
 
+// repBuilder arma el modelo de un reporte; defaultFormat es el formato que se usa
+// cuando ni -format ni la extensión de -path indican uno.
+type repBuilder struct {
+	build         func(id string, opts ReportOptions) (*report.Report, error)
+	defaultFormat string
+}
 
-func ExecuteRep(name string, path string, id string, path_file_ls string) {	
+var repBuilders = map[string]repBuilder{
+	"mbr":        {MBR, "png"},
+	"disk":       {DISK, "png"},
+	"sb":         {SB, "png"},
+	"inode":      {INODE, "png"},
+	"block":      {BLOCK, "png"},
+	"tree":       {TREE, "png"},
+	"bm_inode":   {BM_INODE, "txt"},
+	"bm_block":   {BM_BLOCK, "txt"},
+	"file":       {FILE, "txt"},
+	"ls":         {LS, "png"},
+	"journaling": {JOURNALING, "html"},
+	"du":         {DU, "txt"},
+	"heatmap":    {HEATMAP, "png"},
+}
+
+// ReportOptions son los parámetros de rep que solo usan algunos reportes.
+type ReportOptions struct {
+	PathFileLS string        // ruta dentro de la partición para file, ls y du
+	Depth      int           // du: niveles de subcarpetas a listar; <0 sin límite
+	Recursive  bool          // ls: lista también las subcarpetas
+	Sort       string        // ls: name, size, time (mtime), atime o ctime
+	Journal    JournalFilter // filtros de journaling
+}
+
+// ReportNames son los reportes que entiende rep, en el orden en que se documentan.
+var ReportNames = []string{"mbr", "disk", "sb", "inode", "block", "tree", "bm_inode", "bm_block", "file", "ls", "journaling", "du", "heatmap"}
+
+// Errores de BuildReport que la API HTTP distingue para elegir el código de respuesta.
+var (
+	ErrUnknownReport = errors.New("reporte no reconocido")
+	ErrNotMounted    = errors.New("partición no montada")
+	ErrNoSession     = errors.New("debes iniciar sesión para usar este reporte")
+)
+
+// BuildReport arma en memoria el reporte 'name' de la partición 'id'. Lo usan rep y la
+// API HTTP.
+func BuildReport(name, id string, opts ReportOptions) (*report.Report, error) {
+	name = canonicalReportName(name)
+	builder, ok := repBuilders[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s' (%s)", ErrUnknownReport, name, strings.Join(ReportNames, ", "))
+	}
+	rep, err := builder.build(id, opts)
+	if err != nil {
+		return nil, err
+	}
+	rep.Name = name
+	return rep, nil
+}
+
+func canonicalReportName(name string) string {
 	name = strings.ToLower(name)
-	
-	switch name {
-		case "mbr":
-			MBR(id, path)
-
-		case "disk":
-			DISK(id, path)
-			
-		case "inode":
-			INODE(id, path)
-
-		case "block":
-			BLOCK(id, path)
-
-		case "bm_inode":
-			BM_INODE(id, path)
-
-		case "bm_block":
-			BM_BLOCK(id, path)
-
-		case "tree":
-			TREE(id, path)
-
-		case "sb":
-			SB(id, path)
-
-		case "file":
-			FILE(id, path_file_ls, path)
-			
-		case "ls":
-			LS(id, path, path_file_ls)
-		default:			
+	if name == "bm_bloc" {
+		return "bm_block"
+	}
+	return name
+}
+
+// ExecuteRep genera el reporte 'name' de la partición 'id' en 'path'. El formato sale
+// de -format o, si no se indicó, de la extensión de path; si path no tiene extensión se
+// le agrega la del formato. Una extensión desconocida sin -format es un error.
+func ExecuteRep(name string, path string, id string, opts ReportOptions, format string) {
+	name = canonicalReportName(name)
+	builder, ok := repBuilders[name]
+	if !ok {
+		fmt.Printf("Error: reporte '%s' no reconocido (%s).\n", name, strings.Join(ReportNames, ", "))
+		return
+	}
+
+	if format == "" {
+		format = report.FormatFromPath(path)
+		if ext := filepath.Ext(path); format == "" && ext != "" {
+			fmt.Printf("Error: extensión '%s' no soportada (disponibles: %s); usa otra extensión o -format.\n", ext, strings.Join(report.Formats(), ", "))
 			return
+		}
+	}
+	if format == "" {
+		format = builder.defaultFormat
+	}
+	format = report.Normalize(format)
+	if filepath.Ext(path) == "" {
+		path += "." + format
+	}
+
+	rep, err := BuildReport(name, id, opts)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
 	}
-}
\ No newline at end of file
+	if err := report.Write(path, format, rep); err != nil {
+		fmt.Println("Error al generar el reporte:", err)
+		return
+	}
+	fmt.Printf("Reporte %s generado en: %s\n", name, path)
+}
+
+// openReportPartition abre en solo lectura el disco de la partición montada 'id'.
+func openReportPartition(id string) (*os.File, state.MountedPartition, error) {
+	mp, found := state.GetMountedPartitionByID(id)
+	if !found {
+		return nil, mp, fmt.Errorf("%w: no se encontró la partición montada con id '%s'", ErrNotMounted, id)
+	}
+	file, err := os.Open(mp.Path)
+	if err != nil {
+		return nil, mp, fmt.Errorf("error al abrir el disco: %w", err)
+	}
+	return file, mp, nil
+}
+
+// readReportSuperblock abre la partición 'id' y lee su superbloque.
+func readReportSuperblock(id string) (*os.File, state.MountedPartition, structs.Superblock, error) {
+	var sb structs.Superblock
+	file, mp, err := openReportPartition(id)
+	if err != nil {
+		return nil, mp, sb, err
+	}
+	file.Seek(mp.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		file.Close()
+		return nil, mp, sb, fmt.Errorf("error al leer el superbloque: %w", err)
+	}
+	if err := fs.CheckLayout(sb); err != nil {
+		file.Close()
+		return nil, mp, sb, err
+	}
+	return file, mp, sb, nil
+}
+
+// readBitmap lee 'count' bytes de bitmap desde 'start'.
+func readBitmap(file *os.File, start, count int32) ([]byte, error) {
+	bm := make([]byte, count)
+	file.Seek(int64(start), 0)
+	if _, err := file.Read(bm); err != nil {
+		return nil, fmt.Errorf("error al leer el bitmap: %w", err)
+	}
+	return bm, nil
+}
+
+// requireReportSession valida que haya sesión activa (reportes file y ls).
+func requireReportSession() error {
+	if !state.CurrentSession.IsActive {
+		return ErrNoSession
+	}
+	return nil
+}
+
+// reportName quita los \x00 de relleno de un nombre de tamaño fijo.
+func reportName(b []byte) string {
+	return strings.TrimRight(string(b), "\x00")
+}
+
+// reportTime da formato a una marca de tiempo Unix.
+func reportTime(t int64) string {
+	return time.Unix(t, 0).Format("2006-01-02 15:04")
+}
@@ -1,14 +1,12 @@
 package commands
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
-	"io"
 	"os"
 	"proyecto1/state"
 	"proyecto1/structs"
-	"strings"
+	"proyecto1/users"
 )
 
 func ExecuteRmgrp(groupName string) {
@@ -50,148 +48,23 @@ func ExecuteRmgrp(groupName string) {
 		return
 	}
 
-	// Leer inodo raíz
-	var currentInode structs.Inode
-	file.Seek(int64(sb.S_inode_start), 0)
-	if err := binary.Read(file, binary.BigEndian, &currentInode); err != nil {
-		fmt.Println("Error al leer el inodo raíz:", err)
+	// Leer users.txt
+	table, err := users.Load(file, sb)
+	if err != nil {
+		fmt.Println("Error:", err)
 		return
 	}
 
-	// Buscar el inodo del archivo /users.txt
-	path := "/users.txt"
-	parts := strings.Split(path, "/")
-	for i := 1; i < len(parts); i++ {
-		name := parts[i]
-		if name == "" {
-			continue
-		}
-
-		found := false
-		for _, blockNum := range currentInode.I_block {
-			if blockNum == -1 {
-				continue
-			}
-			blockPos := int64(sb.S_block_start) + int64(blockNum)*int64(sb.S_block_size)
-			var folderBlock structs.FolderBlock
-			file.Seek(blockPos, 0)
-			if err := binary.Read(file, binary.BigEndian, &folderBlock); err != nil {
-				fmt.Println("Error al leer bloque de carpeta:", err)
-				return
-			}
-
-			for _, entry := range folderBlock.B_content {
-				entryName := string(bytes.Trim(entry.B_name[:], "\x00"))
-				if entryName == name {
-					file.Seek(int64(sb.S_inode_start)+int64(entry.B_inodo)*int64(sb.S_inode_size), 0)
-					if err := binary.Read(file, binary.BigEndian, &currentInode); err != nil {
-						fmt.Println("Error al leer inodo:", err)
-						return
-					}
-					found = true
-					break
-				}
-			}
-			if found {
-				break
-			}
-		}
-
-		if !found {
-			fmt.Printf("Error: No se encontró '%s' en la ruta.\n", name)
-			return
-		}
-	}
-
-	// Leer contenido actual
-	var content strings.Builder
-	for _, blockNum := range currentInode.I_block {
-		if blockNum == -1 {
-			continue
-		}
-		blockPos := int64(sb.S_block_start) + int64(blockNum)*int64(sb.S_block_size)
-		blockData := make([]byte, sb.S_block_size)
-		file.Seek(blockPos, 0)
-		if _, err := io.ReadFull(file, blockData); err != nil {
-			fmt.Println("Error al leer bloque del archivo:", err)
-			return
-		}
-		content.Write(bytes.Trim(blockData, "\x00"))
-	}
-
-	// Procesar líneas, verificando si ya estaba eliminado
-	lines := strings.Split(content.String(), "\n")
-	removed := false
-	alreadyRemoved := false
-
-	for i, line := range lines {
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-		parts := strings.Split(line, ",")
-		if len(parts) >= 3 && parts[1] == "G" {
-			if parts[2] == groupName {
-				if parts[0] == "0" {
-					alreadyRemoved = true
-					break
-				}
-				// Marcar como eliminado (ID -> 0)
-				lines[i] = fmt.Sprintf("0,G,%s", parts[2])
-				removed = true
-				break
-			}
-		}
-	}
-
-	if alreadyRemoved {
-		fmt.Printf("Aviso: El grupo '%s' ya estaba eliminado.\n", groupName)
+	// Marcar como eliminado (ID -> 0)
+	if err := table.RemoveGroup(groupName); err != nil {
+		fmt.Println("Error:", err)
 		return
 	}
 
-	if !removed {
-		fmt.Printf("Error: No se encontró el grupo '%s'.\n", groupName)
+	if err := users.Save(file, sb, table); err != nil {
+		fmt.Println("Error:", err)
 		return
 	}
 
-	newContent := strings.Join(lines, "\n")
-	if !strings.HasSuffix(newContent, "\n") {
-		newContent += "\n"
-	}
-
-	// Escribir de nuevo en bloques
-	data := []byte(newContent)
-	offset := 0
-	for _, blockNum := range currentInode.I_block {
-		if blockNum == -1 {
-			continue
-		}
-		blockPos := int64(sb.S_block_start) + int64(blockNum)*int64(sb.S_block_size)
-		blockSize := int(sb.S_block_size)
-
-		end := offset + blockSize
-		if end > len(data) {
-			end = len(data)
-		}
-		chunk := data[offset:end]
-
-		// Rellenar con ceros
-		if len(chunk) < blockSize {
-			padded := make([]byte, blockSize)
-			copy(padded, chunk)
-			chunk = padded
-		}
-
-		file.Seek(blockPos, 0)
-		if _, err := file.Write(chunk); err != nil {
-			fmt.Println("Error al escribir bloque:", err)
-			return
-		}
-
-		offset += blockSize
-		if offset >= len(data) {
-			break
-		}
-	}
-
 	fmt.Printf("Grupo '%s' marcado como eliminado en /users.txt\n", groupName)
 }
@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+
+	"proyecto1/fs"
+	"proyecto1/state"
+	"proyecto1/structs"
+	"proyecto1/users"
+)
+
+// ======================== ExecuteSetfacl ========================
+//
+// Modifica la ACL extendida de un archivo o carpeta. Las entradas van separadas por comas:
+//   - modify: u:usuario:rwx, g:grupo:r-x o m::rw (máscara); agrega o reemplaza.
+//   - remove: u:usuario, g:grupo o m; quita la entrada.
+//   - clear: borra toda la ACL y libera su bloque.
+//
+// Solo el propietario del inodo o root pueden ejecutarlo.
+func ExecuteSetfacl(path, modify, remove string, clear bool) {
+	if !state.CurrentSession.IsActive {
+		fmt.Println("Error: Debes iniciar sesión para usar setfacl.")
+		return
+	}
+
+	var mountedPartition *state.MountedPartition
+	for _, mp := range state.GlobalMountedPartitions {
+		if mp.ID == state.CurrentSession.PartitionID {
+			mountedPartition = &mp
+			break
+		}
+	}
+	if mountedPartition == nil {
+		fmt.Println("Error: No se encontró la partición activa.")
+		return
+	}
+
+	file, err := os.OpenFile(mountedPartition.Path, os.O_RDWR, 0644)
+	if err != nil {
+		fmt.Println("Error al abrir el disco:", err)
+		return
+	}
+	defer file.Close()
+
+	var sb structs.Superblock
+	file.Seek(mountedPartition.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		fmt.Println("Error al leer el superbloque:", err)
+		return
+	}
+
+	inode, inodeIndex, err := fs.FindInodeByPath(file, sb, path)
+	if err != nil {
+		fmt.Println("Error al buscar la ruta:", err)
+		return
+	}
+	if !esPropietario(inode, fs.SessionCred()) {
+		fmt.Println("Error: Solo el propietario o root pueden cambiar la ACL de", path)
+		return
+	}
+
+	table, err := users.Load(file, sb)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	var entries []fs.ACLEntry
+	if !clear {
+		if entries, err = fs.ReadACL(file, sb, inode); err != nil {
+			fmt.Println("Error al leer la ACL:", err)
+			return
+		}
+	}
+	if modify != "" {
+		for _, spec := range strings.Split(modify, ",") {
+			entry, err := parseACLSpec(table, spec, true)
+			if err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			entries = setACLEntry(entries, entry)
+		}
+	}
+	if remove != "" {
+		for _, spec := range strings.Split(remove, ",") {
+			entry, err := parseACLSpec(table, spec, false)
+			if err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			entries = removeACLEntry(entries, entry)
+		}
+	}
+
+	if err := fs.WriteACL(file, sb, &inode, entries); err != nil {
+		fmt.Println("Error al guardar la ACL:", err)
+		return
+	}
+	fs.WriteInode(file, sb, inodeIndex, inode)
+
+	if len(entries) == 0 {
+		fmt.Println("ACL eliminada de", path)
+		return
+	}
+	fmt.Printf("ACL de %s actualizada (%d entrada(s))\n", path, len(entries))
+}
+
+// parseACLSpec interpreta una entrada "u:nombre:rwx", "g:nombre:rwx" o "m::rwx". Con
+// withPerm en false (para quitar) se espera "u:nombre", "g:nombre" o "m".
+func parseACLSpec(table *users.Table, spec string, withPerm bool) (fs.ACLEntry, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	var entry fs.ACLEntry
+
+	switch parts[0] {
+	case "u", "user":
+		entry.Tag = fs.ACLUser
+	case "g", "group":
+		entry.Tag = fs.ACLGroup
+	case "m", "mask":
+		entry.Tag = fs.ACLMask
+	default:
+		return entry, fmt.Errorf("entrada de ACL inválida '%s': se espera u:, g: o m:", spec)
+	}
+
+	name := ""
+	if len(parts) > 1 {
+		name = parts[1]
+	}
+	want := 2 // tipo y nombre
+	if withPerm {
+		want = 3
+	}
+	if entry.Tag == fs.ACLMask {
+		if len(parts) > want || name != "" {
+			return entry, fmt.Errorf("entrada de ACL inválida '%s': la máscara no lleva nombre", spec)
+		}
+	} else if len(parts) != want || name == "" {
+		return entry, fmt.Errorf("entrada de ACL inválida '%s'", spec)
+	}
+
+	switch entry.Tag {
+	case fs.ACLUser:
+		u := table.User(name)
+		if u == nil || u.Deleted() {
+			return entry, fmt.Errorf("el usuario '%s' no existe", name)
+		}
+		entry.ID = u.UID
+	case fs.ACLGroup:
+		g := table.Group(name)
+		if g == nil || g.Deleted() {
+			return entry, fmt.Errorf("el grupo '%s' no existe", name)
+		}
+		entry.ID = g.GID
+	}
+
+	if withPerm {
+		if len(parts) < 3 {
+			return entry, fmt.Errorf("entrada de ACL inválida '%s': faltan los permisos", spec)
+		}
+		perm, err := fs.ParseACLPerm(parts[len(parts)-1])
+		if err != nil {
+			return entry, err
+		}
+		entry.Perm = perm
+	}
+	return entry, nil
+}
+
+// setACLEntry reemplaza la entrada con el mismo tipo e ID, o la agrega al final.
+func setACLEntry(entries []fs.ACLEntry, entry fs.ACLEntry) []fs.ACLEntry {
+	for i, e := range entries {
+		if e.Tag == entry.Tag && e.ID == entry.ID {
+			entries[i] = entry
+			return entries
+		}
+	}
+	return append(entries, entry)
+}
+
+// removeACLEntry quita la entrada con el mismo tipo e ID, si existe.
+func removeACLEntry(entries []fs.ACLEntry, entry fs.ACLEntry) []fs.ACLEntry {
+	for i, e := range entries {
+		if e.Tag == entry.Tag && e.ID == entry.ID {
+			return append(entries[:i], entries[i+1:]...)
+		}
+	}
+	return entries
+}
@@ -1,13 +1,10 @@
 package commands
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
-	"io"
 	"os"
 	"strconv"
-	"strings"
 
 	"proyecto1/fs"
 	"proyecto1/structs"
@@ -47,9 +44,14 @@ func ExecuteShowFile(diskPath string, startStr string, path string) {
 		fmt.Println("Error al leer superbloque:", err)
 		return
 	}
+	if err := fs.CheckLayout(sb); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 
-	// Buscar el inodo del archivo
-	inode, _, err := fs.FindInodeByPath(f, sb, path)
+	// Buscar el inodo del archivo; showfile lee el disco directamente (FileBrowser),
+	// sin depender de la sesión para recorrer las carpetas
+	inode, _, err := fs.FindInodeByPathNoCheck(f, sb, path)
 	if err != nil {
 		fmt.Println("Error al resolver ruta:", err)
 		return
@@ -59,31 +61,20 @@ func ExecuteShowFile(diskPath string, startStr string, path string) {
 		fmt.Println("Error: la ruta no es un archivo")
 		return
 	}
+	// El archivo sí tiene que ser legible para la sesión (sin sesión, solo los permisos
+	// de otros): así /.audit, de root y 600, no se puede leer desde el explorador
+	if !fs.CanRead(f, sb, inode, fs.SessionCred()) {
+		fmt.Println("Error: permiso denegado para leer el archivo")
+		return
+	}
 
 	// Leer los bloques de datos asociados al archivo
-	var contentBuilder strings.Builder
-
-	for _, blockNum := range inode.I_block {
-		if blockNum == -1 {
-			continue
-		}
-
-		blockPos := int64(sb.S_block_start) + int64(blockNum)*int64(sb.S_block_size)
-		blockData := make([]byte, sb.S_block_size)
-
-		if _, err := f.Seek(blockPos, 0); err != nil {
-			fmt.Println("Error al posicionar en bloque:", err)
-			return
-		}
-
-		if _, err := io.ReadFull(f, blockData); err != nil && err != io.EOF {
-			fmt.Println("Error al leer bloque:", err)
-			return
-		}
-
-		contentBuilder.Write(bytes.Trim(blockData, "\x00"))
+	content, err := fs.ReadFileContent(f, sb, inode)
+	if err != nil {
+		fmt.Println("Error al leer el archivo:", err)
+		return
 	}
 
 	// Mostrar el contenido completo del archivo
-	fmt.Print(contentBuilder.String())
+	fmt.Print(string(content))
 }
@@ -1,185 +1,120 @@
 package commands
 
 import (
-	"encoding/binary"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"proyecto1/fs"
-	"proyecto1/state"
-	"proyecto1/structs"
-	"strings"
+	"proyecto1/report"
 )
 
-// escapeDotLabel escapa caracteres especiales para Graphviz
-func escapeDotLabel(s string) string {
-	s = strings.ReplaceAll(s, "\n", " ")   // quitar saltos de línea
-	s = strings.ReplaceAll(s, "→", "->")   // normalizar flecha
-	s = strings.ReplaceAll(s, "\\", "\\\\") // escapar backslash
-	s = strings.ReplaceAll(s, "\"", "\\\"") // escapar comillas dobles
-	return s
-}
-
-func TREE(id, path string) {
-	// 1. Buscar partición montada
-	mountedPartition, found := state.GetMountedPartitionByID(id)
-	if !found {
-		fmt.Printf("Error: No se encontró la partición montada con id '%s'\n", id)
-		return
-	}
-
-	file, err := os.Open(mountedPartition.Path)
+// TREE arma el grafo del sistema de archivos: inodos, sus bloques y las entradas de
+// carpeta que llevan a otros inodos. La bitácora /.audit y sus bloques no aparecen.
+func TREE(id string, _ ReportOptions) (*report.Report, error) {
+	file, mp, sb, err := readReportSuperblock(id)
 	if err != nil {
-		fmt.Println("Error al abrir el disco:", err)
-		return
+		return nil, err
 	}
 	defer file.Close()
 
-	// 2. Leer superbloque
-	var sb structs.Superblock
-	file.Seek(mountedPartition.Start, 0)
-	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
-		fmt.Println("Error al leer el superbloque:", err)
-		return
-	}
-
-	// 3. Leer bitmaps
-	bmInodes := make([]byte, sb.S_inodes_count)
-	file.Seek(int64(sb.S_bm_inode_start), 0)
-	binary.Read(file, binary.BigEndian, &bmInodes)
-
-	bmBlocks := make([]byte, sb.S_blocks_count)
-	file.Seek(int64(sb.S_bm_block_start), 0)
-	binary.Read(file, binary.BigEndian, &bmBlocks)
-
-	// 4. Preparar salida
-	outDir := filepath.Dir(path)
-	if _, err := os.Stat(outDir); os.IsNotExist(err) {
-		os.MkdirAll(outDir, 0755)
+	bmInodes, err := readBitmap(file, sb.S_bm_inode_start, sb.S_inodes_count)
+	if err != nil {
+		return nil, err
 	}
-
-	dotFile := path + ".dot"
-	f, err := os.Create(dotFile)
+	bmBlocks, err := readBitmap(file, sb.S_bm_block_start, sb.S_blocks_count)
 	if err != nil {
-		fmt.Println("Error al crear el archivo DOT:", err)
-		return
+		return nil, err
+	}
+	kinds := fs.ClassifyBlocks(file, sb, bmInodes)
+	auditInode, auditBlocks := auditNodes(file, sb)
+
+	graph := &report.Graph{
+		Direction: "TB",
+		Clusters: []report.Cluster{
+			{ID: "inodos", Label: "Inodos", Color: "#0000ff"},
+			{ID: "bloques", Label: "Bloques", Color: "#ff0000"},
+		},
+	}
+	edge := func(from, to, label string) {
+		graph.Edges = append(graph.Edges, report.Edge{From: from, To: to, Label: label})
 	}
-	defer f.Close()
-
-	// 5. Encabezado del grafo
-	fmt.Fprintln(f, "digraph G {")
-	fmt.Fprintln(f, "    rankdir=TB;") // de arriba hacia abajo
-	fmt.Fprintln(f, "    node [shape=record, fontsize=10, style=filled];")
-	fmt.Fprintln(f, "    edge [arrowhead=vee, arrowsize=0.7];")
-	fmt.Fprintf(f, "    labelloc=\"t\";\n")
-	fmt.Fprintf(f, "    label=\"Reporte TREE - Partición %s (%s)\";\n", mountedPartition.Name, mountedPartition.ID)
-
-	// Subgrafos
-	fmt.Fprintln(f, "    subgraph cluster_inodes {")
-	fmt.Fprintln(f, "        label=\"Inodos\";")
-	fmt.Fprintln(f, "        color=blue; style=dashed;")
-
-	// 6. Procesar inodos
-	inodeSize := int64(binary.Size(structs.Inode{}))
-	for i := 0; i < int(sb.S_inodes_count); i++ {
-		if bmInodes[i] == 1 {
-			var inode structs.Inode
-			offset := int64(sb.S_inode_start) + int64(i)*inodeSize
-			file.Seek(offset, 0)
-			binary.Read(file, binary.BigEndian, &inode)
-
-			label := fmt.Sprintf("Inodo %d | UID=%d | Size=%d | Type=%d | Perm=%d",
-				i, inode.I_uid, inode.I_size, inode.I_type, inode.I_perm)
 
-			fmt.Fprintf(f, "        inode%d [label=\"%s\", fillcolor=lightblue];\n",
-				i, escapeDotLabel(label))
+	// Inodos y sus apuntadores
+	for i, used := range bmInodes {
+		if used != 1 || int32(i) == auditInode {
+			continue
+		}
+		inode, err := fs.ReadInode(file, sb, int32(i))
+		if err != nil {
+			continue
+		}
+		cells := []string{
+			fmt.Sprintf("Inodo %d", i),
+			fmt.Sprintf("UID=%d", inode.I_uid),
+			fmt.Sprintf("Size=%d", inode.I_size),
+			fmt.Sprintf("Type=%d", inode.I_type),
+			fmt.Sprintf("Perm=%d", inode.I_perm),
+		}
+		if inode.I_type == 2 {
+			// Enlace simbólico: mostrar a dónde apunta
+			if target, err := fs.ReadSymlinkTarget(file, sb, inode); err == nil {
+				cells = append(cells, "Enlace → "+target)
+			}
+		}
+		node := fmt.Sprintf("inode%d", i)
+		graph.Nodes = append(graph.Nodes, report.Node{ID: node, Cells: cells, Color: "#add8e6", Cluster: "inodos"})
 
-			// Relaciones inodo -> bloques
-			for j, b := range inode.I_block {
-				if b != -1 {
-					fmt.Fprintf(f, "        inode%d -> block%d [label=\"%d\"];\n", i, b, j)
-				}
+		for j, b := range inode.I_block {
+			if b >= 0 && b < sb.S_blocks_count {
+				edge(node, fmt.Sprintf("block%d", b), fmt.Sprintf("%d", j))
 			}
 		}
+		if fs.HasACL(inode) {
+			edge(node, fmt.Sprintf("block%d", inode.I_acl), "acl")
+		}
 	}
-	fmt.Fprintln(f, "    }") // cierre cluster_inodes
-
-	// Subgrafo de bloques
-	fmt.Fprintln(f, "    subgraph cluster_blocks {")
-	fmt.Fprintln(f, "        label=\"Bloques\";")
-	fmt.Fprintln(f, "        color=red; style=dashed;")
-
-	// 7. Procesar bloques
-	for i := 0; i < int(sb.S_blocks_count); i++ {
-		if bmBlocks[i] == 1 {
-			var label string
-			shape := "box"
-			color := "lightgreen" // carpeta por defecto
-
-			// Bloque carpeta
-			if fb, err := fs.ReadFolderBlock(file, sb, int32(i)); err == nil {
-				label = fmt.Sprintf("Bloque %d | Carpeta {", i)
-				for _, entry := range fb.B_content {
-					name := strings.TrimRight(string(entry.B_name[:]), "\x00")
-					if name != "" && entry.B_inodo != -1 {
-						label += fmt.Sprintf("%s -> inodo %d | ", escapeDotLabel(name), entry.B_inodo)
-						fmt.Fprintf(f, "        block%d -> inode%d [label=\"%s\"];\n", i, entry.B_inodo, escapeDotLabel(name))
-					}
-				}
-				label = strings.TrimRight(label, "| ")
-				label += "}"
-
-			} else if fblock, err := fs.ReadFileBlock(file, sb, int32(i)); err == nil {
-				// Bloque archivo
-				content := strings.TrimRight(string(fblock.B_content[:]), "\x00")
-				if len(content) > 50 {
-					content = content[:50] + "..."
-				}
-				label = fmt.Sprintf("Bloque %d | Archivo: %s", i, escapeDotLabel(content))
-				color = "khaki"
-
-			} else if apBlock, err := fs.ReadPointerBlock(file, sb, int32(i)); err == nil {
-				// Bloque de apuntadores
-				mainLabel := fmt.Sprintf("Bloque %d | Apuntadores", i)
-				fmt.Fprintf(f, "        block%d [label=\"%s\", shape=box, fillcolor=lightcoral];\n",
-					i, escapeDotLabel(mainLabel))
-
-				for j, ptr := range apBlock {
-					if ptr != -1 {
-						childName := fmt.Sprintf("block%d_ptr%d", i, j)
-						childLabel := fmt.Sprintf("Ptr %d -> %d", j, ptr)
-
-						fmt.Fprintf(f, "        %s [label=\"%s\", shape=box, fillcolor=lightcoral];\n",
-							childName, escapeDotLabel(childLabel))
 
-						fmt.Fprintf(f, "        block%d -> %s;\n", i, childName)
-						fmt.Fprintf(f, "        %s -> block%d;\n", childName, ptr)
-					}
+	// Bloques: las carpetas apuntan a los inodos de sus entradas y los bloques de
+	// apuntadores a los bloques que referencian
+	for i, used := range bmBlocks {
+		if used != 1 || auditBlocks[int32(i)] {
+			continue
+		}
+		kind := kinds[int32(i)]
+		node := fmt.Sprintf("block%d", i)
+		graph.Nodes = append(graph.Nodes, report.Node{
+			ID:      node,
+			Cells:   blockCells(file, sb, int32(i), kind, auditInode),
+			Color:   blockColors[kind],
+			Cluster: "bloques",
+		})
+
+		switch kind {
+		case fs.BlockFolder:
+			fb, err := fs.ReadFolderBlock(file, sb, int32(i))
+			if err != nil {
+				continue
+			}
+			for _, entry := range fb.B_content {
+				name := reportName(entry.B_name[:])
+				if name == "" || name == "." || name == ".." || entry.B_inodo == -1 || entry.B_inodo == auditInode {
+					continue
 				}
+				edge(node, fmt.Sprintf("inode%d", entry.B_inodo), name)
+			}
+		case fs.BlockPointer:
+			pointers, err := fs.ReadPointerBlock(file, sb, int32(i))
+			if err != nil {
 				continue
-			} else {
-				label = fmt.Sprintf("Bloque %d | Desconocido", i)
-				color = "white"
 			}
-
-			// Nodo del bloque
-			fmt.Fprintf(f, "        block%d [label=\"%s\", shape=%s, fillcolor=%s];\n",
-				i, escapeDotLabel(label), shape, color)
+			for j, p := range pointers {
+				if p >= 0 && p < sb.S_blocks_count {
+					edge(node, fmt.Sprintf("block%d", p), fmt.Sprintf("%d", j))
+				}
+			}
 		}
 	}
-	fmt.Fprintln(f, "    }") // cierre cluster_blocks
-
-	// Cierre grafo
-	fmt.Fprintln(f, "}")
-
-	// 8. Generar PNG
-	imgFile := path + ".png"
-	cmd := exec.Command("dot", "-Tpng", dotFile, "-o", imgFile)
-	if err := cmd.Run(); err != nil {
-		fmt.Println("Error al generar la imagen con Graphviz:", err)
-		return
-	}
 
-	fmt.Println("Reporte TREE generado en:", imgFile)
+	return &report.Report{
+		Title:    fmt.Sprintf("Reporte TREE - Partición %s (%s)", mp.Name, mp.ID),
+		Sections: []report.Section{{Graph: graph}},
+	}, nil
 }
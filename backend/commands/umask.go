@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"proyecto1/fs"
+	"proyecto1/state"
+	"proyecto1/structs"
+	"proyecto1/users"
+)
+
+// ExecuteUmask muestra o cambia la umask guardada en users.txt. Sin mask solo la muestra.
+// Cada usuario puede cambiar la suya; root puede cambiar la de cualquiera con user.
+func ExecuteUmask(user, mask string) {
+	if !state.CurrentSession.IsActive {
+		fmt.Println("Error: Debes iniciar sesión para usar umask.")
+		return
+	}
+
+	if user == "" {
+		user = state.CurrentSession.User
+	}
+	if mask != "" && user != state.CurrentSession.User && state.CurrentSession.User != "root" {
+		fmt.Println("Error: Solo el usuario root puede cambiar la umask de otros usuarios.")
+		return
+	}
+
+	var umask fs.Perm
+	if mask != "" {
+		var err error
+		if umask, err = fs.ParsePerm(mask); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		if umask&^0o777 != 0 {
+			fmt.Println("Error: la umask solo puede quitar permisos rwx (ej. 022).")
+			return
+		}
+	}
+
+	// Obtener partición activa
+	var mountedPartition *state.MountedPartition
+	for _, p := range state.GlobalMountedPartitions {
+		if p.ID == state.CurrentSession.PartitionID {
+			mountedPartition = &p
+			break
+		}
+	}
+	if mountedPartition == nil {
+		fmt.Println("Error: No se encontró la partición activa.")
+		return
+	}
+
+	file, err := os.OpenFile(mountedPartition.Path, os.O_RDWR, 0644)
+	if err != nil {
+		fmt.Println("Error al abrir el disco:", err)
+		return
+	}
+	defer file.Close()
+
+	// Leer superbloque
+	var sb structs.Superblock
+	file.Seek(mountedPartition.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		fmt.Println("Error al leer el superbloque:", err)
+		return
+	}
+
+	// Leer users.txt
+	table, err := users.Load(file, sb)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	account := table.User(user)
+	if account == nil || account.Deleted() {
+		fmt.Printf("Error: El usuario '%s' no existe.\n", user)
+		return
+	}
+
+	if mask == "" {
+		fmt.Printf("umask de '%s': %s (archivos %s, carpetas %s)\n", user, account.Umask.Octal(),
+			fs.NewPerm(false, account.Umask).Octal(), fs.NewPerm(true, account.Umask).Octal())
+		return
+	}
+
+	if err := table.SetUmask(user, umask); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if err := users.Save(file, sb, table); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	// La umask de la sesión cambia de inmediato
+	if user == state.CurrentSession.User {
+		state.CurrentSession.Umask = uint16(umask)
+	}
+
+	fmt.Printf("umask de '%s' cambiada a %s (archivos %s, carpetas %s)\n", user, umask.Octal(),
+		fs.NewPerm(false, umask).Octal(), fs.NewPerm(true, umask).Octal())
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"proyecto1/state"
+	"proyecto1/structs"
+	"proyecto1/users"
+)
+
+// ExecuteUsermod agrega (addGroup) o quita (delGroup) grupos suplementarios de un usuario.
+// El grupo principal se sigue cambiando con chgrp.
+func ExecuteUsermod(user, addGroup, delGroup string) {
+	if !state.CurrentSession.IsActive {
+		fmt.Println("Error: Debes iniciar sesión para usar usermod.")
+		return
+	}
+
+	if state.CurrentSession.User != "root" {
+		fmt.Println("Error: Solo el usuario root puede usar usermod.")
+		return
+	}
+
+	// Obtener partición activa
+	var mountedPartition *state.MountedPartition
+	for _, p := range state.GlobalMountedPartitions {
+		if p.ID == state.CurrentSession.PartitionID {
+			mountedPartition = &p
+			break
+		}
+	}
+	if mountedPartition == nil {
+		fmt.Println("Error: No se encontró la partición activa.")
+		return
+	}
+
+	file, err := os.OpenFile(mountedPartition.Path, os.O_RDWR, 0644)
+	if err != nil {
+		fmt.Println("Error al abrir el disco:", err)
+		return
+	}
+	defer file.Close()
+
+	// Leer superbloque
+	var sb structs.Superblock
+	file.Seek(mountedPartition.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil {
+		fmt.Println("Error al leer el superbloque:", err)
+		return
+	}
+
+	// Leer users.txt
+	table, err := users.Load(file, sb)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	if addGroup != "" {
+		if err := table.AddUserGroup(user, addGroup); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
+	if delGroup != "" {
+		if err := table.RemoveUserGroup(user, delGroup); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
+
+	if err := users.Save(file, sb, table); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	// Si se modificó al usuario de la sesión, sus grupos cambian de inmediato
+	if user == state.CurrentSession.User {
+		state.CurrentSession.Groups = table.GIDs(user)
+	}
+
+	if addGroup != "" {
+		fmt.Printf("Usuario '%s' agregado al grupo '%s'.\n", user, addGroup)
+	}
+	if delGroup != "" {
+		fmt.Printf("Usuario '%s' quitado del grupo '%s'.\n", user, delGroup)
+	}
+}
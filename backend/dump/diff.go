@@ -0,0 +1,458 @@
+package dump
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DiffSection agrupa las diferencias de una parte del disco. Cada línea empieza con
+// '+' (agregado), '-' (eliminado o liberado) o '~' (modificado).
+type DiffSection struct {
+	Title string
+	Lines []string
+}
+
+// Diff compara dos volcados del mismo disco. Si name no está vacío solo se comparan
+// los sistemas de archivos de esa partición; el MBR y la tabla de particiones siempre.
+func Diff(a, b *Disk, name string) ([]DiffSection, error) {
+	pa, pb := partitionsByName(a), partitionsByName(b)
+	if name != "" && pa[name] == nil && pb[name] == nil {
+		return nil, fmt.Errorf("no existe la partición '%s' en ninguno de los discos", name)
+	}
+
+	var sections []DiffSection
+	add := func(title string, lines []string) {
+		if len(lines) > 0 {
+			sections = append(sections, DiffSection{Title: title, Lines: lines})
+		}
+	}
+
+	// MBR y tabla de particiones
+	var mbr []string
+	da, db := *a, *b
+	da.Path, db.Path = "", ""
+	for _, c := range fieldChanges(da, db) {
+		mbr = append(mbr, "~ "+c)
+	}
+	for _, n := range sortedKeys(pa, pb) {
+		x, y := pa[n], pb[n]
+		switch {
+		case y == nil:
+			mbr = append(mbr, fmt.Sprintf("- partición %s (%s, inicio %d, tamaño %d)", n, x.Type, x.Start, x.Size))
+		case x == nil:
+			mbr = append(mbr, fmt.Sprintf("+ partición %s (%s, inicio %d, tamaño %d)", n, y.Type, y.Start, y.Size))
+		default:
+			changes := fieldChanges(*x, *y)
+			if ea, eb := x.EBR, y.EBR; ea != nil && eb != nil {
+				changes = append(changes, fieldChanges(*ea, *eb)...)
+			}
+			if len(changes) > 0 {
+				mbr = append(mbr, fmt.Sprintf("~ partición %s: %s", n, strings.Join(changes, ", ")))
+			}
+		}
+	}
+	add("MBR y particiones", mbr)
+
+	for _, n := range sortedKeys(pa, pb) {
+		if name != "" && n != name {
+			continue
+		}
+		var fa, fb *Filesystem
+		if pa[n] != nil {
+			fa = pa[n].Filesystem
+		}
+		if pb[n] != nil {
+			fb = pb[n].Filesystem
+		}
+		switch {
+		case fa == nil && fb == nil:
+			continue
+		case fa == nil:
+			add("Partición "+n, []string{"+ sistema de archivos creado"})
+		case fb == nil:
+			add("Partición "+n, []string{"- sistema de archivos eliminado"})
+		default:
+			for _, s := range diffFilesystem(fa, fb) {
+				s.Title = fmt.Sprintf("Partición %s: %s", n, s.Title)
+				sections = append(sections, s)
+			}
+		}
+	}
+	return sections, nil
+}
+
+// partitionsByName indexa las primarias, la extendida y las lógicas por nombre.
+func partitionsByName(d *Disk) map[string]*Partition {
+	out := make(map[string]*Partition)
+	for i := range d.Partitions {
+		p := &d.Partitions[i]
+		out[p.Name] = p
+		for j := range p.Logicals {
+			out[p.Logicals[j].Name] = &p.Logicals[j]
+		}
+	}
+	return out
+}
+
+func sortedKeys(a, b map[string]*Partition) []string {
+	var keys []string
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if a[k] == nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffFilesystem compara superbloque, bitmaps, rutas, inodos y bloques.
+func diffFilesystem(a, b *Filesystem) []DiffSection {
+	ta, tb := newTree(a), newTree(b)
+	var sections []DiffSection
+	add := func(title string, lines []string) {
+		if len(lines) > 0 {
+			sections = append(sections, DiffSection{Title: title, Lines: lines})
+		}
+	}
+
+	var sb []string
+	for _, c := range fieldChanges(a.Superblock, b.Superblock) {
+		sb = append(sb, "~ "+c)
+	}
+	add("superbloque", sb)
+
+	add("bitmap de inodos", bitFlips(a.InodeBitmap, b.InodeBitmap))
+	add("bitmap de bloques", bitFlips(a.BlockBitmap, b.BlockBitmap))
+	add("entradas de carpeta", diffPaths(ta, tb))
+
+	// Inodos
+	var inodes []string
+	for _, i := range unionKeys(ta.inodes, tb.inodes) {
+		x, okA := ta.inodes[i]
+		y, okB := tb.inodes[i]
+		switch {
+		case !okA:
+			inodes = append(inodes, fmt.Sprintf("+ inodo %d (%s%s)", i, y.Type, tb.pathSuffix(i)))
+		case !okB:
+			inodes = append(inodes, fmt.Sprintf("- inodo %d (%s%s)", i, x.Type, ta.pathSuffix(i)))
+		default:
+			if changes := fieldChanges(x, y); len(changes) > 0 {
+				inodes = append(inodes, fmt.Sprintf("~ inodo %d%s: %s", i, bestPath(ta, tb, i), strings.Join(changes, ", ")))
+			}
+		}
+	}
+	add("inodos", inodes)
+
+	// Bloques
+	var blocks []string
+	for _, i := range unionKeys(ta.blocks, tb.blocks) {
+		x, okA := ta.blocks[i]
+		y, okB := tb.blocks[i]
+		switch {
+		case !okA:
+			blocks = append(blocks, fmt.Sprintf("+ bloque %d (%s%s)", i, y.Kind, tb.ownerSuffix(i)))
+		case !okB:
+			blocks = append(blocks, fmt.Sprintf("- bloque %d (%s%s)", i, x.Kind, ta.ownerSuffix(i)))
+		default:
+			if changes := blockChanges(x, y); len(changes) > 0 {
+				owner := tb.ownerSuffix(i)
+				if owner == "" {
+					owner = ta.ownerSuffix(i)
+				}
+				blocks = append(blocks, fmt.Sprintf("~ bloque %d (%s%s): %s", i, y.Kind, owner, strings.Join(changes, ", ")))
+			}
+		}
+	}
+	add("bloques", blocks)
+	return sections
+}
+
+// tree indexa un sistema de archivos volcado: inodos y bloques por número, la ruta de
+// cada inodo alcanzable desde la raíz y el inodo dueño de cada bloque.
+type tree struct {
+	inodes map[int32]Inode
+	blocks map[int32]Block
+	paths  map[string]int32 // ruta -> inodo
+	names  map[int32]string // inodo -> primera ruta encontrada
+	owner  map[int32]int32  // bloque -> inodo
+}
+
+func newTree(f *Filesystem) *tree {
+	t := &tree{
+		inodes: make(map[int32]Inode),
+		blocks: make(map[int32]Block),
+		paths:  make(map[string]int32),
+		names:  make(map[int32]string),
+		owner:  make(map[int32]int32),
+	}
+	for _, in := range f.Inodes {
+		t.inodes[in.Index] = in
+	}
+	for _, b := range f.Blocks {
+		t.blocks[b.Index] = b
+	}
+	for _, in := range f.Inodes {
+		for _, b := range t.dataBlocks(in) {
+			t.owner[b] = in.Index
+		}
+		if in.ACL > 0 {
+			t.owner[in.ACL] = in.Index
+		}
+	}
+	t.walk(0, "/", make(map[int32]bool))
+	return t
+}
+
+// dataBlocks devuelve los bloques del inodo, incluidos los de apuntadores.
+func (t *tree) dataBlocks(in Inode) []int32 {
+	var out []int32
+	var follow func(b int32, level int)
+	follow = func(b int32, level int) {
+		if b < 0 {
+			return
+		}
+		out = append(out, b)
+		if level == 0 {
+			return
+		}
+		for _, p := range t.blocks[b].Pointers {
+			follow(p, level-1)
+		}
+	}
+	for i, b := range in.Blocks {
+		level := 0
+		if i >= 12 {
+			level = i - 11 // 12 simple, 13 doble, 14 triple
+		}
+		follow(b, level)
+	}
+	return out
+}
+
+// walk recorre las carpetas desde el inodo ino; visited evita ciclos en imágenes dañadas.
+func (t *tree) walk(ino int32, p string, visited map[int32]bool) {
+	in, ok := t.inodes[ino]
+	t.paths[p] = ino
+	if _, seen := t.names[ino]; !seen {
+		t.names[ino] = p
+	}
+	if !ok || in.Type != "dir" || visited[ino] {
+		return
+	}
+	visited[ino] = true
+	for _, b := range t.dataBlocks(in) {
+		block := t.blocks[b]
+		if block.Kind != "folder" {
+			continue
+		}
+		for _, e := range block.Entries {
+			if e.Name == "." || e.Name == ".." {
+				continue
+			}
+			t.walk(e.Inode, path.Join(p, e.Name), visited)
+		}
+	}
+}
+
+func (t *tree) pathSuffix(ino int32) string {
+	if p, ok := t.names[ino]; ok {
+		return ", " + p
+	}
+	return ""
+}
+
+func (t *tree) ownerSuffix(block int32) string {
+	ino, ok := t.owner[block]
+	if !ok {
+		return ""
+	}
+	if p, ok := t.names[ino]; ok {
+		return fmt.Sprintf(", inodo %d %s", ino, p)
+	}
+	return fmt.Sprintf(", inodo %d", ino)
+}
+
+// bestPath da la ruta de un inodo para los mensajes: la nueva o, si ya no es
+// alcanzable, la anterior.
+func bestPath(a, b *tree, ino int32) string {
+	if p, ok := b.names[ino]; ok {
+		return " " + p
+	}
+	if p, ok := a.names[ino]; ok {
+		return " " + p
+	}
+	return ""
+}
+
+// diffPaths compara las rutas alcanzables. Una ruta que desaparece y otra que aparece
+// con el mismo inodo se informa como movida.
+func diffPaths(a, b *tree) []string {
+	var lines, removed, added []string
+	for p, ino := range a.paths {
+		if other, ok := b.paths[p]; !ok {
+			removed = append(removed, p)
+		} else if other != ino {
+			lines = append(lines, fmt.Sprintf("~ %s: inodo %d → %d", p, ino, other))
+		}
+	}
+	for p := range b.paths {
+		if _, ok := a.paths[p]; !ok {
+			added = append(added, p)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+
+	moved := make(map[string]bool)
+	for _, from := range removed {
+		for _, to := range added {
+			if !moved[to] && a.paths[from] == b.paths[to] {
+				lines = append(lines, fmt.Sprintf("~ %s → %s (inodo %d)", from, to, b.paths[to]))
+				moved[from], moved[to] = true, true
+				break
+			}
+		}
+	}
+	for _, p := range removed {
+		if !moved[p] {
+			lines = append(lines, fmt.Sprintf("- %s (inodo %d)", p, a.paths[p]))
+		}
+	}
+	for _, p := range added {
+		if !moved[p] {
+			lines = append(lines, fmt.Sprintf("+ %s (inodo %d)", p, b.paths[p]))
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i][2:] < lines[j][2:] })
+	return lines
+}
+
+// bitFlips lista los bits que cambiaron, agrupados en rangos consecutivos.
+func bitFlips(a, b []Run) []string {
+	ba, bb := expand(a), expand(b)
+	n := max(len(ba), len(bb))
+	bit := func(bits []bool, i int) bool { return i < len(bits) && bits[i] }
+
+	var lines []string
+	for i := 0; i < n; {
+		x, y := bit(ba, i), bit(bb, i)
+		if x == y {
+			i++
+			continue
+		}
+		j := i
+		for j+1 < n && bit(ba, j+1) == x && bit(bb, j+1) == y {
+			j++
+		}
+		sign, dir := "+", "0→1"
+		if x {
+			sign, dir = "-", "1→0"
+		}
+		if i == j {
+			lines = append(lines, fmt.Sprintf("%s %d: %s", sign, i, dir))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s %d-%d: %s", sign, i, j, dir))
+		}
+		i = j + 1
+	}
+	return lines
+}
+
+func expand(runs []Run) []bool {
+	var bits []bool
+	for _, r := range runs {
+		for k := int32(0); k < r.Count; k++ {
+			bits = append(bits, r.Used)
+		}
+	}
+	return bits
+}
+
+// blockChanges describe en qué cambió un bloque.
+func blockChanges(a, b Block) []string {
+	var out []string
+	if a.Kind != b.Kind {
+		out = append(out, fmt.Sprintf("tipo %s → %s", a.Kind, b.Kind))
+	}
+	ea, eb := entrySet(a.Entries), entrySet(b.Entries)
+	for _, e := range a.Entries {
+		if eb[e] == 0 {
+			out = append(out, fmt.Sprintf("-%s→%d", e.Name, e.Inode))
+		}
+	}
+	for _, e := range b.Entries {
+		if ea[e] == 0 {
+			out = append(out, fmt.Sprintf("+%s→%d", e.Name, e.Inode))
+		}
+	}
+	if !reflect.DeepEqual(a.Content, b.Content) || a.ContentBase64 != b.ContentBase64 {
+		out = append(out, fmt.Sprintf("contenido (%d → %d bytes)", contentLen(a), contentLen(b)))
+	}
+	if !reflect.DeepEqual(a.Pointers, b.Pointers) {
+		out = append(out, fmt.Sprintf("apuntadores %v → %v", a.Pointers, b.Pointers))
+	}
+	if !reflect.DeepEqual(a.ACL, b.ACL) {
+		out = append(out, fmt.Sprintf("acl %v → %v", a.ACL, b.ACL))
+	}
+	return out
+}
+
+func entrySet(entries []Entry) map[Entry]int {
+	set := make(map[Entry]int)
+	for _, e := range entries {
+		set[e]++
+	}
+	return set
+}
+
+func contentLen(b Block) int {
+	if b.Content != nil {
+		return len(*b.Content)
+	}
+	return len(b.ContentBase64) * 3 / 4
+}
+
+// fieldChanges compara los campos simples (números, textos y arreglos) de dos structs
+// del mismo tipo y devuelve "campo a → b" por cada uno que difiera. Los slices y
+// punteros se comparan aparte.
+func fieldChanges(a, b any) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	var out []string
+	for i := 0; i < va.NumField(); i++ {
+		f := va.Type().Field(i)
+		switch f.Type.Kind() {
+		case reflect.Slice, reflect.Pointer, reflect.Map:
+			continue
+		}
+		x, y := va.Field(i).Interface(), vb.Field(i).Interface()
+		if reflect.DeepEqual(x, y) {
+			continue
+		}
+		label := f.Name
+		if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag != "" {
+			label = tag
+		}
+		out = append(out, fmt.Sprintf("%s %v → %v", label, x, y))
+	}
+	return out
+}
+
+func unionKeys[V any](a, b map[int32]V) []int32 {
+	var keys []int32
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
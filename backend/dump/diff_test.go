@@ -0,0 +1,83 @@
+package dump_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"proyecto1/commands"
+	"proyecto1/dump"
+)
+
+func TestDiffMkfileRemove(t *testing.T) {
+	path := newDisk(t)
+	// snapshot copia la imagen tal como está y la vuelca
+	snapshot := func(name string) *dump.Disk {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		copyPath := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(copyPath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		d, err := dump.Read(copyPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	before := snapshot("antes.mia")
+	quiet(t, func() { commands.ExecuteMkfile("/docs/b.txt", false, 10, "") })
+	created := snapshot("creado.mia")
+	quiet(t, func() { commands.ExecuteRemove("/docs/b.txt") })
+	removed := snapshot("borrado.mia")
+
+	tests := []struct {
+		name string
+		a, b *dump.Disk
+		want []dump.DiffSection
+	}{
+		{"sin cambios", before, before, nil},
+		{"mkfile", before, created, []dump.DiffSection{
+			{"Partición P1: superbloque", []string{"~ S_free_blocks_count 980 → 979", "~ S_free_inodes_count 323 → 322"}},
+			{"Partición P1: bitmap de inodos", []string{"+ 6: 0→1"}},
+			{"Partición P1: bitmap de bloques", []string{"+ 7: 0→1"}},
+			{"Partición P1: entradas de carpeta", []string{"+ /docs/b.txt (inodo 6)"}},
+			{"Partición P1: inodos", []string{"+ inodo 6 (file, /docs/b.txt)"}},
+			{"Partición P1: bloques", []string{"~ bloque 3 (folder, inodo 3 /docs): +b.txt→6", "+ bloque 7 (file, inodo 6 /docs/b.txt)"}},
+		}},
+		{"remove", created, removed, []dump.DiffSection{
+			{"Partición P1: superbloque", []string{"~ S_free_blocks_count 979 → 980", "~ S_free_inodes_count 322 → 323"}},
+			{"Partición P1: bitmap de inodos", []string{"- 6: 1→0"}},
+			{"Partición P1: bitmap de bloques", []string{"- 7: 1→0"}},
+			{"Partición P1: entradas de carpeta", []string{"- /docs/b.txt (inodo 6)"}},
+			{"Partición P1: inodos", []string{"- inodo 6 (file, /docs/b.txt)"}},
+			{"Partición P1: bloques", []string{"~ bloque 3 (folder, inodo 3 /docs): -b.txt→6", "- bloque 7 (file, inodo 6 /docs/b.txt)"}},
+		}},
+		// remove libera lo mismo que reservó mkfile: el disco vuelve a quedar igual
+		{"mkfile y remove", before, removed, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dump.Diff(tt.a, tt.b, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff =\n%v\nse esperaba\n%v", got, tt.want)
+			}
+		})
+	}
+
+	// -name limita el contenido comparado a esa partición
+	if got, err := dump.Diff(before, created, "L1"); err != nil || len(got) != 0 {
+		t.Errorf("Diff con -name=L1 = %v, %v; se esperaba sin diferencias", got, err)
+	}
+	if _, err := dump.Diff(before, created, "nada"); err == nil {
+		t.Error("Diff con una partición que no existe no devolvió error")
+	}
+}
@@ -0,0 +1,349 @@
+// Package dump lee una imagen de disco completa y la describe como datos: MBR, cadena de
+// EBR y, por cada partición formateada, superbloque, bitmaps, inodos y bloques en uso.
+// El resultado se serializa a JSON con el comando dump; el orden es siempre el del disco,
+// así dos volcados de la misma imagen son idénticos y se pueden comparar con diff.
+package dump
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"proyecto1/fs"
+	"proyecto1/structs"
+	"proyecto1/utils"
+)
+
+// Disk es el volcado de una imagen .mia.
+type Disk struct {
+	Path       string      `json:"path"`
+	Size       int64       `json:"size"`
+	Created    int64       `json:"created"`
+	Signature  int64       `json:"signature"`
+	Fit        string      `json:"fit"`
+	Partitions []Partition `json:"partitions"`
+}
+
+// Partition es una partición del MBR (Slot 0 a 3) o una lógica (Slot -1, con su EBR).
+type Partition struct {
+	Slot        int         `json:"slot"`
+	Type        string      `json:"type"` // P, E o L
+	Status      string      `json:"status"`
+	Fit         string      `json:"fit"`
+	Start       int64       `json:"start"`
+	Size        int64       `json:"size"`
+	Name        string      `json:"name"`
+	ID          string      `json:"id,omitempty"` // asignado al montar
+	Correlative int64       `json:"correlative,omitempty"`
+	EBR         *EBR        `json:"ebr,omitempty"`
+	Logicals    []Partition `json:"logicals,omitempty"` // solo la extendida
+	Filesystem  *Filesystem `json:"filesystem,omitempty"`
+	Error       string      `json:"error,omitempty"` // si no se pudo leer su contenido
+}
+
+// EBR ubica una lógica en la cadena de la extendida.
+type EBR struct {
+	Offset int64 `json:"offset"` // byte donde está el EBR
+	Next   int64 `json:"next"`
+}
+
+// Filesystem es el contenido de una partición formateada.
+type Filesystem struct {
+	Superblock  structs.Superblock `json:"superblock"`
+	InodeBitmap []Run              `json:"inode_bitmap"`
+	BlockBitmap []Run              `json:"block_bitmap"`
+	Inodes      []Inode            `json:"inodes"`
+	Blocks      []Block            `json:"blocks"`
+}
+
+// Run es un tramo del bitmap con el mismo valor (codificación run-length).
+type Run struct {
+	Start int32 `json:"start"`
+	Count int32 `json:"count"`
+	Used  bool  `json:"used"`
+}
+
+// Inode es un inodo en uso.
+type Inode struct {
+	Index  int32     `json:"index"`
+	Type   string    `json:"type"` // dir, file o symlink
+	UID    int32     `json:"uid"`
+	GID    int32     `json:"gid"`
+	Size   int32     `json:"size"`
+	Mode   string    `json:"mode"` // octal, ej. 2775
+	Perm   string    `json:"perm"` // como ls -l, ej. rwxrwsr-x
+	Atime  int64     `json:"atime"`
+	Ctime  int64     `json:"ctime"`
+	Mtime  int64     `json:"mtime"`
+	Links  int32     `json:"links"`
+	Blocks [15]int32 `json:"blocks"`
+	ACL    int32     `json:"acl"`
+}
+
+// Block es un bloque en uso, decodificado según el inodo que lo usa.
+type Block struct {
+	Index         int32      `json:"index"`
+	Kind          string     `json:"kind"` // folder, file, pointer, acl o unknown
+	Entries       []Entry    `json:"entries,omitempty"`
+	Content       *string    `json:"content,omitempty"`
+	ContentBase64 string     `json:"content_base64,omitempty"` // si el contenido no es UTF-8
+	Pointers      []int32    `json:"pointers,omitempty"`
+	ACL           []ACLEntry `json:"acl,omitempty"`
+}
+
+// ACLEntry es una entrada de un bloque de ACL.
+type ACLEntry struct {
+	Tag  string `json:"tag"` // user, group o mask
+	ID   int32  `json:"id"`
+	Perm string `json:"perm"` // rwx
+}
+
+// Entry es una entrada ocupada de un bloque de carpeta.
+type Entry struct {
+	Name  string `json:"name"`
+	Inode int32  `json:"inode"`
+}
+
+// Read vuelca la imagen de disco de path.
+func Read(path string) (*Disk, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir el disco: %w", err)
+	}
+	defer file.Close()
+
+	mbr, err := utils.ReadMBR(file)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el MBR: %w", err)
+	}
+	d := &Disk{
+		Path:       path,
+		Size:       mbr.Mbr_tamano,
+		Created:    mbr.Mbr_fecha_creacion,
+		Signature:  mbr.Mbr_dsk_signature,
+		Fit:        char(mbr.Dsk_fit),
+		Partitions: []Partition{},
+	}
+
+	for slot, p := range mbr.Mbr_partitions {
+		if p.Part_status == '0' || p.Part_start < 0 {
+			continue
+		}
+		part := Partition{
+			Slot:        slot,
+			Type:        char(p.Part_type),
+			Status:      char(p.Part_status),
+			Fit:         char(p.Part_fit),
+			Start:       p.Part_start,
+			Size:        p.Part_s,
+			Name:        name(p.Part_name[:]),
+			ID:          name(p.Part_id[:]),
+			Correlative: max(p.Part_correlative, 0),
+		}
+		if p.Part_type == 'E' {
+			part.Logicals = readLogicals(file, p)
+		} else {
+			readFilesystem(file, &part)
+		}
+		d.Partitions = append(d.Partitions, part)
+	}
+	return d, nil
+}
+
+// readLogicals recorre la cadena de EBR de la extendida.
+func readLogicals(file *os.File, ext structs.Partition) []Partition {
+	var logicals []Partition
+	seen := make(map[int64]bool) // una cadena dañada podría cerrar un ciclo
+	for pos := ext.Part_start; pos != -1 && !seen[pos]; {
+		seen[pos] = true
+		ebr, err := utils.ReadEBR(file, pos)
+		if err != nil {
+			break
+		}
+		if ebr.Part_status != '0' && ebr.Part_s > 0 {
+			part := Partition{
+				Slot:   -1,
+				Type:   "L",
+				Status: char(ebr.Part_status),
+				Fit:    char(ebr.Part_fit),
+				Start:  ebr.Part_start,
+				Size:   ebr.Part_s,
+				Name:   name(ebr.Part_name[:]),
+				EBR:    &EBR{Offset: pos, Next: ebr.Part_next},
+			}
+			readFilesystem(file, &part)
+			logicals = append(logicals, part)
+		}
+		pos = ebr.Part_next
+	}
+	return logicals
+}
+
+// readFilesystem llena part.Filesystem si la partición tiene un superbloque válido.
+func readFilesystem(file *os.File, part *Partition) {
+	var sb structs.Superblock
+	file.Seek(part.Start, 0)
+	if err := binary.Read(file, binary.BigEndian, &sb); err != nil || sb.S_magic != 0xEF53 {
+		return // sin formato
+	}
+	if err := fs.CheckLayout(sb); err != nil {
+		part.Error = err.Error()
+		return
+	}
+	fsys, err := readContent(file, sb)
+	if err != nil {
+		part.Error = err.Error()
+	}
+	part.Filesystem = fsys
+}
+
+func readContent(file *os.File, sb structs.Superblock) (*Filesystem, error) {
+	fsys := &Filesystem{Superblock: sb, Inodes: []Inode{}, Blocks: []Block{}}
+	bmInodes := make([]byte, sb.S_inodes_count)
+	if _, err := file.ReadAt(bmInodes, int64(sb.S_bm_inode_start)); err != nil {
+		return fsys, fmt.Errorf("error al leer el bitmap de inodos: %w", err)
+	}
+	bmBlocks := make([]byte, sb.S_blocks_count)
+	if _, err := file.ReadAt(bmBlocks, int64(sb.S_bm_block_start)); err != nil {
+		return fsys, fmt.Errorf("error al leer el bitmap de bloques: %w", err)
+	}
+	fsys.InodeBitmap = runs(bmInodes)
+	fsys.BlockBitmap = runs(bmBlocks)
+
+	for i, used := range bmInodes {
+		if used != 1 {
+			continue
+		}
+		inode, err := fs.ReadInode(file, sb, int32(i))
+		if err != nil {
+			return fsys, fmt.Errorf("error al leer el inodo %d: %w", i, err)
+		}
+		fsys.Inodes = append(fsys.Inodes, Inode{
+			Index:  int32(i),
+			Type:   inodeType(inode.I_type),
+			UID:    inode.I_uid,
+			GID:    inode.I_gid,
+			Size:   inode.I_size,
+			Mode:   fs.PermOf(inode).Octal(),
+			Perm:   fs.PermOf(inode).String(),
+			Atime:  inode.I_atime,
+			Ctime:  inode.I_ctime,
+			Mtime:  inode.I_mtime,
+			Links:  inode.I_links,
+			Blocks: inode.I_block,
+			ACL:    inode.I_acl,
+		})
+	}
+
+	kinds := fs.ClassifyBlocks(file, sb, bmInodes)
+	for i, used := range bmBlocks {
+		if used != 1 {
+			continue
+		}
+		b, err := readBlock(file, sb, int32(i), kinds[int32(i)])
+		if err != nil {
+			return fsys, fmt.Errorf("error al leer el bloque %d: %w", i, err)
+		}
+		fsys.Blocks = append(fsys.Blocks, b)
+	}
+	return fsys, nil
+}
+
+func readBlock(file *os.File, sb structs.Superblock, index int32, kind fs.BlockKind) (Block, error) {
+	b := Block{Index: index, Kind: kind.String()}
+	switch kind {
+	case fs.BlockFolder:
+		fb, err := fs.ReadFolderBlock(file, sb, index)
+		if err != nil {
+			return b, err
+		}
+		for _, e := range fb.B_content {
+			if n := name(e.B_name[:]); n != "" && e.B_inodo != -1 {
+				b.Entries = append(b.Entries, Entry{Name: n, Inode: e.B_inodo})
+			}
+		}
+	case fs.BlockFile, fs.BlockUnknown:
+		fb, err := fs.ReadFileBlock(file, sb, index)
+		if err != nil {
+			return b, err
+		}
+		content := strings.TrimRight(string(fb.B_content), "\x00")
+		if utf8.ValidString(content) {
+			b.Content = &content
+		} else {
+			b.ContentBase64 = base64.StdEncoding.EncodeToString([]byte(content))
+		}
+	case fs.BlockPointer:
+		pointers, err := fs.ReadPointerBlock(file, sb, index)
+		if err != nil {
+			return b, err
+		}
+		for _, p := range pointers {
+			if p != -1 {
+				b.Pointers = append(b.Pointers, p)
+			}
+		}
+	case fs.BlockACL:
+		// ReadACL parte del inodo; basta uno que apunte a este bloque
+		entries, err := fs.ReadACL(file, sb, structs.Inode{I_acl: index})
+		if err != nil {
+			return b, err
+		}
+		for _, e := range entries {
+			b.ACL = append(b.ACL, ACLEntry{Tag: aclTag(e.Tag), ID: e.ID, Perm: e.Perm.String()[6:]})
+		}
+	}
+	return b, nil
+}
+
+// runs codifica un bitmap como tramos consecutivos del mismo valor.
+func runs(bm []byte) []Run {
+	out := []Run{}
+	for i, v := range bm {
+		used := v == 1
+		if n := len(out); n > 0 && out[n-1].Used == used {
+			out[n-1].Count++
+			continue
+		}
+		out = append(out, Run{Start: int32(i), Count: 1, Used: used})
+	}
+	return out
+}
+
+func inodeType(t int32) string {
+	switch t {
+	case 0:
+		return "dir"
+	case 2:
+		return "symlink"
+	default:
+		return "file"
+	}
+}
+
+func aclTag(t fs.ACLTag) string {
+	switch t {
+	case fs.ACLUser:
+		return "user"
+	case fs.ACLGroup:
+		return "group"
+	case fs.ACLMask:
+		return "mask"
+	default:
+		return fmt.Sprintf("tag%d", t)
+	}
+}
+
+func name(b []byte) string {
+	return strings.TrimRight(string(b), "\x00")
+}
+
+func char(b byte) string {
+	if b == 0 {
+		return ""
+	}
+	return string(b)
+}
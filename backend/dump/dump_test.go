@@ -0,0 +1,138 @@
+package dump_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"proyecto1/commands"
+	"proyecto1/dump"
+	"proyecto1/state"
+	"proyecto1/structs"
+)
+
+var update = flag.Bool("update", false, "reescribe los archivos de testdata")
+
+// quiet ejecuta fn sin mostrar lo que imprimen los comandos.
+func quiet(t *testing.T, fn func()) {
+	t.Helper()
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = null
+	defer func() {
+		os.Stdout = stdout
+		null.Close()
+	}()
+	fn()
+}
+
+// newDisk crea un disco pequeño con una primaria formateada (con una carpeta, un archivo
+// y un enlace simbólico) y una extendida con una lógica, y deja la sesión de root abierta.
+func newDisk(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "disco.mia")
+	t.Cleanup(func() {
+		state.CurrentSession = state.Session{}
+		state.GlobalMountedPartitions = nil
+	})
+	quiet(t, func() {
+		commands.ExecuteMkdisk(1, "m", "ff", path)
+		commands.ExecuteFdisk(path, "P1", "k", "p", "wf", 300, "", 0)
+		commands.ExecuteFdisk(path, "E1", "k", "e", "wf", 300, "", 0)
+		commands.ExecuteFdisk(path, "L1", "k", "l", "wf", 100, "", 0)
+		commands.ExecuteMount(path, "P1")
+	})
+	if len(state.GlobalMountedPartitions) != 1 {
+		t.Fatal("no se pudo montar la partición")
+	}
+	id := state.GlobalMountedPartitions[0].ID
+	quiet(t, func() {
+		commands.ExecuteMkfs(id, "full", "2fs", 0, 3, structs.FILE_BLOCK_SIZE)
+		commands.ExecuteLogin("root", "123", id)
+		commands.ExecuteMkdir("/docs", false)
+		commands.ExecuteMkfile("/docs/a.txt", false, 30, "")
+		commands.ExecuteLn("/docs/a.txt", "/atajo", true)
+	})
+	if !state.CurrentSession.IsActive {
+		t.Fatal("no se pudo iniciar sesión como root")
+	}
+	return path
+}
+
+var (
+	bcryptHash  = regexp.MustCompile(`\$2[aby]\$\d\d\$[./A-Za-z0-9]{53}`)
+	auditStamps = regexp.MustCompile(`(?m)^\d{9,},`)
+)
+
+// normalize borra lo que cambia entre dos corridas: fechas, firma del disco, la sal de
+// los hashes de users.txt, las horas de /.audit y la letra del ID de montaje.
+func normalize(d *dump.Disk) {
+	d.Path, d.Created, d.Signature = "disco.mia", 0, 0
+	for i := range d.Partitions {
+		if id := d.Partitions[i].ID; id != "" {
+			d.Partitions[i].ID = id[:len(id)-1] + "A"
+		}
+		f := d.Partitions[i].Filesystem
+		if f == nil {
+			continue
+		}
+		f.Superblock.S_mtime, f.Superblock.S_umtime = 0, 0
+		for j := range f.Inodes {
+			f.Inodes[j].Atime, f.Inodes[j].Ctime, f.Inodes[j].Mtime = 0, 0, 0
+		}
+		for j := range f.Blocks {
+			if c := f.Blocks[j].Content; c != nil {
+				s := auditStamps.ReplaceAllString(bcryptHash.ReplaceAllString(*c, "HASH"), "0,")
+				f.Blocks[j].Content = &s
+			}
+		}
+	}
+}
+
+func TestDumpGolden(t *testing.T) {
+	path := newDisk(t)
+	d, err := dump.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalize(d)
+	got, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	golden := filepath.Join("testdata", "disco.json")
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (go test ./dump -update lo genera)", err)
+	}
+	if string(got) != string(want) {
+		gl, wl := strings.Split(string(got), "\n"), strings.Split(string(want), "\n")
+		for i := 0; i < len(gl) && i < len(wl); i++ {
+			if gl[i] != wl[i] {
+				t.Fatalf("el volcado no coincide con %s en la línea %d:\n  %s\nse esperaba\n  %s", golden, i+1, gl[i], wl[i])
+			}
+		}
+		t.Fatalf("el volcado tiene %d líneas y %s %d", len(gl), golden, len(wl))
+	}
+
+	// Leer dos veces la misma imagen da el mismo volcado
+	again, _ := dump.Read(path)
+	normalize(again)
+	if b, _ := json.MarshalIndent(again, "", "  "); string(b)+"\n" != string(got) {
+		t.Error("dos volcados de la misma imagen difieren")
+	}
+}
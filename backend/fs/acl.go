@@ -0,0 +1,192 @@
+package fs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"proyecto1/structs"
+)
+
+// ACL de un inodo: entradas extra al estilo POSIX que se guardan en un bloque propio
+// apuntado por I_acl. El propietario, el grupo dueño y "otros" siguen saliendo de I_perm;
+// la ACL solo agrega usuarios y grupos con nombre y, opcionalmente, una máscara.
+//
+// Formato del bloque: entradas de aclEntrySize bytes [tag][permisos][relleno x2][id int32],
+// en big endian como el resto del sistema de archivos. Un tag 0 marca el final.
+
+// ACLTag es el tipo de una entrada de la ACL.
+type ACLTag uint8
+
+const (
+	ACLUser  ACLTag = 1 // usuario con nombre (ID = UID)
+	ACLGroup ACLTag = 2 // grupo con nombre (ID = GID)
+	ACLMask  ACLTag = 3 // máscara: limita a los usuarios con nombre y a todos los grupos
+)
+
+const aclEntrySize = 8
+
+// ACLEntry es una entrada de la ACL. Perm usa solo los 3 bits rwx.
+type ACLEntry struct {
+	Tag  ACLTag
+	ID   int32
+	Perm Perm
+}
+
+// HasACL indica si el inodo tiene un bloque de ACL. 0 también significa "sin ACL":
+// el bloque 0 siempre es la carpeta raíz.
+func HasACL(inode structs.Inode) bool {
+	return inode.I_acl > 0
+}
+
+// MaxACLEntries es la cantidad de entradas que caben en un bloque.
+func MaxACLEntries(sb structs.Superblock) int {
+	return int(sb.S_block_size) / aclEntrySize
+}
+
+// ReadACL lee las entradas de la ACL del inodo (nil si no tiene).
+func ReadACL(file *os.File, sb structs.Superblock, inode structs.Inode) ([]ACLEntry, error) {
+	if !HasACL(inode) {
+		return nil, nil
+	}
+	if !validBlock(sb, inode.I_acl) {
+		return nil, fmt.Errorf("bloque de ACL %d fuera de rango", inode.I_acl)
+	}
+	raw := make([]byte, sb.S_block_size)
+	if _, err := file.ReadAt(raw, blockOffset(sb, inode.I_acl)); err != nil {
+		return nil, err
+	}
+	var entries []ACLEntry
+	for off := 0; off+aclEntrySize <= len(raw); off += aclEntrySize {
+		tag := ACLTag(raw[off])
+		if tag == 0 {
+			break
+		}
+		entries = append(entries, ACLEntry{
+			Tag:  tag,
+			Perm: Perm(raw[off+1]) & 7,
+			ID:   int32(binary.BigEndian.Uint32(raw[off+4:])),
+		})
+	}
+	return entries, nil
+}
+
+// WriteACL guarda las entradas en el bloque de ACL del inodo, asignándolo si hace falta.
+// Con una lista vacía libera el bloque. No escribe el inodo: eso queda para quien llama.
+func WriteACL(file *os.File, sb structs.Superblock, inode *structs.Inode, entries []ACLEntry) error {
+	if len(entries) == 0 {
+		return ReleaseACL(file, sb, inode)
+	}
+	if len(entries) > MaxACLEntries(sb) {
+		return fmt.Errorf("la ACL tiene %d entradas y solo caben %d", len(entries), MaxACLEntries(sb))
+	}
+	if !HasACL(*inode) {
+		block, err := allocBlock(file, sb)
+		if err != nil {
+			return err
+		}
+		inode.I_acl = block
+	}
+
+	raw := make([]byte, sb.S_block_size)
+	for i, e := range entries {
+		off := i * aclEntrySize
+		raw[off] = byte(e.Tag)
+		raw[off+1] = byte(e.Perm & 7)
+		binary.BigEndian.PutUint32(raw[off+4:], uint32(e.ID))
+	}
+	_, err := file.WriteAt(raw, blockOffset(sb, inode.I_acl))
+	return err
+}
+
+// ReleaseACL marca como libre el bloque de ACL del inodo y deja I_acl en -1.
+func ReleaseACL(file *os.File, sb structs.Superblock, inode *structs.Inode) error {
+	if HasACL(*inode) && validBlock(sb, inode.I_acl) {
+		if err := MarkBlockAsFree(file, sb, inode.I_acl, superblockStart(sb)); err != nil {
+			return err
+		}
+	}
+	inode.I_acl = -1
+	return nil
+}
+
+// Access indica si c tiene todos los bits de want sobre el inodo, consultando la ACL si
+// el inodo tiene una. Sigue el orden de POSIX: root, propietario, usuario con nombre,
+// grupos (el dueño y los con nombre, limitados por la máscara) y por último otros.
+func Access(file *os.File, sb structs.Superblock, inode structs.Inode, c Cred, want Perm) bool {
+	if c.IsRoot() {
+		return true
+	}
+	if !HasACL(inode) {
+		return Allowed(inode, c, want)
+	}
+	entries, err := ReadACL(file, sb, inode)
+	if err != nil {
+		return Allowed(inode, c, want)
+	}
+
+	p := PermOf(inode)
+	if inode.I_uid == c.UID {
+		return (p>>6)&7&want == want
+	}
+
+	mask := Perm(7)
+	for _, e := range entries {
+		if e.Tag == ACLMask {
+			mask = e.Perm
+		}
+	}
+	for _, e := range entries {
+		if e.Tag == ACLUser && e.ID == c.UID {
+			return e.Perm&mask&want == want
+		}
+	}
+
+	matched := false
+	if c.InGroup(inode.I_gid) {
+		matched = true
+		if (p>>3)&7&mask&want == want {
+			return true
+		}
+	}
+	for _, e := range entries {
+		if e.Tag == ACLGroup && c.InGroup(e.ID) {
+			matched = true
+			if e.Perm&mask&want == want {
+				return true
+			}
+		}
+	}
+	if matched {
+		return false
+	}
+	return p&7&want == want
+}
+
+// ParseACLPerm interpreta los permisos de una entrada de ACL: letras ("rwx", "r-x", "rw")
+// o un dígito octal ("5").
+func ParseACLPerm(s string) (Perm, error) {
+	if len(s) == 1 && s[0] >= '0' && s[0] <= '7' {
+		return Perm(s[0] - '0'), nil
+	}
+	var p Perm
+	for _, c := range s {
+		switch c {
+		case 'r':
+			p |= PermRead
+		case 'w':
+			p |= PermWrite
+		case 'x':
+			p |= PermExec
+		case '-':
+		default:
+			return 0, fmt.Errorf("permiso de ACL inválido '%s'", s)
+		}
+	}
+	return p, nil
+}
+
+// ACLPermString devuelve los 3 bits de p como "r-x".
+func ACLPermString(p Perm) string {
+	return (p & 7).String()[6:]
+}
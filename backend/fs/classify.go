@@ -0,0 +1,67 @@
+package fs
+
+import (
+	"os"
+	"proyecto1/structs"
+)
+
+// BlockKind es lo que guarda un bloque según el inodo que lo usa.
+type BlockKind int
+
+const (
+	BlockUnknown BlockKind = iota // marcado en el bitmap pero sin inodo que lo use
+	BlockFolder
+	BlockFile
+	BlockPointer
+	BlockACL
+)
+
+// String devuelve el nombre del tipo, el que usan dump y los reportes.
+func (k BlockKind) String() string {
+	switch k {
+	case BlockFolder:
+		return "folder"
+	case BlockFile:
+		return "file"
+	case BlockPointer:
+		return "pointer"
+	case BlockACL:
+		return "acl"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifyBlocks recorre los inodos marcados en inodeBitmap y anota el tipo de cada
+// bloque que alcanzan. Un bloque no se puede clasificar solo por su contenido: cualquier
+// bloque se lee como carpeta sin error.
+func ClassifyBlocks(file *os.File, sb structs.Superblock, inodeBitmap []byte) map[int32]BlockKind {
+	kinds := make(map[int32]BlockKind)
+	for i, used := range inodeBitmap {
+		if used != 1 {
+			continue
+		}
+		inode, err := ReadInode(file, sb, int32(i))
+		if err != nil {
+			continue
+		}
+		data, pointers, err := InodeBlocks(file, sb, inode)
+		if err != nil {
+			continue
+		}
+		kind := BlockFile
+		if inode.I_type == 0 {
+			kind = BlockFolder
+		}
+		for _, b := range data {
+			kinds[b] = kind
+		}
+		for _, b := range pointers {
+			kinds[b] = BlockPointer
+		}
+		if HasACL(inode) && validBlock(sb, inode.I_acl) {
+			kinds[inode.I_acl] = BlockACL
+		}
+	}
+	return kinds
+}
@@ -4,24 +4,57 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
 	"proyecto1/structs"
 	"os"
 	"strings"
+	"time"
 )
 
+// DirectPointers es la cantidad de apuntadores directos de I_block en un archivo.
+// I_block[12] es el indirecto simple, I_block[13] el doble y I_block[14] el triple.
+// Las carpetas usan los 15 apuntadores como directos a bloques de carpeta.
+const DirectPointers = 12
+
+// MaxSymlinkHops es la cantidad máxima de enlaces simbólicos que se siguen al resolver una
+// ruta; si se supera se asume un ciclo (como ELOOP en Linux).
+const MaxSymlinkHops = 40
+
 // FindInodeByPath navega el sistema de archivos para encontrar el inodo de una ruta específica.
+// Sigue los enlaces simbólicos, incluido el último componente de la ruta, y exige permiso
+// de ejecución (x) sobre cada carpeta que atraviesa para el usuario de la sesión.
 func FindInodeByPath(file *os.File, sb structs.Superblock, path string) (structs.Inode, int32, error) {
+	cred := SessionCred()
+	return resolvePath(file, sb, path, true, &cred)
+}
+
+// FindInodeByPathNoFollow es como FindInodeByPath pero, si el último componente es un enlace
+// simbólico, devuelve el enlace en lugar de su destino (para remove, rename, move, ln).
+func FindInodeByPathNoFollow(file *os.File, sb structs.Superblock, path string) (structs.Inode, int32, error) {
+	cred := SessionCred()
+	return resolvePath(file, sb, path, false, &cred)
+}
+
+// FindInodeByPathNoCheck es como FindInodeByPath pero sin revisar permisos. Es para las
+// lecturas que hace el propio sistema (users.txt, listfs), no en nombre de un usuario.
+func FindInodeByPathNoCheck(file *os.File, sb structs.Superblock, path string) (structs.Inode, int32, error) {
+	return resolvePath(file, sb, path, true, nil)
+}
+
+// resolvePath resuelve una ruta absoluta. Si cred no es nil, cada carpeta atravesada
+// debe dar permiso de ejecución a cred.
+func resolvePath(file *os.File, sb structs.Superblock, path string, followLast bool, cred *Cred) (structs.Inode, int32, error) {
 	if !strings.HasPrefix(path, "/") {
 		return structs.Inode{}, -1, errors.New("la ruta debe ser absoluta (empezar con /)")
 	}
 
 	currentInodeIndex := int32(0) // Empezamos desde el inodo raíz (0)
 	pathParts := strings.Split(strings.Trim(path, "/"), "/")
-	if path == "/" {
-		pathParts = []string{}
-	}
+	hops := 0
 
-	for _, part := range pathParts {
+	for i := 0; i < len(pathParts); i++ {
+		part := pathParts[i]
 		if part == "" {
 			continue
 		}
@@ -32,50 +65,100 @@ func FindInodeByPath(file *os.File, sb structs.Superblock, path string) (structs
 		if inode.I_type != 0 { // 0 es para carpeta
 			return structs.Inode{}, -1, errors.New("la ruta contiene un archivo en una posición intermedia")
 		}
+		if cred != nil && !CanExec(file, sb, inode, *cred) {
+			return structs.Inode{}, -1, fmt.Errorf("permiso denegado: no se puede atravesar la carpeta que contiene '%s'", part)
+		}
+
+		nextIndex, err := lookupEntry(file, sb, inode, part)
+		if err != nil {
+			return structs.Inode{}, -1, err
+		}
+		next, err := ReadInode(file, sb, nextIndex)
+		if err != nil {
+			return structs.Inode{}, -1, err
+		}
 
-		foundNext := false
-		for _, blockPtr := range inode.I_block {
-			if blockPtr == -1 {
-				continue
+		isLast := i == len(pathParts)-1
+		if next.I_type == 2 && (!isLast || followLast) { // 2 es enlace simbólico
+			hops++
+			if hops > MaxSymlinkHops {
+				return structs.Inode{}, -1, errors.New("demasiados niveles de enlaces simbólicos en: " + path)
 			}
-			folderBlock, err := ReadFolderBlock(file, sb, blockPtr)
+			target, err := ReadSymlinkTarget(file, sb, next)
 			if err != nil {
 				return structs.Inode{}, -1, err
 			}
-			for _, entry := range folderBlock.B_content {
-				if entry.B_inodo != -1 && strings.TrimRight(string(entry.B_name[:]), "\x00") == part {
-					currentInodeIndex = entry.B_inodo
-					foundNext = true
-					break
-				}
-			}
-			if foundNext {
-				break
+			// Un destino absoluto vuelve a la raíz; uno relativo sigue desde la carpeta del enlace
+			if strings.HasPrefix(target, "/") {
+				currentInodeIndex = 0
 			}
+			rest := pathParts[i+1:]
+			pathParts = append(strings.Split(strings.Trim(target, "/"), "/"), rest...)
+			i = -1
+			continue
 		}
-		if !foundNext {
-			return structs.Inode{}, -1, errors.New("no se encontró el archivo o directorio: " + part)
-		}
+		currentInodeIndex = nextIndex
 	}
 
 	finalInode, err := ReadInode(file, sb, currentInodeIndex)
 	return finalInode, currentInodeIndex, err
 }
 
-// ReadFileContent lee todos los bloques de datos de un inodo y devuelve su contenido.
+// lookupEntry busca 'name' en los bloques de una carpeta y devuelve el inodo al que apunta.
+func lookupEntry(file *os.File, sb structs.Superblock, dir structs.Inode, name string) (int32, error) {
+	for _, blockPtr := range dir.I_block {
+		if blockPtr == -1 {
+			continue
+		}
+		folderBlock, err := ReadFolderBlock(file, sb, blockPtr)
+		if err != nil {
+			return -1, err
+		}
+		for _, entry := range folderBlock.B_content {
+			if entry.B_inodo != -1 && strings.TrimRight(string(entry.B_name[:]), "\x00") == name {
+				return entry.B_inodo, nil
+			}
+		}
+	}
+	return -1, errors.New("no se encontró el archivo o directorio: " + name)
+}
+
+// ReadSymlinkTarget devuelve la ruta guardada en el bloque de datos de un enlace simbólico.
+func ReadSymlinkTarget(file *os.File, sb structs.Superblock, inode structs.Inode) (string, error) {
+	if inode.I_type != 2 {
+		return "", errors.New("el inodo no corresponde a un enlace simbólico")
+	}
+	target, err := readContent(file, sb, inode)
+	if err != nil {
+		return "", err
+	}
+	return string(target), nil
+}
+
+// ReadFileContent lee todos los bloques de datos de un inodo (directos e indirectos) y devuelve su contenido.
 func ReadFileContent(file *os.File, sb structs.Superblock, inode structs.Inode) ([]byte, error) {
 	if inode.I_type != 1 { // 1 es para archivo
 		return nil, errors.New("el inodo no corresponde a un archivo")
 	}
+	return readContent(file, sb, inode)
+}
+
+// readContent lee los bloques de datos de un inodo hasta I_size, sin revisar su tipo.
+func readContent(file *os.File, sb structs.Superblock, inode structs.Inode) ([]byte, error) {
+	dataBlocks, _, err := InodeBlocks(file, sb, inode)
+	if err != nil {
+		return nil, err
+	}
 	var content bytes.Buffer
-	// solo leemos punteros directos
-	for i := 0; i < 12 && inode.I_block[i] != -1; i++ {
-		blockPtr := inode.I_block[i]
+	for _, blockPtr := range dataBlocks {
 		fileBlock, err := ReadFileBlock(file, sb, blockPtr)
 		if err != nil {
 			return nil, err
 		}
-		content.Write(fileBlock.B_content[:])
+		content.Write(fileBlock.B_content)
+		if int64(content.Len()) >= int64(inode.I_size) {
+			break
+		}
 	}
 	// Devolver solo la cantidad de bytes especificada por el tamaño del inodo
 	if int64(content.Len()) > int64(inode.I_size) {
@@ -84,6 +167,310 @@ func ReadFileContent(file *os.File, sb structs.Superblock, inode structs.Inode)
 	return content.Bytes(), nil
 }
 
+// WriteFileContent reemplaza el contenido de un inodo de archivo por 'data'.
+// Libera los bloques que tenía, reserva los necesarios (usando los indirectos cuando
+// no alcanzan los directos) y actualiza I_block e I_size. No escribe el inodo: eso
+// le toca a quien llama, con WriteInode.
+func WriteFileContent(file *os.File, sb structs.Superblock, inode *structs.Inode, data []byte) error {
+	if err := ReleaseInodeBlocks(file, sb, inode); err != nil {
+		return err
+	}
+
+	blockSize := int(sb.S_block_size)
+	needed := (len(data) + blockSize - 1) / blockSize
+	if int64(needed) > MaxFileBlocks(sb) {
+		return fmt.Errorf("el contenido (%d bytes) excede el tamaño máximo de archivo", len(data))
+	}
+
+	for i := 0; i < needed; i++ {
+		blockIndex, err := allocBlock(file, sb)
+		if err != nil {
+			return err
+		}
+		end := (i + 1) * blockSize
+		if end > len(data) {
+			end = len(data)
+		}
+		fb := NewFileBlock(sb)
+		copy(fb.B_content, data[i*blockSize:end])
+		if err := WriteFileBlock(file, sb, blockIndex, fb); err != nil {
+			return err
+		}
+		if err := setDataBlock(file, sb, inode, int64(i), blockIndex); err != nil {
+			return err
+		}
+	}
+	inode.I_size = int32(len(data))
+	return nil
+}
+
+// ReplaceFileContent reescribe el archivo del inodo inodeIndex de forma atómica: el
+// contenido nuevo se escribe en bloques recién asignados y solo al final se actualiza el
+// inodo para apuntar a ellos. Si falta espacio a mitad de camino, el archivo anterior
+// queda intacto.
+func ReplaceFileContent(file *os.File, sb structs.Superblock, inodeIndex int32, data []byte) error {
+	inode, err := ReadInode(file, sb, inodeIndex)
+	if err != nil {
+		return err
+	}
+
+	// Copia del inodo sin bloques: WriteFileContent no liberará los actuales
+	updated := inode
+	for i := range updated.I_block {
+		updated.I_block[i] = -1
+	}
+	if err := WriteFileContent(file, sb, &updated, data); err != nil {
+		ReleaseInodeBlocks(file, sb, &updated) // deshacer lo que alcanzó a asignar
+		return err
+	}
+	updated.I_mtime = time.Now().Unix()
+	if err := WriteInode(file, sb, inodeIndex, updated); err != nil {
+		ReleaseInodeBlocks(file, sb, &updated)
+		return err
+	}
+
+	// El inodo ya apunta al contenido nuevo; liberar los bloques viejos
+	return ReleaseInodeBlocks(file, sb, &inode)
+}
+
+// AppendFileContent agrega data al final del archivo del inodo inodeIndex: completa el
+// último bloque y reserva solo los que falten, sin reescribir el contenido anterior.
+func AppendFileContent(file *os.File, sb structs.Superblock, inodeIndex int32, data []byte) error {
+	inode, err := ReadInode(file, sb, inodeIndex)
+	if err != nil {
+		return err
+	}
+	if inode.I_type != 1 {
+		return errors.New("el inodo no corresponde a un archivo")
+	}
+	blockSize := int64(sb.S_block_size)
+	size := int64(inode.I_size)
+	if (size+int64(len(data))+blockSize-1)/blockSize > MaxFileBlocks(sb) {
+		return fmt.Errorf("el contenido (%d bytes) excede el tamaño máximo de archivo", size+int64(len(data)))
+	}
+	dataBlocks, _, err := InodeBlocks(file, sb, inode)
+	if err != nil {
+		return err
+	}
+
+	for len(data) > 0 {
+		n := size / blockSize
+		var blockIndex int32
+		var fb structs.FileBlock
+		if n < int64(len(dataBlocks)) {
+			blockIndex = dataBlocks[n]
+			if fb, err = ReadFileBlock(file, sb, blockIndex); err != nil {
+				return err
+			}
+		} else {
+			if blockIndex, err = allocBlock(file, sb); err != nil {
+				return err
+			}
+			if err := setDataBlock(file, sb, &inode, n, blockIndex); err != nil {
+				return err
+			}
+			fb = NewFileBlock(sb)
+		}
+		written := copy(fb.B_content[size%blockSize:], data)
+		if err := WriteFileBlock(file, sb, blockIndex, fb); err != nil {
+			return err
+		}
+		data = data[written:]
+		size += int64(written)
+	}
+	inode.I_size = int32(size)
+	inode.I_mtime = time.Now().Unix()
+	return WriteInode(file, sb, inodeIndex, inode)
+}
+
+// ReleaseInodeBlocks marca como libres en el bitmap todos los bloques de datos y de
+// apuntadores de un inodo y deja sus I_block en -1.
+func ReleaseInodeBlocks(file *os.File, sb structs.Superblock, inode *structs.Inode) error {
+	dataBlocks, pointerBlocks, err := InodeBlocks(file, sb, *inode)
+	if err != nil {
+		return err
+	}
+	for _, b := range append(dataBlocks, pointerBlocks...) {
+		if err := MarkBlockAsFree(file, sb, b, superblockStart(sb)); err != nil {
+			return err
+		}
+	}
+	for i := range inode.I_block {
+		inode.I_block[i] = -1
+	}
+	return nil
+}
+
+// InodeBlocks devuelve, en orden lógico, los bloques de datos de un inodo y, aparte,
+// los bloques de apuntadores que recorrió para llegar a ellos.
+// En carpetas los 15 apuntadores son directos; en archivos se siguen los indirectos.
+// Los apuntadores fuera del rango de bloques se ignoran.
+func InodeBlocks(file *os.File, sb structs.Superblock, inode structs.Inode) ([]int32, []int32, error) {
+	var dataBlocks, pointerBlocks []int32
+	if inode.I_type == 0 {
+		for _, b := range inode.I_block {
+			if validBlock(sb, b) {
+				dataBlocks = append(dataBlocks, b)
+			}
+		}
+		return dataBlocks, nil, nil
+	}
+
+	for i := 0; i < DirectPointers; i++ {
+		if validBlock(sb, inode.I_block[i]) {
+			dataBlocks = append(dataBlocks, inode.I_block[i])
+		}
+	}
+	for level := 1; level <= 3; level++ {
+		root := inode.I_block[DirectPointers+level-1]
+		if !validBlock(sb, root) {
+			continue
+		}
+		if err := collectPointerTree(file, sb, root, level, &dataBlocks, &pointerBlocks); err != nil {
+			return nil, nil, err
+		}
+	}
+	return dataBlocks, pointerBlocks, nil
+}
+
+// collectPointerTree recorre un bloque de apuntadores de nivel 'level' (1 = simple).
+func collectPointerTree(file *os.File, sb structs.Superblock, block int32, level int, dataBlocks, pointerBlocks *[]int32) error {
+	*pointerBlocks = append(*pointerBlocks, block)
+	pointers, err := ReadPointerBlock(file, sb, block)
+	if err != nil {
+		return err
+	}
+	for _, p := range pointers {
+		if !validBlock(sb, p) {
+			continue
+		}
+		if level == 1 {
+			*dataBlocks = append(*dataBlocks, p)
+			continue
+		}
+		if err := collectPointerTree(file, sb, p, level-1, dataBlocks, pointerBlocks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setDataBlock enlaza el bloque físico 'blockIndex' como bloque lógico 'n' del inodo,
+// creando los bloques de apuntadores intermedios que hagan falta.
+func setDataBlock(file *os.File, sb structs.Superblock, inode *structs.Inode, n int64, blockIndex int32) error {
+	if n < DirectPointers {
+		inode.I_block[n] = blockIndex
+		return nil
+	}
+	n -= DirectPointers
+	span := int64(PointersPerBlock(sb))
+	for level := 1; level <= 3; level++ {
+		if n < span {
+			slot := DirectPointers + level - 1
+			if inode.I_block[slot] == -1 {
+				root, err := allocPointerBlock(file, sb)
+				if err != nil {
+					return err
+				}
+				inode.I_block[slot] = root
+			}
+			return setInPointerTree(file, sb, inode.I_block[slot], level, n, blockIndex)
+		}
+		n -= span
+		span *= int64(PointersPerBlock(sb))
+	}
+	return errors.New("el archivo excede la capacidad de los apuntadores del inodo")
+}
+
+func setInPointerTree(file *os.File, sb structs.Superblock, block int32, level int, n int64, blockIndex int32) error {
+	pointers, err := ReadPointerBlock(file, sb, block)
+	if err != nil {
+		return err
+	}
+	if level == 1 {
+		pointers[n] = blockIndex
+		return WritePointerBlock(file, sb, block, pointers)
+	}
+	sub := int64(1)
+	for i := 1; i < level; i++ {
+		sub *= int64(len(pointers))
+	}
+	slot := n / sub
+	if pointers[slot] == -1 {
+		child, err := allocPointerBlock(file, sb)
+		if err != nil {
+			return err
+		}
+		pointers[slot] = child
+		if err := WritePointerBlock(file, sb, block, pointers); err != nil {
+			return err
+		}
+	}
+	return setInPointerTree(file, sb, pointers[slot], level-1, n%sub, blockIndex)
+}
+
+// MaxFileBlocks es la cantidad máxima de bloques de datos que puede direccionar un inodo de archivo.
+func MaxFileBlocks(sb structs.Superblock) int64 {
+	p := int64(PointersPerBlock(sb))
+	return DirectPointers + p + p*p + p*p*p
+}
+
+// PointersPerBlock es la cantidad de apuntadores (int32) que caben en un bloque.
+func PointersPerBlock(sb structs.Superblock) int {
+	return int(sb.S_block_size) / 4
+}
+
+// EntriesPerFolderBlock es la cantidad de ContentEntry que caben en un bloque de carpeta.
+func EntriesPerFolderBlock(sb structs.Superblock) int {
+	return int(sb.S_block_size) / structs.CONTENT_ENTRY_SIZE
+}
+
+// NewFolderBlock crea un bloque de carpeta del tamaño de la partición con todas sus entradas libres (-1).
+func NewFolderBlock(sb structs.Superblock) structs.FolderBlock {
+	fb := structs.FolderBlock{B_content: make([]structs.ContentEntry, EntriesPerFolderBlock(sb))}
+	for i := range fb.B_content {
+		fb.B_content[i].B_inodo = -1
+	}
+	return fb
+}
+
+// NewFileBlock crea un bloque de archivo vacío de S_block_size bytes.
+func NewFileBlock(sb structs.Superblock) structs.FileBlock {
+	return structs.FileBlock{B_content: make([]byte, sb.S_block_size)}
+}
+
+func validBlock(sb structs.Superblock, index int32) bool {
+	return index >= 0 && index < sb.S_blocks_count
+}
+
+func allocBlock(file *os.File, sb structs.Superblock) (int32, error) {
+	index, err := FindFreeBlock(file, sb)
+	if err != nil {
+		return -1, err
+	}
+	if err := MarkBlockAsUsed(file, sb, index); err != nil {
+		return -1, err
+	}
+	return index, nil
+}
+
+func allocPointerBlock(file *os.File, sb structs.Superblock) (int32, error) {
+	index, err := allocBlock(file, sb)
+	if err != nil {
+		return -1, err
+	}
+	pointers := make([]int32, PointersPerBlock(sb))
+	for i := range pointers {
+		pointers[i] = -1
+	}
+	return index, WritePointerBlock(file, sb, index, pointers)
+}
+
+// blockOffset es la posición absoluta del bloque 'index' en el disco.
+func blockOffset(sb structs.Superblock, index int32) int64 {
+	return int64(sb.S_block_start) + int64(index)*int64(sb.S_block_size)
+}
+
 // --- Funciones auxiliares de lectura de bajo nivel ---
 func ReadInode(file *os.File, sb structs.Superblock, index int32) (structs.Inode, error) {
 	var inode structs.Inode
@@ -93,18 +480,22 @@ func ReadInode(file *os.File, sb structs.Superblock, index int32) (structs.Inode
 	return inode, err
 }
 func ReadFileBlock(file *os.File, sb structs.Superblock, index int32) (structs.FileBlock, error) {
-	var block structs.FileBlock
-	offset := int64(sb.S_block_start) + int64(index)*int64(sb.S_block_size)
-	file.Seek(offset, 0)
-	err := binary.Read(file, binary.BigEndian, &block)
+	block := NewFileBlock(sb)
+	_, err := file.ReadAt(block.B_content, blockOffset(sb, index))
 	return block, err
 }
 func ReadFolderBlock(file *os.File, sb structs.Superblock, index int32) (structs.FolderBlock, error) {
-	var block structs.FolderBlock
-	offset := int64(sb.S_block_start) + int64(index)*int64(sb.S_block_size)
-	file.Seek(offset, 0)
-	err := binary.Read(file, binary.BigEndian, &block)
-	return block, err
+	buf := make([]byte, sb.S_block_size)
+	if _, err := file.ReadAt(buf, blockOffset(sb, index)); err != nil {
+		return structs.FolderBlock{}, err
+	}
+	block := NewFolderBlock(sb)
+	for i := range block.B_content {
+		raw := buf[i*structs.CONTENT_ENTRY_SIZE : (i+1)*structs.CONTENT_ENTRY_SIZE]
+		copy(block.B_content[i].B_name[:], raw[:structs.NAME_MAX])
+		block.B_content[i].B_inodo = int32(binary.BigEndian.Uint32(raw[structs.NAME_MAX:]))
+	}
+	return block, nil
 }
 // WriteInode guarda un inodo en disco en una posición específica
 func WriteInode(file *os.File, sb structs.Superblock, index int32, inode structs.Inode) error {
@@ -113,11 +504,12 @@ func WriteInode(file *os.File, sb structs.Superblock, index int32, inode structs
     return binary.Write(file, binary.BigEndian, &inode)
 }
 
-// WriteFileBlock guarda un bloque de archivo en disco
+// WriteFileBlock guarda un bloque de archivo en disco (se rellena o recorta a S_block_size)
 func WriteFileBlock(file *os.File, sb structs.Superblock, index int32, block structs.FileBlock) error {
-    offset := int64(sb.S_block_start) + int64(index)*int64(sb.S_block_size)
-    file.Seek(offset, 0)
-    return binary.Write(file, binary.BigEndian, &block)
+	buf := make([]byte, sb.S_block_size)
+	copy(buf, block.B_content)
+	_, err := file.WriteAt(buf, blockOffset(sb, index))
+	return err
 }
 // FindFreeBlock busca el primer bloque libre en el bitmap
 // Busca primer bloque libre (byte 0)
@@ -136,8 +528,7 @@ func FindFreeBlock(file *os.File, sb structs.Superblock) (int32, error) {
 
 // MarkBlockAsUsed marca un bloque en el bitmap como ocupado
 func MarkBlockAsUsed(file *os.File, sb structs.Superblock, index int32) error {
-    _, err := file.WriteAt([]byte{1}, int64(sb.S_bm_block_start)+int64(index))
-    return err
+	return setBitmap(file, sb, false, index, 1, superblockStart(sb))
 }
 
 //func MarkBlockAsFree(file *os.File, sb structs.Superblock, index int32) error {
@@ -159,15 +550,22 @@ func FindFreeInode(file *os.File, sb structs.Superblock) (int32, error) {
 }
 
 func MarkInodeAsUsed(file *os.File, sb structs.Superblock, index int32) error {
-    _, err := file.WriteAt([]byte{1}, int64(sb.S_bm_inode_start)+int64(index))
-    return err
+	return setBitmap(file, sb, true, index, 1, superblockStart(sb))
 }
 
+// WriteFolderBlock guarda un bloque de carpeta; las entradas que no quepan en S_block_size se descartan.
 func WriteFolderBlock(file *os.File, sb structs.Superblock, blockIndex int32, fb structs.FolderBlock) error {
-    blockSize := sb.S_block_size
-    offset := int64(sb.S_block_start) + int64(blockIndex)*int64(blockSize)
-    file.Seek(offset, 0)
-    return binary.Write(file, binary.BigEndian, &fb)
+	buf := make([]byte, sb.S_block_size)
+	for i, entry := range fb.B_content {
+		if i >= EntriesPerFolderBlock(sb) {
+			break
+		}
+		raw := buf[i*structs.CONTENT_ENTRY_SIZE : (i+1)*structs.CONTENT_ENTRY_SIZE]
+		copy(raw[:structs.NAME_MAX], entry.B_name[:])
+		binary.BigEndian.PutUint32(raw[structs.NAME_MAX:], uint32(entry.B_inodo))
+	}
+	_, err := file.WriteAt(buf, blockOffset(sb, blockIndex))
+	return err
 }
 
 func ReadPointerBlock(file *os.File, sb structs.Superblock, index int32) ([]int32, error) {
@@ -191,26 +589,99 @@ func ReadPointerBlock(file *os.File, sb structs.Superblock, index int32) ([]int3
     return pointers, nil
 }
 
+// WritePointerBlock guarda un bloque de apuntadores (int32 big endian).
+func WritePointerBlock(file *os.File, sb structs.Superblock, index int32, pointers []int32) error {
+	buf := make([]byte, sb.S_block_size)
+	for i, p := range pointers {
+		if (i+1)*4 > len(buf) {
+			break
+		}
+		binary.BigEndian.PutUint32(buf[i*4:], uint32(p))
+	}
+	_, err := file.WriteAt(buf, blockOffset(sb, index))
+	return err
+}
+
 // MarkInodeAsFree marca un inodo como libre en el bitmap de inodos.
-func MarkInodeAsFree(file *os.File, sb structs.Superblock, inodeIndex int32, sbStart int64) {
-	bitmapPos := int64(sb.S_bm_inode_start) + int64(inodeIndex)
-	file.Seek(bitmapPos, 0)
-	file.Write([]byte{0}) // 0 = libre
-	sb.S_free_inodes_count++
-	UpdateSuperblock(file, sb, sbStart)
+func MarkInodeAsFree(file *os.File, sb structs.Superblock, inodeIndex int32, sbStart int64) error {
+	return setBitmap(file, sb, true, inodeIndex, 0, sbStart)
 }
 
 // MarkBlockAsFree marca un bloque como libre en el bitmap de bloques.
-func MarkBlockAsFree(file *os.File, sb structs.Superblock, blockIndex int32, sbStart int64) {
-	bitmapPos := int64(sb.S_bm_block_start) + int64(blockIndex)
-	file.Seek(bitmapPos, 0)
-	file.Write([]byte{0}) // 0 = libre
-	sb.S_free_blocks_count++
-	UpdateSuperblock(file, sb, sbStart)
+func MarkBlockAsFree(file *os.File, sb structs.Superblock, blockIndex int32, sbStart int64) error {
+	return setBitmap(file, sb, false, blockIndex, 0, sbStart)
+}
+
+// setBitmap escribe value (1 ocupado, 0 libre) en el bitmap de inodos o de bloques y, si el
+// byte cambió, ajusta S_free_inodes_count o S_free_blocks_count. El contador se lee y se
+// escribe en el disco y no en sb: los comandos reservan varias veces con la misma copia
+// del superbloque, y reescribirla entera pisaría lo que contaron las llamadas anteriores.
+func setBitmap(file *os.File, sb structs.Superblock, inodes bool, index int32, value byte, sbStart int64) error {
+	pos := int64(sb.S_bm_block_start) + int64(index)
+	if inodes {
+		pos = int64(sb.S_bm_inode_start) + int64(index)
+	}
+	old := make([]byte, 1)
+	if _, err := file.ReadAt(old, pos); err != nil {
+		return err
+	}
+	if old[0] == value {
+		return nil
+	}
+	if _, err := file.WriteAt([]byte{value}, pos); err != nil {
+		return err
+	}
+
+	var current structs.Superblock
+	if err := binary.Read(io.NewSectionReader(file, sbStart, int64(binary.Size(current))), binary.BigEndian, &current); err != nil {
+		return err
+	}
+	delta := int32(1) // se liberó
+	if value != 0 {
+		delta = -1
+	}
+	if inodes {
+		current.S_free_inodes_count += delta
+	} else {
+		current.S_free_blocks_count += delta
+	}
+	UpdateSuperblock(file, current, sbStart)
+	return nil
+}
+
+// superblockStart devuelve dónde empieza el superbloque: mkfs lo deja justo antes del
+// journal en EXT3 y del bitmap de inodos en EXT2.
+func superblockStart(sb structs.Superblock) int64 {
+	next := sb.S_bm_inode_start
+	if sb.S_filesystem_type == 3 {
+		next = sb.S_journal_start
+	}
+	return int64(next) - int64(binary.Size(structs.Superblock{}))
 }
 
 // UpdateSuperblock reescribe el superbloque actualizado en disco.
 func UpdateSuperblock(file *os.File, sb structs.Superblock, sbStart int64) {
 	file.Seek(sbStart, 0)
 	binary.Write(file, binary.BigEndian, &sb)
-}
\ No newline at end of file
+}
+
+// zeroChunkSize es el tamaño del buffer usado por WriteZeros (1 MB).
+const zeroChunkSize = 1024 * 1024
+
+// WriteZeros escribe 'length' bytes en cero a partir de 'offset', en trozos de
+// hasta 1 MB para no reservar toda el área en memoria ni hacer una escritura por estructura.
+func WriteZeros(file *os.File, offset, length int64) error {
+	zero := make([]byte, zeroChunkSize)
+	written := int64(0)
+	for written < length {
+		toWrite := int64(zeroChunkSize)
+		if rem := length - written; rem < toWrite {
+			toWrite = rem
+		}
+		if _, err := file.WriteAt(zero[:toWrite], offset+written); err != nil {
+			return err
+		}
+		written += toWrite
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package fs
+
+import (
+	"encoding/binary"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"proyecto1/fs/fstest"
+	"proyecto1/structs"
+)
+
+// newTestInode reserva un inodo de root del tipo indicado, sin bloques.
+func newTestInode(t *testing.T, file *os.File, sb structs.Superblock, typ int32, perm Perm) int32 {
+	t.Helper()
+	index, err := FindFreeInode(file, sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := MarkInodeAsUsed(file, sb, index); err != nil {
+		t.Fatal(err)
+	}
+	inode := structs.Inode{I_uid: RootUID, I_gid: RootUID, I_type: typ, I_links: 1, I_acl: -1}
+	SetPerm(&inode, perm)
+	for i := range inode.I_block {
+		inode.I_block[i] = -1
+	}
+	if err := WriteInode(file, sb, index, inode); err != nil {
+		t.Fatal(err)
+	}
+	return index
+}
+
+// addTestEntry agrega name -> child a la carpeta dir, usando un bloque nuevo si los
+// que tiene están llenos.
+func addTestEntry(t *testing.T, file *os.File, sb structs.Superblock, dir int32, name string, child int32) {
+	t.Helper()
+	inode, err := ReadInode(file, sb, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for slot, blockIndex := range inode.I_block {
+		if blockIndex == -1 {
+			if blockIndex, err = allocBlock(file, sb); err != nil {
+				t.Fatal(err)
+			}
+			WriteFolderBlock(file, sb, blockIndex, NewFolderBlock(sb))
+			inode.I_block[slot] = blockIndex
+			WriteInode(file, sb, dir, inode)
+		}
+		fb, err := ReadFolderBlock(file, sb, blockIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range fb.B_content {
+			if fb.B_content[i].B_inodo == -1 {
+				copy(fb.B_content[i].B_name[:], name)
+				fb.B_content[i].B_inodo = child
+				WriteFolderBlock(file, sb, blockIndex, fb)
+				return
+			}
+		}
+	}
+	t.Fatalf("la carpeta %d no tiene lugar para '%s'", dir, name)
+}
+
+func mkTestDir(t *testing.T, file *os.File, sb structs.Superblock, parent int32, name string) int32 {
+	t.Helper()
+	index := newTestInode(t, file, sb, 0, DefaultDirPerm)
+	addTestEntry(t, file, sb, index, ".", index)
+	addTestEntry(t, file, sb, index, "..", parent)
+	addTestEntry(t, file, sb, parent, name, index)
+	return index
+}
+
+func mkTestFile(t *testing.T, file *os.File, sb structs.Superblock, parent int32, name, content string) int32 {
+	t.Helper()
+	index := newTestInode(t, file, sb, 1, DefaultFilePerm)
+	if content != "" {
+		if err := AppendFileContent(file, sb, index, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addTestEntry(t, file, sb, parent, name, index)
+	return index
+}
+
+func mkTestSymlink(t *testing.T, file *os.File, sb structs.Superblock, parent int32, name, target string) int32 {
+	t.Helper()
+	index := newTestInode(t, file, sb, 2, DefaultSymlinkPerm)
+	inode, _ := ReadInode(file, sb, index)
+	if err := WriteFileContent(file, sb, &inode, []byte(target)); err != nil {
+		t.Fatal(err)
+	}
+	WriteInode(file, sb, index, inode)
+	addTestEntry(t, file, sb, parent, name, index)
+	return index
+}
+
+func TestResolveSymlinks(t *testing.T) {
+	file, sb := fstest.NewImage(t, 128)
+	docs := mkTestDir(t, file, sb, 0, "docs")
+	target := mkTestFile(t, file, sb, docs, "a.txt", "hola")
+	mkTestSymlink(t, file, sb, docs, "rel", "a.txt")
+	mkTestSymlink(t, file, sb, 0, "abs", "/docs/a.txt")
+	mkTestSymlink(t, file, sb, 0, "dir", "docs")
+	mkTestSymlink(t, file, sb, 0, "loop", "/loop")
+	mkTestSymlink(t, file, sb, 0, "ping", "pong")
+	mkTestSymlink(t, file, sb, 0, "pong", "ping")
+	mkTestSymlink(t, file, sb, 0, "dangling", "/no/existe")
+
+	// Cadena /c/<i> -> <i-1> que termina en /c/0 -> /docs/a.txt: /c/<i> da i+1 saltos,
+	// así que /c/<MaxSymlinkHops-1> es el último que se resuelve.
+	chain := mkTestDir(t, file, sb, 0, "c")
+	mkTestSymlink(t, file, sb, chain, "0", "/docs/a.txt")
+	for i := 1; i <= MaxSymlinkHops; i++ {
+		mkTestSymlink(t, file, sb, chain, strconv.Itoa(i), strconv.Itoa(i-1))
+	}
+
+	tests := []struct {
+		path    string
+		want    int32
+		wantErr string
+	}{
+		{path: "/docs/a.txt", want: target},
+		{path: "/docs/rel", want: target},
+		{path: "/abs", want: target},
+		{path: "/dir/a.txt", want: target},
+		{path: "/dir/rel", want: target},
+		{path: "/c/" + strconv.Itoa(MaxSymlinkHops-1), want: target},
+		{path: "/c/" + strconv.Itoa(MaxSymlinkHops), wantErr: "demasiados niveles"},
+		{path: "/loop", wantErr: "demasiados niveles"},
+		{path: "/ping", wantErr: "demasiados niveles"},
+		{path: "/dangling", wantErr: "no se encontró"},
+		{path: "/abs/x", wantErr: "archivo en una posición intermedia"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, index, err := FindInodeByPathNoCheck(file, sb, tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, se esperaba %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if index != tt.want {
+				t.Errorf("inodo = %d, se esperaba %d", index, tt.want)
+			}
+		})
+	}
+
+	// Sin seguir el último componente, un ciclo devuelve el propio enlace
+	inode, _, err := resolvePath(file, sb, "/loop", false, nil)
+	if err != nil || inode.I_type != 2 {
+		t.Errorf("NoFollow de /loop = tipo %d, %v; se esperaba el enlace", inode.I_type, err)
+	}
+}
+
+func TestAllocKeepsFreeCounters(t *testing.T) {
+	file, sb := fstest.NewImage(t, 64)
+	dir := mkTestDir(t, file, sb, 0, "d")
+	f := mkTestFile(t, file, sb, dir, "f.txt", strings.Repeat("x", 20*int(sb.S_block_size)))
+	mkTestSymlink(t, file, sb, 0, "s", "/d/f.txt")
+
+	inode, _ := ReadInode(file, sb, f)
+	if err := WriteACL(file, sb, &inode, []ACLEntry{{Tag: ACLUser, ID: 2, Perm: 7}}); err != nil {
+		t.Fatal(err)
+	}
+	WriteInode(file, sb, f, inode)
+	if err := ReplaceFileContent(file, sb, f, []byte("corto")); err != nil {
+		t.Fatal(err)
+	}
+	inode, _ = ReadInode(file, sb, f)
+	if err := ReleaseACL(file, sb, &inode); err != nil {
+		t.Fatal(err)
+	}
+	MarkBlockAsFree(file, sb, inode.I_block[0], 0)
+	MarkBlockAsFree(file, sb, inode.I_block[0], 0) // liberar dos veces no descuenta de más
+
+	var disk structs.Superblock
+	file.Seek(0, 0)
+	if err := binary.Read(file, binary.BigEndian, &disk); err != nil {
+		t.Fatal(err)
+	}
+	freeInodes, freeBlocks := countFreeBytes(t, file, sb.S_bm_inode_start, sb.S_inodes_count),
+		countFreeBytes(t, file, sb.S_bm_block_start, sb.S_blocks_count)
+	if disk.S_free_inodes_count != freeInodes || disk.S_free_blocks_count != freeBlocks {
+		t.Errorf("superbloque: %d inodos y %d bloques libres; bitmaps: %d y %d",
+			disk.S_free_inodes_count, disk.S_free_blocks_count, freeInodes, freeBlocks)
+	}
+}
+
+func TestAppendFileContent(t *testing.T) {
+	file, sb := fstest.NewImage(t, 64)
+	// Más que los bloques directos para pasar por el indirecto simple
+	var want strings.Builder
+	index := mkTestFile(t, file, sb, 0, "log", "")
+	for i := 0; want.Len() < 14*int(sb.S_block_size); i++ {
+		line := "evento " + strconv.Itoa(i) + "\n"
+		if err := AppendFileContent(file, sb, index, []byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		want.WriteString(line)
+	}
+	inode, _ := ReadInode(file, sb, index)
+	got, err := ReadFileContent(file, sb, inode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want.String() {
+		t.Errorf("contenido de %d bytes, se esperaban %d", len(got), want.Len())
+	}
+}
+
+func countFreeBytes(t *testing.T, file *os.File, start, count int32) int32 {
+	t.Helper()
+	bitmap := make([]byte, count)
+	if _, err := file.ReadAt(bitmap, int64(start)); err != nil {
+		t.Fatal(err)
+	}
+	free := int32(0)
+	for _, b := range bitmap {
+		if b == 0 {
+			free++
+		}
+	}
+	return free
+}
@@ -0,0 +1,84 @@
+// Package fstest arma imágenes EXT2 mínimas en archivos temporales para los tests de
+// fs y de los paquetes que leen el sistema de archivos (users, audit, ...).
+//
+// Solo depende de structs: los tests internos de fs también lo usan, y si importara fs
+// se formaría un ciclo.
+package fstest
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"proyecto1/structs"
+)
+
+// NewImage crea en un archivo temporal un EXT2 con la misma distribución que mkfs
+// (superbloque en el byte 0, bitmaps, tabla de inodos y de bloques) con inodes inodos,
+// el triple de bloques de structs.FILE_BLOCK_SIZE y solo la carpeta raíz: inodo 0 de
+// root con permisos 775 y el bloque 0 con "." y "..". El archivo se cierra al terminar
+// el test.
+func NewImage(t testing.TB, inodes int32) (*os.File, structs.Superblock) {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "*.mia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	sb := structs.Superblock{
+		S_filesystem_type:   2,
+		S_inodes_count:      inodes,
+		S_blocks_count:      3 * inodes,
+		S_free_inodes_count: inodes - 1,
+		S_free_blocks_count: 3*inodes - 1,
+		S_magic:             0xEF53,
+		S_inode_size:        int32(binary.Size(structs.Inode{})),
+		S_block_size:        structs.FILE_BLOCK_SIZE,
+		S_first_ino:         1,
+		S_first_blo:         1,
+	}
+	sb.S_bm_inode_start = int32(binary.Size(sb))
+	sb.S_bm_block_start = sb.S_bm_inode_start + sb.S_inodes_count
+	sb.S_inode_start = sb.S_bm_block_start + sb.S_blocks_count
+	sb.S_block_start = sb.S_inode_start + sb.S_inodes_count*sb.S_inode_size
+	if err := file.Truncate(int64(sb.S_block_start) + int64(sb.S_blocks_count)*int64(sb.S_block_size)); err != nil {
+		t.Fatal(err)
+	}
+
+	root := structs.Inode{I_uid: 1, I_gid: 1, I_size: sb.S_block_size, I_type: 0, I_perm: 775, I_links: 1, I_acl: -1}
+	for i := range root.I_block {
+		root.I_block[i] = -1
+	}
+	root.I_block[0] = 0
+
+	// Bloque de carpeta: entradas de NAME_MAX bytes de nombre y 4 de inodo (-1 libre)
+	folder := make([]byte, sb.S_block_size)
+	for i := 0; (i+1)*structs.CONTENT_ENTRY_SIZE <= len(folder); i++ {
+		binary.BigEndian.PutUint32(folder[i*structs.CONTENT_ENTRY_SIZE+structs.NAME_MAX:], ^uint32(0))
+	}
+	for i, name := range []string{".", ".."} {
+		copy(folder[i*structs.CONTENT_ENTRY_SIZE:], name)
+		binary.BigEndian.PutUint32(folder[i*structs.CONTENT_ENTRY_SIZE+structs.NAME_MAX:], 0)
+	}
+
+	writes := []struct {
+		offset int32
+		data   interface{}
+	}{
+		{0, &sb},
+		{sb.S_bm_inode_start, []byte{1}},
+		{sb.S_bm_block_start, []byte{1}},
+		{sb.S_inode_start, &root},
+		{sb.S_block_start, folder},
+	}
+	for _, w := range writes {
+		if _, err := file.Seek(int64(w.offset), 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := binary.Write(file, binary.BigEndian, w.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return file, sb
+}
@@ -0,0 +1,36 @@
+package fs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"proyecto1/structs"
+)
+
+// ErrOldLayout indica un sistema de archivos formateado con estructuras de otro tamaño.
+// Los inodos crecieron con I_links e I_acl y las entradas del journal con IUser: leer un
+// disco viejo con las estructuras actuales mezcla cada inodo con el comienzo del siguiente
+// y, en EXT3, corre el journal. No se migra porque cada área empieza justo donde termina la
+// anterior y no hay lugar para agrandarlas; hay que volver a formatear.
+var ErrOldLayout = errors.New("el sistema de archivos se formateó con una versión anterior; vuelve a formatear la partición con mkfs")
+
+// CheckLayout verifica que un sistema de archivos use el tamaño de inodo y, en EXT3, de
+// entrada de journal actuales. Un superbloque sin S_magic (partición sin formatear) no se
+// revisa: cada comando ya decide qué hacer en ese caso.
+func CheckLayout(sb structs.Superblock) error {
+	if sb.S_magic != 0xEF53 {
+		return nil
+	}
+	if want := int32(binary.Size(structs.Inode{})); sb.S_inode_size != want {
+		return fmt.Errorf("%w (inodos de %d bytes, se esperan %d)", ErrOldLayout, sb.S_inode_size, want)
+	}
+	if sb.S_filesystem_type == 3 && sb.S_inodes_count > 0 {
+		// mkfs deja una entrada por inodo entre el journal y el bitmap de inodos
+		entry := (int64(sb.S_bm_inode_start) - int64(sb.S_journal_start)) / int64(sb.S_inodes_count)
+		if want := int64(binary.Size(structs.JournalEntry{})); entry != want {
+			return fmt.Errorf("%w (entradas de journal de %d bytes, se esperan %d)", ErrOldLayout, entry, want)
+		}
+	}
+	return nil
+}
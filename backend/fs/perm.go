@@ -0,0 +1,303 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"proyecto1/state"
+	"proyecto1/structs"
+)
+
+// Perm son los permisos de un inodo como bits octales (0o664 = rw-rw-r--). Un cuarto
+// dígito a la izquierda lleva los bits especiales de carpeta (0o1777, 0o2775).
+//
+// En disco I_perm se guarda en formato UGO: un número decimal cuyos dígitos son los
+// dígitos octales (664). PermOf y SetPerm son los únicos lugares que convierten entre
+// ambos formatos; el resto del código trabaja siempre con Perm.
+type Perm uint16
+
+// Bits de cada clase (propietario, grupo, otros).
+const (
+	PermRead  Perm = 4
+	PermWrite Perm = 2
+	PermExec  Perm = 1
+)
+
+// Bits especiales. Solo tienen efecto en carpetas: con PermSticky solo el propietario de
+// una entrada (o de la carpeta, o root) puede borrarla o renombrarla; con PermSetgid las
+// entradas nuevas heredan el grupo de la carpeta y las subcarpetas heredan el bit.
+const (
+	PermSetgid Perm = 0o2000
+	PermSticky Perm = 0o1000
+)
+
+// Permisos con los que se crean los inodos nuevos. Las carpetas llevan x para que
+// se puedan atravesar. mkdir y mkfile parten de FileBasePerm/DirBasePerm y les quitan
+// la umask del usuario; con la umask por defecto (002) quedan igual que Default*.
+const (
+	DefaultFilePerm    Perm = 0o664
+	DefaultDirPerm     Perm = 0o775
+	DefaultSymlinkPerm Perm = 0o777
+
+	FileBasePerm Perm = 0o666
+	DirBasePerm  Perm = 0o777
+	DefaultUmask Perm = 0o002
+)
+
+// RootUID es el UID del usuario root que crea mkfs. root no pasa por las revisiones de permisos.
+const RootUID int32 = 1
+
+// PermOf decodifica el I_perm de un inodo.
+func PermOf(inode structs.Inode) Perm {
+	var p Perm
+	digits := inode.I_perm
+	for shift := 0; digits > 0 && shift < 12; shift += 3 {
+		d := digits % 10
+		if d > 7 {
+			d = 7 // valor inválido en disco: se satura en lugar de mezclar bits
+		}
+		p |= Perm(d) << shift
+		digits /= 10
+	}
+	return p
+}
+
+// SetPerm guarda p en el I_perm del inodo (en formato UGO).
+func SetPerm(inode *structs.Inode, p Perm) {
+	v, _ := strconv.Atoi(p.Octal())
+	inode.I_perm = int32(v)
+}
+
+// ParsePerm interpreta permisos escritos en octal, como "664", "0755" o "1777". El
+// primer dígito de cuatro solo admite los bits especiales (1 sticky, 2 setgid).
+func ParsePerm(s string) (Perm, error) {
+	if len(s) < 3 || len(s) > 4 {
+		return 0, fmt.Errorf("permisos inválidos '%s': se esperan 3 dígitos octales (U,G,O)", s)
+	}
+	v, err := strconv.ParseUint(s, 8, 16)
+	if err != nil {
+		return 0, fmt.Errorf("permisos inválidos '%s': cada dígito debe estar entre 0 y 7", s)
+	}
+	if v > 0o3777 {
+		return 0, fmt.Errorf("permisos inválidos '%s': setuid no está soportado", s)
+	}
+	return Perm(v), nil
+}
+
+// NewPerm devuelve los permisos de un inodo nuevo aplicando la umask.
+func NewPerm(isDir bool, umask Perm) Perm {
+	if isDir {
+		return DirBasePerm &^ umask
+	}
+	return FileBasePerm &^ umask
+}
+
+// ParseMode interpreta un modo de chmod y lo aplica sobre los permisos actuales.
+// Acepta octal ("750") o simbólico separado por comas ("u+rwx,g-w,o=r"): cada cláusula
+// es [ugoa]*[+-=][rwxX]*; sin clases aplica a todas. X da ejecución solo a carpetas o
+// a archivos que ya tengan alguna x; s (con g) es setgid y t (con o) es sticky.
+// Como en GNU chmod, un octal de 3 dígitos conserva los bits especiales de las carpetas:
+// para quitarlos hay que usar 4 dígitos (0755) o g-s / o-t.
+func ParseMode(mode string, current Perm, isDir bool) (Perm, error) {
+	if mode != "" && mode[0] >= '0' && mode[0] <= '7' {
+		p, err := ParsePerm(mode)
+		if err == nil && len(mode) == 3 && isDir {
+			p |= current & (PermSetgid | PermSticky)
+		}
+		return p, err
+	}
+
+	p := current
+	for _, clause := range strings.Split(mode, ",") {
+		i := 0
+		var who Perm
+		for ; i < len(clause) && strings.IndexByte("ugoa", clause[i]) >= 0; i++ {
+			switch clause[i] {
+			case 'u':
+				who |= 0o700
+			case 'g':
+				who |= 0o070
+			case 'o':
+				who |= 0o007
+			case 'a':
+				who |= 0o777
+			}
+		}
+		if who == 0 {
+			who = 0o777
+		}
+		if i >= len(clause) || strings.IndexByte("+-=", clause[i]) < 0 {
+			return 0, fmt.Errorf("modo inválido '%s': se espera +, - o = en '%s'", mode, clause)
+		}
+		op := clause[i]
+
+		var bits Perm // en las tres clases; luego se recorta con who
+		for _, c := range clause[i+1:] {
+			switch c {
+			case 'r':
+				bits |= 0o444
+			case 'w':
+				bits |= 0o222
+			case 'x':
+				bits |= 0o111
+			case 'X':
+				if isDir || p&0o111 != 0 {
+					bits |= 0o111
+				}
+			case 's':
+				if who&0o070 != 0 {
+					bits |= PermSetgid
+				}
+			case 't':
+				if who&0o007 != 0 {
+					bits |= PermSticky
+				}
+			default:
+				return 0, fmt.Errorf("modo inválido '%s': permiso '%c' desconocido", mode, c)
+			}
+		}
+		special := bits & (PermSetgid | PermSticky)
+		bits = bits&who | special
+
+		switch op {
+		case '+':
+			p |= bits
+		case '-':
+			p &^= bits
+		case '=':
+			p = p&^who | bits // los bits especiales solo cambian si se nombran
+		}
+	}
+	return p, nil
+}
+
+// Octal devuelve los permisos como dígitos octales, ej. "664" o "1777".
+func (p Perm) Octal() string {
+	return fmt.Sprintf("%03o", uint16(p))
+}
+
+// String devuelve los permisos al estilo de ls -l, ej. "rw-rw-r--". setgid y sticky
+// se muestran en la x del grupo y de otros: s/t si también hay x, S/T si no.
+func (p Perm) String() string {
+	const letters = "rwx"
+	out := make([]byte, 9)
+	for i := 0; i < 9; i++ {
+		if p&(1<<uint(8-i)) != 0 {
+			out[i] = letters[i%3]
+		} else {
+			out[i] = '-'
+		}
+	}
+	if p&PermSetgid != 0 {
+		out[5] = "Ss"[p>>3&1]
+	}
+	if p&PermSticky != 0 {
+		out[8] = "Tt"[p&1]
+	}
+	return string(out)
+}
+
+// SessionUmask devuelve la umask del usuario con sesión iniciada (se carga al
+// iniciar sesión); sin sesión devuelve DefaultUmask.
+func SessionUmask() Perm {
+	if !state.CurrentSession.IsActive {
+		return DefaultUmask
+	}
+	return Perm(state.CurrentSession.Umask) & 0o777
+}
+
+// Cred identifica a quién se le revisan los permisos.
+type Cred struct {
+	UID    int32
+	GID    int32   // grupo principal
+	Groups []int32 // grupos suplementarios
+}
+
+// IsRoot indica si la credencial es la de root.
+func (c Cred) IsRoot() bool { return c.UID == RootUID }
+
+// InGroup indica si la credencial pertenece al grupo gid, por su grupo principal o
+// por uno suplementario.
+func (c Cred) InGroup(gid int32) bool {
+	if c.GID == gid {
+		return true
+	}
+	for _, g := range c.Groups {
+		if g == gid {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionCred devuelve la credencial del usuario con sesión iniciada. Sin sesión
+// devuelve una que no es dueña ni miembro de nada (solo tiene los permisos de otros);
+// las lecturas internas que necesitan saltarse los permisos (users.txt, /.audit) usan
+// FindInodeByPathNoCheck.
+func SessionCred() Cred {
+	s := state.CurrentSession
+	if !s.IsActive {
+		return Cred{UID: -1, GID: -1}
+	}
+	return Cred{UID: s.UID, GID: s.GID, Groups: s.Groups}
+}
+
+// Allowed indica si c tiene todos los bits de want (combinación de PermRead,
+// PermWrite y PermExec) sobre el inodo. Se usa la clase del propietario, si no la
+// del grupo y si no la de otros, igual que en Linux. Solo mira I_perm: para tener en
+// cuenta la ACL se usa Access.
+func Allowed(inode structs.Inode, c Cred, want Perm) bool {
+	if c.IsRoot() {
+		return true
+	}
+	p := PermOf(inode)
+	var class Perm
+	switch {
+	case inode.I_uid == c.UID:
+		class = (p >> 6) & 7
+	case c.InGroup(inode.I_gid):
+		class = (p >> 3) & 7
+	default:
+		class = p & 7
+	}
+	return class&want == want
+}
+
+// StickyDenies indica si el sticky bit de la carpeta dir le impide a c borrar o
+// renombrar su entrada child: solo pueden hacerlo el dueño de child, el de dir o root.
+func StickyDenies(dir, child structs.Inode, c Cred) bool {
+	if PermOf(dir)&PermSticky == 0 || c.IsRoot() {
+		return false
+	}
+	return child.I_uid != c.UID && dir.I_uid != c.UID
+}
+
+// InheritSetgid aplica el setgid de la carpeta parent a un inodo nuevo: el inodo toma
+// el grupo de la carpeta y, si es carpeta, también el bit. Se llama después de
+// asignarle el grupo y los permisos iniciales.
+func InheritSetgid(parent structs.Inode, child *structs.Inode) {
+	if PermOf(parent)&PermSetgid == 0 {
+		return
+	}
+	child.I_gid = parent.I_gid
+	if child.I_type == 0 {
+		SetPerm(child, PermOf(*child)|PermSetgid)
+	}
+}
+
+// CanRead indica si c puede leer el inodo (listar, en carpetas). Consulta la ACL.
+func CanRead(file *os.File, sb structs.Superblock, inode structs.Inode, c Cred) bool {
+	return Access(file, sb, inode, c, PermRead)
+}
+
+// CanWrite indica si c puede escribir el inodo (crear o borrar entradas, en carpetas).
+func CanWrite(file *os.File, sb structs.Superblock, inode structs.Inode, c Cred) bool {
+	return Access(file, sb, inode, c, PermWrite)
+}
+
+// CanExec indica si c puede ejecutar el inodo o, si es carpeta, atravesarla.
+func CanExec(file *os.File, sb structs.Superblock, inode structs.Inode, c Cred) bool {
+	return Access(file, sb, inode, c, PermExec)
+}
@@ -0,0 +1,157 @@
+package fs
+
+import (
+	"testing"
+
+	"proyecto1/fs/fstest"
+	"proyecto1/structs"
+)
+
+func TestParsePerm(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Perm
+		wantErr bool
+	}{
+		{in: "664", want: 0o664},
+		{in: "000", want: 0},
+		{in: "0755", want: 0o755},
+		{in: "1777", want: 0o1777},
+		{in: "2775", want: 0o2775},
+		{in: "3770", want: 0o3770},
+		{in: "4755", wantErr: true}, // setuid
+		{in: "64", wantErr: true},
+		{in: "77777", wantErr: true},
+		{in: "648", wantErr: true},
+		{in: "rwx", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePerm(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePerm(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParsePerm(%q) = %o, se esperaba %o", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		current Perm
+		isDir   bool
+		want    Perm
+		wantErr bool
+	}{
+		{mode: "750", current: 0o644, want: 0o750},
+		{mode: "u+x", current: 0o644, want: 0o744},
+		{mode: "+x", current: 0o644, want: 0o755},
+		{mode: "a-w", current: 0o664, want: 0o444},
+		{mode: "g=r,o=", current: 0o777, want: 0o740},
+		{mode: "u=rwx,g-w,o=r", current: 0o666, want: 0o744},
+		{mode: "go-rwx", current: 0o775, want: 0o700},
+		// X solo da ejecución a carpetas o a archivos que ya tienen alguna x
+		{mode: "a+X", current: 0o644, want: 0o644},
+		{mode: "a+X", current: 0o744, want: 0o755},
+		{mode: "a+X", current: 0o644, isDir: true, want: 0o755},
+		// bits especiales
+		{mode: "g+s", current: 0o775, isDir: true, want: 0o2775},
+		{mode: "o+t", current: 0o777, isDir: true, want: 0o1777},
+		{mode: "u+s", current: 0o775, isDir: true, want: 0o775}, // s solo cuenta con g
+		{mode: "g-s", current: 0o2775, isDir: true, want: 0o775},
+		{mode: "u=rwx", current: 0o2755, isDir: true, want: 0o2755}, // = no toca lo especial
+		// un octal de 3 dígitos conserva setgid y sticky en carpetas; de 4 los reemplaza
+		{mode: "755", current: 0o3777, isDir: true, want: 0o3755},
+		{mode: "0755", current: 0o3777, isDir: true, want: 0o755},
+		{mode: "755", current: 0o3777, want: 0o755},
+		{mode: "u", current: 0o644, wantErr: true},
+		{mode: "u+z", current: 0o644, wantErr: true},
+		{mode: "x+r", current: 0o644, wantErr: true},
+		{mode: "999", current: 0o644, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMode(tt.mode, tt.current, tt.isDir)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMode(%q, %o, %v) error = %v, wantErr %v", tt.mode, tt.current, tt.isDir, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseMode(%q, %o, %v) = %o, se esperaba %o", tt.mode, tt.current, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestAccess(t *testing.T) {
+	file, sb := fstest.NewImage(t, 16)
+
+	// Archivo 640 de uid 2 y gid 10, con y sin ACL
+	plain := structs.Inode{I_uid: 2, I_gid: 10, I_acl: -1}
+	SetPerm(&plain, 0o640)
+	withACL := plain
+	if err := WriteACL(file, sb, &withACL, []ACLEntry{
+		{Tag: ACLUser, ID: 3, Perm: PermRead | PermWrite},
+		{Tag: ACLUser, ID: 4, Perm: PermRead | PermWrite | PermExec},
+		{Tag: ACLGroup, ID: 20, Perm: PermRead | PermWrite},
+		{Tag: ACLMask, Perm: PermRead | PermWrite},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		root    = Cred{UID: RootUID, GID: RootUID}
+		owner   = Cred{UID: 2, GID: 99}
+		member  = Cred{UID: 5, GID: 10}
+		suppl   = Cred{UID: 6, GID: 99, Groups: []int32{10}}
+		named   = Cred{UID: 3, GID: 99}
+		masked  = Cred{UID: 4, GID: 99}
+		group20 = Cred{UID: 7, GID: 20}
+		other   = Cred{UID: 8, GID: 99}
+		nobody  = Cred{UID: -1, GID: -1} // SessionCred sin sesión
+	)
+	tests := []struct {
+		name  string
+		inode structs.Inode
+		cred  Cred
+		want  Perm
+		ok    bool
+	}{
+		{"root lee", plain, root, PermRead | PermWrite | PermExec, true},
+		{"dueño escribe", plain, owner, PermWrite, true},
+		{"dueño no ejecuta", plain, owner, PermExec, false},
+		{"grupo lee", plain, member, PermRead, true},
+		{"grupo no escribe", plain, member, PermWrite, false},
+		{"grupo suplementario lee", plain, suppl, PermRead, true},
+		{"otros no leen", plain, other, PermRead, false},
+		{"sin sesión no lee", plain, nobody, PermRead, false},
+		{"ACL usuario con nombre", withACL, named, PermRead | PermWrite, true},
+		{"ACL la máscara limita", withACL, masked, PermExec, false},
+		{"ACL la máscara deja rw", withACL, masked, PermWrite, true},
+		{"ACL grupo con nombre", withACL, group20, PermWrite, true},
+		{"ACL el grupo dueño sigue en r", withACL, member, PermWrite, false},
+		{"ACL el dueño no usa la ACL", withACL, owner, PermWrite, true},
+		{"ACL otros", withACL, other, PermRead, false},
+	}
+	for _, tt := range tests {
+		if got := Access(file, sb, tt.inode, tt.cred, tt.want); got != tt.ok {
+			t.Errorf("%s: Access = %v, se esperaba %v", tt.name, got, tt.ok)
+		}
+	}
+}
+
+func TestPermString(t *testing.T) {
+	tests := map[Perm]string{
+		0o664:  "rw-rw-r--",
+		0o2775: "rwxrwsr-x",
+		0o2764: "rwxrwSr--",
+		0o1777: "rwxrwxrwt",
+		0o1776: "rwxrwxrwT",
+	}
+	for p, want := range tests {
+		if got := p.String(); got != want {
+			t.Errorf("Perm(%o).String() = %q, se esperaba %q", p, got, want)
+		}
+	}
+}
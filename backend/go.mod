@@ -4,9 +4,10 @@ go 1.23.0
 
 toolchain go1.24.6
 
-require github.com/fogleman/gg v1.3.0
-
 require (
-	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
-	golang.org/x/image v0.30.0 // indirect
+	github.com/fogleman/gg v1.3.0
+	golang.org/x/crypto v0.41.0
+	golang.org/x/image v0.30.0
 )
+
+require github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
@@ -8,6 +8,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sync"
 )
 
 // Estructuras para las peticiones/respuestas JSON
@@ -19,6 +20,9 @@ type ExecResponse struct {
 	Output string `json:"output"`
 }
 
+// serverMu serializa las peticiones: los comandos usan estado global y redirigen stdout
+var serverMu sync.Mutex
+
 //go run main.go --server
 func main() {
 	// Verifica si hay un flag para iniciar en modo servidor
@@ -60,6 +64,8 @@ func startServer() {
 	// Configura el manejador con CORS
 	handler := http.NewServeMux()
 	handler.HandleFunc("/execute", executeHandler)
+	handler.HandleFunc("GET /reports", reportsIndexHandler)
+	handler.HandleFunc("GET /reports/{id}/{name}", reportHandler)
 
 	// Aplica middleware CORS
 	corsHandler := enableCORS(handler)
@@ -75,7 +81,7 @@ func enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Configura los headers CORS
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 
 		// Para peticiones OPTIONS (preflight)
@@ -108,7 +114,9 @@ func executeHandler(w http.ResponseWriter, r *http.Request) {
 
         log.Printf("Recibidos comandos: %s", req.Commands)
 
+        serverMu.Lock()
         output := analyzer.ProcessCommands(req.Commands)
+        serverMu.Unlock()
 
         resp := ExecResponse{Output: output}
         w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,370 @@
+package report
+
+import (
+	"fmt"
+	"image/jpeg"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// Dibujo propio de los reportes. El mismo layout se usa para PNG/JPG (con gg), SVG y
+// PDF: cambia solo el painter. Los grafos se ubican con layoutGraph.
+
+const (
+	canvasWidth = 1000.0
+	rowHeight   = 25.0
+	monoHeight  = 16.0
+	charWidth   = 8.5 // ancho aproximado de un carácter a 14 pt, para repartir columnas
+)
+
+const (
+	fontPath      = "/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf"
+	smallFontPath = "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"
+	monoFontPath  = "/usr/share/fonts/truetype/dejavu/DejaVuSansMono.ttf"
+)
+
+// fontStyle es la letra con que se dibuja un texto.
+type fontStyle int
+
+const (
+	fontBold  fontStyle = iota // sans negrita 14: títulos, campos y tablas
+	fontMono                   // monoespaciada 12: secciones de texto
+	fontSmall                  // sans 11: nodos y aristas de los grafos
+)
+
+// painter es lo mínimo que necesita el layout para dibujar.
+type painter interface {
+	rect(x, y, w, h float64, color string)
+	strokeRect(x, y, w, h float64, color string, dashed bool)
+	line(x1, y1, x2, y2 float64, color string)
+	curve(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, color string)
+	// polygon rellena el polígono de puntos x0, y0, x1, y1...
+	polygon(points []float64, color string)
+	// text dibuja s centrado verticalmente en y; ax es el anclaje horizontal (0 izquierda, 0.5 centro).
+	text(s string, x, y, ax float64, color string, f fontStyle)
+}
+
+// nopPainter no dibuja nada: sirve para medir la altura antes de crear la imagen.
+type nopPainter struct{}
+
+func (nopPainter) rect(x, y, w, h float64, color string)                          {}
+func (nopPainter) strokeRect(x, y, w, h float64, color string, dashed bool)       {}
+func (nopPainter) line(x1, y1, x2, y2 float64, color string)                      {}
+func (nopPainter) curve(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, color string) {}
+func (nopPainter) polygon(points []float64, color string)                         {}
+func (nopPainter) text(s string, x, y, ax float64, color string, f fontStyle)     {}
+
+// canvasDoc es un reporte listo para dibujar: con los grafos ya ubicados y el tamaño total.
+// El ancho crece para que quepan el grafo o la tabla más anchos.
+type canvasDoc struct {
+	r             *Report
+	graphs        map[*Graph]*graphLayout
+	width, height float64
+}
+
+func newCanvasDoc(r *Report) *canvasDoc {
+	d := &canvasDoc{r: r, graphs: make(map[*Graph]*graphLayout), width: canvasWidth}
+	for _, s := range r.Sections {
+		if s.Graph != nil {
+			gl := layoutGraph(s.Graph)
+			d.graphs[s.Graph] = gl
+			d.width = max(d.width, gl.width)
+		}
+		if s.Table != nil {
+			d.width = max(d.width, sumWidths(columnWidths(s.Table))+40)
+		}
+	}
+	d.height = d.draw(nopPainter{})
+	return d
+}
+
+// draw dibuja el reporte con p y devuelve la altura usada.
+func (d *canvasDoc) draw(p painter) float64 {
+	r, width := d.r, d.width
+	y := 20.0
+	if r.Title != "" {
+		p.rect(0, y, width, 40, "#336699")
+		p.text(r.Title, width/2, y+20, 0.5, "#ffffff", fontBold)
+		y += 60
+	}
+
+	for _, s := range r.Sections {
+		if s.Title != "" {
+			p.rect(0, y, width, 30, "#4d4d4d")
+			p.text(s.Title, 20, y+15, 0, "#ffffff", fontBold)
+			y += 30
+		}
+		for i, f := range s.Fields {
+			p.rect(0, y, width, rowHeight, rowColor(s.Color, i))
+			p.text(f.Key, 20, y+rowHeight/2, 0, "#000000", fontBold)
+			p.text(f.Value, 300, y+rowHeight/2, 0, "#000000", fontBold)
+			y += rowHeight
+		}
+		if t := s.Table; t != nil {
+			xs := columnPositions(t)
+			p.rect(0, y, width, 30, "#4d4d4d")
+			for i, c := range t.Columns {
+				p.text(c, xs[i], y+15, 0, "#ffffff", fontBold)
+			}
+			y += 30
+			for i, row := range t.Rows {
+				p.rect(0, y, width, rowHeight, rowColor("", i))
+				for j := 0; j < len(row) && j < len(xs); j++ {
+					p.text(row[j], xs[j], y+rowHeight/2, 0, "#000000", fontBold)
+				}
+				y += rowHeight
+			}
+		}
+		if len(s.Bar) > 0 {
+			y = drawBar(p, barGroups(s.Bar), width, y+20) + 20
+		}
+		if s.Grid != nil {
+			y = drawGrid(p, s.Grid, width, y)
+		}
+		if g := s.Graph; g != nil {
+			gl := d.graphs[g]
+			gl.draw(p, (width-gl.width)/2, y)
+			y += gl.height
+		}
+		if s.Text != "" {
+			y += 10
+			for _, line := range strings.Split(strings.TrimRight(s.Text, "\n"), "\n") {
+				p.text(line, 20, y+monoHeight/2, 0, "#000000", fontMono)
+				y += monoHeight
+			}
+		}
+		y += 10
+	}
+	return y + 10
+}
+
+// drawBar dibuja la barra proporcional desde y: los tramos de primer nivel en una fila y,
+// debajo de cada uno, sus tramos anidados en el mismo ancho. Cada texto se dibuja solo si
+// cabe en su tramo. Devuelve la y donde termina.
+func drawBar(p painter, groups []barGroup, width, y float64) float64 {
+	const barHeight = 80.0
+	nested := false
+	x := 0.0
+	for _, g := range groups {
+		w := width * g.Percent / 100
+		drawSegment(p, g.Segment, x, y, w, barHeight, "#e6e6e6")
+		cx := x
+		for _, c := range g.Children {
+			cw := width * c.Percent / 100
+			drawSegment(p, c, cx, y+barHeight, cw, barHeight, "#ff9999")
+			cx += cw
+			nested = true
+		}
+		x += w
+	}
+	if nested {
+		return y + 2*barHeight
+	}
+	return y + barHeight
+}
+
+// drawSegment dibuja un tramo con borde, para que los muy angostos se vean como una línea.
+func drawSegment(p painter, seg Segment, x, y, w, h float64, def string) {
+	p.rect(x, y, w, h, colorOr(seg.Color, def))
+	p.strokeRect(x, y, max(w, 1), h, "#4d4d4d", false)
+	lines := append([]string{seg.Label, fmt.Sprintf("%.2f%%", seg.Percent)}, seg.Detail...)
+	const lineHeight = 16.0
+	ty := y + (h-lineHeight*float64(len(lines)))/2 + lineHeight/2
+	for i, l := range lines {
+		// etiqueta y porcentaje en negrita si caben; si no, y el detalle siempre, en letra chica
+		n := float64(utf8.RuneCountInString(l))
+		switch {
+		case i < 2 && n*charWidth+6 <= w:
+			p.text(l, x+w/2, ty, 0.5, "#000000", fontBold)
+		case n*charWidth*0.75+6 <= w:
+			p.text(l, x+w/2, ty, 0.5, "#000000", fontSmall)
+		}
+		ty += lineHeight
+	}
+}
+
+// drawGrid dibuja el mapa de celdas desde y, con el número de la primera celda de cada
+// fila a la izquierda y la leyenda debajo. Devuelve la y donde termina.
+func drawGrid(p painter, g *Grid, width, y float64) float64 {
+	const labelWidth = 70.0
+	cols := max(g.Columns, 1)
+	step := max(g.Step, 1)
+	cell := min(16, (width-labelWidth-20)/float64(cols))
+	labelEvery := int(math.Ceil(14 / cell)) // con celdas chicas los rótulos se encimarían
+
+	y += 10
+	for row := 0; row*cols < len(g.Cells); row++ {
+		if row%labelEvery == 0 {
+			p.text(fmt.Sprintf("%d", g.Start+row*cols*step), 20, y+cell/2, 0, "#000000", fontSmall)
+		}
+		for j, c := range g.Cells[row*cols : min((row+1)*cols, len(g.Cells))] {
+			x := labelWidth + float64(j)*cell
+			p.rect(x, y, cell-1, cell-1, c.Color)
+			if c.Mark {
+				p.strokeRect(x+0.5, y+0.5, cell-2, cell-2, "#cc0000", false)
+			}
+		}
+		y += cell
+	}
+
+	if len(g.Legend) > 0 {
+		y += 10
+		x := 20.0
+		for _, l := range g.Legend {
+			w := 14 + 6 + float64(utf8.RuneCountInString(l.Label))*charWidth*0.8 + 20
+			if x+w > width && x > 20 {
+				x, y = 20, y+rowHeight
+			}
+			p.rect(x, y+5, 14, 14, l.Color)
+			if l.Mark {
+				p.strokeRect(x+0.5, y+5.5, 13, 13, "#cc0000", false)
+			}
+			p.text(l.Label, x+20, y+12, 0, "#000000", fontSmall)
+			x += w
+		}
+		y += rowHeight
+	}
+	return y
+}
+
+// rowColor alterna dos tonos para las filas; con color de sección se usa ese color.
+func rowColor(color string, i int) string {
+	if color != "" {
+		return color
+	}
+	if i%2 == 0 {
+		return "#f2f2f2"
+	}
+	return "#d9d9d9"
+}
+
+// columnPositions ubica las columnas una tras otra desde el margen izquierdo; newCanvasDoc
+// ya ensanchó el documento para que quepan todas.
+func columnPositions(t *Table) []float64 {
+	widths := columnWidths(t)
+	xs := make([]float64, len(widths))
+	x := 20.0
+	for i, w := range widths {
+		xs[i] = x
+		x += w
+	}
+	return xs
+}
+
+// columnWidths da a cada columna el ancho de su contenido más largo.
+func columnWidths(t *Table) []float64 {
+	widths := make([]float64, len(t.Columns))
+	for i, c := range t.Columns {
+		n := utf8.RuneCountInString(c)
+		for _, row := range t.Rows {
+			if i < len(row) {
+				n = max(n, utf8.RuneCountInString(row[i]))
+			}
+		}
+		widths[i] = float64(n)*charWidth + 20
+	}
+	return widths
+}
+
+func sumWidths(widths []float64) float64 {
+	total := 0.0
+	for _, w := range widths {
+		total += w
+	}
+	return total
+}
+
+// ggPainter dibuja sobre una imagen de gg.
+type ggPainter struct {
+	dc    *gg.Context
+	faces map[fontStyle]font.Face
+}
+
+func (p *ggPainter) rect(x, y, w, h float64, color string) {
+	p.dc.SetHexColor(color)
+	p.dc.DrawRectangle(x, y, w, h)
+	p.dc.Fill()
+}
+
+func (p *ggPainter) strokeRect(x, y, w, h float64, color string, dashed bool) {
+	p.dc.SetHexColor(color)
+	p.dc.SetLineWidth(1)
+	if dashed {
+		p.dc.SetDash(4, 2)
+	}
+	p.dc.DrawRectangle(x, y, w, h)
+	p.dc.Stroke()
+	p.dc.SetDash()
+}
+
+func (p *ggPainter) line(x1, y1, x2, y2 float64, color string) {
+	p.dc.SetHexColor(color)
+	p.dc.SetLineWidth(1)
+	p.dc.DrawLine(x1, y1, x2, y2)
+	p.dc.Stroke()
+}
+
+func (p *ggPainter) curve(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, color string) {
+	p.dc.SetHexColor(color)
+	p.dc.SetLineWidth(1)
+	p.dc.MoveTo(x1, y1)
+	p.dc.CubicTo(cx1, cy1, cx2, cy2, x2, y2)
+	p.dc.Stroke()
+}
+
+func (p *ggPainter) polygon(points []float64, color string) {
+	p.dc.SetHexColor(color)
+	for i := 0; i+1 < len(points); i += 2 {
+		p.dc.LineTo(points[i], points[i+1])
+	}
+	p.dc.ClosePath()
+	p.dc.Fill()
+}
+
+func (p *ggPainter) text(s string, x, y, ax float64, color string, f fontStyle) {
+	p.dc.SetFontFace(p.faces[f])
+	p.dc.SetHexColor(color)
+	p.dc.DrawStringAnchored(s, x, y, ax, 0.5)
+}
+
+// drawCanvas dibuja el reporte en una imagen de gg del tamaño necesario.
+func drawCanvas(r *Report) (*gg.Context, error) {
+	faces := make(map[fontStyle]font.Face)
+	for style, f := range map[fontStyle]struct {
+		path string
+		size float64
+	}{fontBold: {fontPath, 14}, fontMono: {monoFontPath, 12}, fontSmall: {smallFontPath, 11}} {
+		face, err := gg.LoadFontFace(f.path, f.size)
+		if err != nil {
+			return nil, fmt.Errorf("error al cargar la fuente: %w", err)
+		}
+		faces[style] = face
+	}
+	doc := newCanvasDoc(r)
+	dc := gg.NewContext(int(doc.width), int(doc.height))
+	dc.SetRGB(1, 1, 1)
+	dc.Clear()
+	doc.draw(&ggPainter{dc: dc, faces: faces})
+	return dc, nil
+}
+
+func renderCanvasPNG(w io.Writer, r *Report) error {
+	dc, err := drawCanvas(r)
+	if err != nil {
+		return err
+	}
+	return dc.EncodePNG(w)
+}
+
+func renderCanvasJPG(w io.Writer, r *Report) error {
+	dc, err := drawCanvas(r)
+	if err != nil {
+		return err
+	}
+	return jpeg.Encode(w, dc.Image(), &jpeg.Options{Quality: 90})
+}
@@ -0,0 +1,224 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// renderDOT escribe el reporte en el lenguaje de Graphviz. Los grafos se escriben como
+// nodos tipo registro; las demás secciones, como nodos con una tabla HTML, apiladas.
+func renderDOT(w io.Writer, r *Report) error {
+	bw := bufio.NewWriter(w)
+
+	direction := "TB"
+	for _, s := range r.Sections {
+		if s.Graph != nil && s.Graph.Direction != "" {
+			direction = s.Graph.Direction
+			break
+		}
+	}
+	name := r.Name
+	if name == "" {
+		name = "reporte"
+	}
+	fmt.Fprintf(bw, "digraph %s {\n", strconv.Quote(name))
+	fmt.Fprintf(bw, "    rankdir=%s;\n", direction)
+	if r.Title != "" {
+		fmt.Fprintf(bw, "    labelloc=\"t\";\n    label=%s;\n", dotQuote(r.Title))
+	}
+	fmt.Fprintln(bw, "    node [shape=record, fontsize=10, style=filled, fillcolor=\"#add8e6\"];")
+	fmt.Fprintln(bw, "    edge [arrowhead=vee, arrowsize=0.7];")
+
+	prev := ""
+	for i, s := range r.Sections {
+		if len(s.Fields) > 0 || s.Table != nil || len(s.Bar) > 0 || s.Grid != nil || s.Text != "" || (s.Title != "" && s.Graph == nil) {
+			id := fmt.Sprintf("seccion%d", i)
+			fmt.Fprintf(bw, "    %s [shape=plaintext, style=\"\", label=<%s>];\n", id, sectionTable(s))
+			if prev != "" {
+				fmt.Fprintf(bw, "    %s -> %s [style=invis];\n", prev, id)
+			}
+			prev = id
+		}
+		if s.Graph != nil {
+			writeDOTGraph(bw, s.Graph)
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func writeDOTGraph(w io.Writer, g *Graph) {
+	node := func(indent string, n Node) {
+		cells := make([]string, len(n.Cells))
+		for i, c := range n.Cells {
+			cells[i] = recordEscape(c)
+		}
+		fmt.Fprintf(w, "%s%s [label=\"%s\"", indent, strconv.Quote(n.ID), strings.Join(cells, " | "))
+		if n.Color != "" {
+			fmt.Fprintf(w, ", fillcolor=%s", strconv.Quote(n.Color))
+		}
+		fmt.Fprintln(w, "];")
+	}
+
+	for _, c := range g.Clusters {
+		fmt.Fprintf(w, "    subgraph %s {\n", strconv.Quote("cluster_"+c.ID))
+		fmt.Fprintf(w, "        label=%s;\n", dotQuote(c.Label))
+		fmt.Fprintf(w, "        color=%s; style=dashed;\n", strconv.Quote(colorOr(c.Color, "#808080")))
+		for _, n := range g.Nodes {
+			if n.Cluster == c.ID {
+				node("        ", n)
+			}
+		}
+		fmt.Fprintln(w, "    }")
+	}
+	for _, n := range g.Nodes {
+		if !hasCluster(g, n.Cluster) {
+			node("    ", n)
+		}
+	}
+	for _, e := range g.Edges {
+		if e.Label != "" {
+			fmt.Fprintf(w, "    %s -> %s [label=%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), dotQuote(e.Label))
+		} else {
+			fmt.Fprintf(w, "    %s -> %s;\n", strconv.Quote(e.From), strconv.Quote(e.To))
+		}
+	}
+}
+
+func hasCluster(g *Graph, id string) bool {
+	for _, c := range g.Clusters {
+		if c.ID == id && id != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionTable arma la etiqueta HTML de Graphviz para una sección que no es grafo.
+func sectionTable(s Section) string {
+	esc := html.EscapeString
+	var b strings.Builder
+	b.WriteString(`<table border="0" cellborder="1" cellspacing="0" cellpadding="4">`)
+	if s.Title != "" {
+		fmt.Fprintf(&b, `<tr><td bgcolor="#4d4d4d"><font color="white"><b>%s</b></font></td></tr>`, esc(s.Title))
+	}
+	if len(s.Fields) > 0 {
+		fmt.Fprintf(&b, `<tr><td><table border="0" cellborder="1" cellspacing="0" bgcolor="%s">`, colorOr(s.Color, "#f2f2f2"))
+		for _, f := range s.Fields {
+			fmt.Fprintf(&b, `<tr><td align="left">%s</td><td align="left">%s</td></tr>`, esc(f.Key), esc(f.Value))
+		}
+		b.WriteString(`</table></td></tr>`)
+	}
+	if t := s.Table; t != nil {
+		b.WriteString(`<tr><td><table border="0" cellborder="1" cellspacing="0"><tr>`)
+		for _, c := range t.Columns {
+			fmt.Fprintf(&b, `<td bgcolor="#cccccc"><b>%s</b></td>`, esc(c))
+		}
+		b.WriteString(`</tr>`)
+		for _, row := range t.Rows {
+			b.WriteString(`<tr>`)
+			for _, cell := range row {
+				fmt.Fprintf(&b, `<td align="left">%s</td>`, esc(cell))
+			}
+			b.WriteString(`</tr>`)
+		}
+		b.WriteString(`</table></td></tr>`)
+	}
+	if len(s.Bar) > 0 {
+		b.WriteString(`<tr><td><table border="0" cellborder="1" cellspacing="0"><tr>`)
+		for _, g := range barGroups(s.Bar) {
+			label := fmt.Sprintf("%s<br/>%.2f%%", esc(g.Label), g.Percent)
+			for _, d := range g.Detail {
+				label += "<br/>" + esc(d)
+			}
+			for _, c := range g.Children {
+				label += fmt.Sprintf("<br/>%s %.2f%%", esc(c.Label), c.Percent)
+				if len(c.Detail) > 0 {
+					label += " " + esc(strings.Join(c.Detail, " "))
+				}
+			}
+			width := int(g.Percent*6) + 1 // 600 puntos = 100 %
+			fmt.Fprintf(&b, `<td width="%d" height="60" fixedsize="false" bgcolor="%s">%s</td>`,
+				width, colorOr(g.Color, "#e6e6e6"), label)
+		}
+		b.WriteString(`</tr></table></td></tr>`)
+	}
+	if g := s.Grid; g != nil {
+		cols := max(g.Columns, 1)
+		b.WriteString(`<tr><td><table border="0" cellborder="0" cellspacing="1">`)
+		for row := 0; row*cols < len(g.Cells); row++ {
+			b.WriteString(`<tr>`)
+			for _, c := range g.Cells[row*cols : min((row+1)*cols, len(g.Cells))] {
+				border := 0
+				if c.Mark {
+					border = 1
+				}
+				fmt.Fprintf(&b, `<td width="8" height="8" fixedsize="true" border="%d" color="#cc0000" bgcolor="%s"></td>`, border, c.Color)
+			}
+			b.WriteString(`</tr>`)
+		}
+		b.WriteString(`</table></td></tr>`)
+		if len(g.Legend) > 0 {
+			b.WriteString(`<tr><td align="left">`)
+			for i, l := range g.Legend {
+				if i > 0 {
+					b.WriteString(`<br align="left"/>`)
+				}
+				fmt.Fprintf(&b, `<font color="%s">■</font> %s`, l.Color, esc(l.Label))
+			}
+			b.WriteString(`<br align="left"/></td></tr>`)
+		}
+	}
+	if s.Text != "" {
+		lines := strings.Split(strings.TrimRight(s.Text, "\n"), "\n")
+		for i, l := range lines {
+			lines[i] = esc(l)
+		}
+		fmt.Fprintf(&b, `<tr><td align="left"><font face="monospace">%s<br align="left"/></font></td></tr>`,
+			strings.Join(lines, `<br align="left"/>`))
+	}
+	b.WriteString(`</table>`)
+	return b.String()
+}
+
+// recordEscape escapa el texto de un campo de un nodo tipo registro.
+func recordEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `{`, `\{`, `}`, `\}`, `|`, `\|`, `<`, `\<`, `>`, `\>`,
+		"\n", `\n`, "\x00", "")
+	return r.Replace(s)
+}
+
+// dotQuote entrecomilla una etiqueta de DOT.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\x00", "").Replace(s) + `"`
+}
+
+// graphvizAvailable indica si se puede usar el comando dot.
+func graphvizAvailable() bool {
+	_, err := exec.LookPath("dot")
+	return err == nil
+}
+
+// graphviz devuelve un renderizador que pasa el DOT del reporte por el comando dot.
+func graphviz(format string) RendererFunc {
+	return func(w io.Writer, r *Report) error {
+		var src, stderr bytes.Buffer
+		if err := renderDOT(&src, r); err != nil {
+			return err
+		}
+		cmd := exec.Command("dot", "-T"+format)
+		cmd.Stdin = &src
+		cmd.Stdout = w
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("error al generar la imagen con Graphviz: %v %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,369 @@
+package report
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Layout propio de grafos por capas, una versión simplificada de lo que hace dot: se
+// rompen los ciclos, cada nodo va en la capa de su camino más largo desde una raíz, el
+// orden dentro de cada capa se ajusta por baricentro y las aristas se dibujan como curvas.
+// Así los reportes inode, block y tree no dependen de tener Graphviz instalado.
+
+const (
+	graphCharWidth = 6.6  // ancho aproximado de un carácter a 11 pt
+	graphRowHeight = 16.0 // alto de cada campo de un nodo
+	graphPadding   = 8.0
+	graphMargin    = 20.0
+	graphNodeGap   = 24.0 // entre nodos de la misma capa
+	graphLayerGap  = 60.0 // entre capas
+	legendHeight   = 30.0
+	defaultNodeBg  = "#add8e6"
+	edgeColor      = "#333333"
+)
+
+type placedNode struct {
+	Node
+	layer      int
+	x, y, w, h float64 // esquina superior izquierda y tamaño
+}
+
+type placedEdge struct {
+	Edge
+	from, to *placedNode
+}
+
+// graphLayout es un grafo con la posición de cada nodo, relativa a su esquina.
+type graphLayout struct {
+	g             *Graph
+	lr            bool
+	nodes         []*placedNode
+	edges         []placedEdge
+	width, height float64
+}
+
+// layoutGraph ubica los nodos del grafo. Las aristas hacia nodos que no existen se
+// ignoran (el reporte tree puede apuntar a bloques que el bitmap marca libres).
+func layoutGraph(g *Graph) *graphLayout {
+	gl := &graphLayout{g: g, lr: g.Direction == "LR"}
+	byID := make(map[string]*placedNode)
+	for _, n := range g.Nodes {
+		if _, dup := byID[n.ID]; dup {
+			continue
+		}
+		pn := &placedNode{Node: n}
+		pn.w, pn.h = nodeSize(n)
+		byID[n.ID] = pn
+		gl.nodes = append(gl.nodes, pn)
+	}
+	for _, e := range g.Edges {
+		from, to := byID[e.From], byID[e.To]
+		if from == nil || to == nil || from == to {
+			continue
+		}
+		gl.edges = append(gl.edges, placedEdge{Edge: e, from: from, to: to})
+	}
+
+	gl.assignLayers()
+	gl.place(gl.orderLayers())
+	return gl
+}
+
+func nodeSize(n Node) (w, h float64) {
+	chars := 0
+	for _, c := range n.Cells {
+		chars = max(chars, utf8.RuneCountInString(c))
+	}
+	w = max(60, float64(chars)*graphCharWidth+2*graphPadding)
+	h = float64(max(1, len(n.Cells)))*graphRowHeight + graphPadding
+	return w, h
+}
+
+// assignLayers hace un DFS para descartar las aristas que cierran ciclos y asigna a cada
+// nodo la capa de su camino más largo en lo que queda (un DAG).
+func (gl *graphLayout) assignLayers() {
+	index := make(map[*placedNode]int, len(gl.nodes))
+	for i, n := range gl.nodes {
+		index[n] = i
+	}
+	out := make([][]int, len(gl.nodes))
+	for _, e := range gl.edges {
+		out[index[e.from]] = append(out[index[e.from]], index[e.to])
+	}
+
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := make([]int, len(gl.nodes))
+	forward := make([][]int, len(gl.nodes))
+	var postorder []int
+	var visit func(v int)
+	visit = func(v int) {
+		state[v] = onStack
+		for _, w := range out[v] {
+			switch state[w] {
+			case unvisited:
+				forward[v] = append(forward[v], w)
+				visit(w)
+			case done:
+				forward[v] = append(forward[v], w)
+			}
+			// onStack: arista de retroceso, cierra un ciclo
+		}
+		state[v] = done
+		postorder = append(postorder, v)
+	}
+	for v := range gl.nodes {
+		if state[v] == unvisited {
+			visit(v)
+		}
+	}
+
+	// El postorden invertido es un orden topológico del DAG
+	for i := len(postorder) - 1; i >= 0; i-- {
+		v := postorder[i]
+		for _, w := range forward[v] {
+			gl.nodes[w].layer = max(gl.nodes[w].layer, gl.nodes[v].layer+1)
+		}
+	}
+}
+
+// orderLayers agrupa los nodos por capa y los ordena por el baricentro de sus vecinos
+// en las capas adyacentes, con unas pasadas hacia abajo y hacia arriba.
+func (gl *graphLayout) orderLayers() [][]*placedNode {
+	var layers [][]*placedNode
+	for _, n := range gl.nodes {
+		for len(layers) <= n.layer {
+			layers = append(layers, nil)
+		}
+		layers[n.layer] = append(layers[n.layer], n)
+	}
+
+	neighbors := make(map[*placedNode][]*placedNode)
+	for _, e := range gl.edges {
+		neighbors[e.from] = append(neighbors[e.from], e.to)
+		neighbors[e.to] = append(neighbors[e.to], e.from)
+	}
+	pos := make(map[*placedNode]float64)
+	setPositions := func(layer []*placedNode) {
+		for i, n := range layer {
+			pos[n] = float64(i) - float64(len(layer)-1)/2
+		}
+	}
+	for _, layer := range layers {
+		setPositions(layer)
+	}
+
+	sortLayer := func(l, ref int) {
+		keys := make(map[*placedNode]float64, len(layers[l]))
+		for _, n := range layers[l] {
+			sum, count := 0.0, 0
+			for _, m := range neighbors[n] {
+				if m.layer == ref {
+					sum += pos[m]
+					count++
+				}
+			}
+			if count > 0 {
+				keys[n] = sum / float64(count)
+			} else {
+				keys[n] = pos[n]
+			}
+		}
+		sort.SliceStable(layers[l], func(i, j int) bool { return keys[layers[l][i]] < keys[layers[l][j]] })
+		setPositions(layers[l])
+	}
+	for pass := 0; pass < 4; pass++ {
+		for l := 1; l < len(layers); l++ {
+			sortLayer(l, l-1)
+		}
+		for l := len(layers) - 2; l >= 0; l-- {
+			sortLayer(l, l+1)
+		}
+	}
+	return layers
+}
+
+// place calcula las coordenadas: las capas avanzan en la dirección del grafo y cada capa
+// se centra en el otro eje.
+func (gl *graphLayout) place(layers [][]*placedNode) {
+	thickness := make([]float64, len(layers)) // en la dirección del grafo
+	extent := make([]float64, len(layers))    // a lo ancho de la capa
+	maxExtent := 0.0
+	for l, layer := range layers {
+		for i, n := range layer {
+			along, across := n.h, n.w
+			if gl.lr {
+				along, across = n.w, n.h
+			}
+			thickness[l] = max(thickness[l], along)
+			if i > 0 {
+				extent[l] += graphNodeGap
+			}
+			extent[l] += across
+		}
+		maxExtent = max(maxExtent, extent[l])
+	}
+
+	main := graphMargin
+	if len(gl.g.Clusters) > 0 && !gl.lr {
+		main += legendHeight
+	}
+	for l, layer := range layers {
+		cross := graphMargin + (maxExtent-extent[l])/2
+		for _, n := range layer {
+			if gl.lr {
+				n.x, n.y = main+(thickness[l]-n.w)/2, cross
+				cross += n.h + graphNodeGap
+			} else {
+				n.x, n.y = cross, main+(thickness[l]-n.h)/2
+				cross += n.w + graphNodeGap
+			}
+		}
+		main += thickness[l] + graphLayerGap
+	}
+	main += graphMargin - graphLayerGap
+
+	if gl.lr {
+		gl.width, gl.height = main, maxExtent+2*graphMargin
+	} else {
+		gl.width, gl.height = maxExtent+2*graphMargin, main
+	}
+	if len(gl.g.Clusters) > 0 {
+		gl.width = max(gl.width, legendWidth(gl.g.Clusters)+2*graphMargin)
+		if gl.lr {
+			gl.height += legendHeight
+			for _, n := range gl.nodes {
+				n.y += legendHeight
+			}
+		}
+	}
+}
+
+func legendWidth(clusters []Cluster) float64 {
+	w := 0.0
+	for _, c := range clusters {
+		w += 24 + float64(utf8.RuneCountInString(c.Label))*graphCharWidth + 20
+	}
+	return w
+}
+
+// clusterColors devuelve el color de borde de cada grupo.
+func (gl *graphLayout) clusterColors() map[string]string {
+	colors := make(map[string]string)
+	for _, c := range gl.g.Clusters {
+		colors[c.ID] = colorOr(c.Color, "#808080")
+	}
+	return colors
+}
+
+// draw dibuja el grafo con su esquina en (ox, oy). Los grupos (clusters) se muestran
+// con una leyenda y el color del borde de sus nodos.
+func (gl *graphLayout) draw(p painter, ox, oy float64) {
+	colors := gl.clusterColors()
+
+	if len(gl.g.Clusters) > 0 {
+		x := ox + graphMargin
+		y := oy + graphMargin
+		for _, c := range gl.g.Clusters {
+			p.strokeRect(x, y+3, 14, 14, colors[c.ID], true)
+			p.text(c.Label, x+20, y+10, 0, "#000000", fontSmall)
+			x += 24 + float64(utf8.RuneCountInString(c.Label))*graphCharWidth + 20
+		}
+	}
+
+	for _, e := range gl.edges {
+		gl.drawEdge(p, e, ox, oy)
+	}
+
+	for _, n := range gl.nodes {
+		x, y := ox+n.x, oy+n.y
+		border := edgeColor
+		if c, ok := colors[n.Cluster]; ok {
+			border = c
+		}
+		p.rect(x, y, n.w, n.h, colorOr(n.Color, defaultNodeBg))
+		p.strokeRect(x, y, n.w, n.h, border, false)
+		for i, cell := range n.Cells {
+			cy := y + graphPadding/2 + float64(i)*graphRowHeight + graphRowHeight/2
+			cell = strings.ReplaceAll(cell, "\n", " ")
+			if i == 0 {
+				p.text(cell, x+n.w/2, cy, 0.5, "#000000", fontSmall)
+				if len(n.Cells) > 1 {
+					p.line(x, cy+graphRowHeight/2, x+n.w, cy+graphRowHeight/2, border)
+				}
+			} else {
+				p.text(cell, x+graphPadding, cy, 0, "#000000", fontSmall)
+			}
+		}
+	}
+}
+
+// drawEdge dibuja una arista: curva entre capas si avanza en la dirección del grafo,
+// recta entre bordes si no, con punta de flecha y etiqueta.
+func (gl *graphLayout) drawEdge(p painter, e placedEdge, ox, oy float64) {
+	a, b := e.from, e.to
+	var sx, sy, ex, ey, c1x, c1y, c2x, c2y float64
+	switch {
+	case b.layer > a.layer && !gl.lr:
+		sx, sy = ox+a.x+a.w/2, oy+a.y+a.h
+		ex, ey = ox+b.x+b.w/2, oy+b.y
+		d := (ey - sy) / 2
+		c1x, c1y, c2x, c2y = sx, sy+d, ex, ey-d
+	case b.layer > a.layer && gl.lr:
+		sx, sy = ox+a.x+a.w, oy+a.y+a.h/2
+		ex, ey = ox+b.x, oy+b.y+b.h/2
+		d := (ex - sx) / 2
+		c1x, c1y, c2x, c2y = sx+d, sy, ex-d, ey
+	default:
+		sx, sy = borderPoint(a, b, ox, oy)
+		ex, ey = borderPoint(b, a, ox, oy)
+		c1x, c1y = sx+(ex-sx)/3, sy+(ey-sy)/3
+		c2x, c2y = sx+2*(ex-sx)/3, sy+2*(ey-sy)/3
+	}
+	p.curve(sx, sy, c1x, c1y, c2x, c2y, ex, ey, edgeColor)
+
+	// Punta de flecha en la dirección con que llega la curva
+	dx, dy := ex-c2x, ey-c2y
+	if dx == 0 && dy == 0 {
+		dx, dy = ex-sx, ey-sy
+	}
+	if l := math.Hypot(dx, dy); l > 0 {
+		dx, dy = dx/l, dy/l
+		p.polygon([]float64{
+			ex, ey,
+			ex - dx*8 - dy*4, ey - dy*8 + dx*4,
+			ex - dx*8 + dy*4, ey - dy*8 - dx*4,
+		}, edgeColor)
+	}
+
+	if e.Label != "" {
+		mx := (sx + 3*c1x + 3*c2x + ex) / 8
+		my := (sy + 3*c1y + 3*c2y + ey) / 8
+		w := float64(utf8.RuneCountInString(e.Label))*graphCharWidth + 6
+		p.rect(mx-w/2, my-7, w, 14, "#ffffff")
+		p.text(e.Label, mx, my, 0.5, "#000000", fontSmall)
+	}
+}
+
+// borderPoint es el punto del borde de n en la recta entre su centro y el de other.
+func borderPoint(n, other *placedNode, ox, oy float64) (float64, float64) {
+	cx, cy := ox+n.x+n.w/2, oy+n.y+n.h/2
+	dx := (ox + other.x + other.w/2) - cx
+	dy := (oy + other.y + other.h/2) - cy
+	if dx == 0 && dy == 0 {
+		return cx, cy
+	}
+	t := math.Inf(1)
+	if dx != 0 {
+		t = math.Min(t, (n.w/2)/math.Abs(dx))
+	}
+	if dy != 0 {
+		t = math.Min(t, (n.h/2)/math.Abs(dy))
+	}
+	return cx + dx*t, cy + dy*t
+}
@@ -0,0 +1,119 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLayoutGraph(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction string
+		edges     [][2]string
+		back      int // aristas que cierran un ciclo y no pueden avanzar de capa
+	}{
+		{"cadena", "LR", [][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}}, 0},
+		{"árbol", "TB", [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"b", "e"}, {"c", "f"}}, 0},
+		{"ciclo", "LR", [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}}, 1},
+		{"rombo", "TB", [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}}, 0},
+		{"aristas a nodos que no existen", "LR", [][2]string{{"a", "b"}, {"b", "zz"}, {"a", "a"}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Graph{Direction: tt.direction}
+			seen := map[string]bool{}
+			for _, e := range tt.edges {
+				g.Edges = append(g.Edges, Edge{From: e[0], To: e[1]})
+				for _, id := range e {
+					if !seen[id] && id != "zz" {
+						seen[id] = true
+						g.Nodes = append(g.Nodes, Node{ID: id, Cells: []string{"Nodo " + id, "campo largo de prueba"}})
+					}
+				}
+			}
+			gl := layoutGraph(g)
+
+			if len(gl.nodes) != len(g.Nodes) {
+				t.Fatalf("%d nodos ubicados, se esperaban %d", len(gl.nodes), len(g.Nodes))
+			}
+			for i, a := range gl.nodes {
+				if a.x < 0 || a.y < 0 || a.x+a.w > gl.width || a.y+a.h > gl.height {
+					t.Errorf("el nodo %s queda fuera del lienzo", a.ID)
+				}
+				for _, b := range gl.nodes[i+1:] {
+					if a.x < b.x+b.w && b.x < a.x+a.w && a.y < b.y+b.h && b.y < a.y+a.h {
+						t.Errorf("los nodos %s y %s se superponen", a.ID, b.ID)
+					}
+				}
+			}
+			back := 0
+			for _, e := range gl.edges {
+				if e.to.layer <= e.from.layer {
+					back++
+				}
+			}
+			if back != tt.back {
+				t.Errorf("%d aristas no avanzan de capa, se esperaban %d", back, tt.back)
+			}
+		})
+	}
+}
+
+func TestRenderImages(t *testing.T) {
+	r := sampleReport()
+
+	var svg bytes.Buffer
+	if err := renderSVG(&svg, r); err != nil {
+		t.Fatal(err)
+	}
+	dec := xml.NewDecoder(&svg)
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("el SVG no es XML válido: %v", err)
+		}
+	}
+
+	// La tabla xref del PDF debe apuntar al inicio de cada objeto
+	var pdf bytes.Buffer
+	if err := renderPDF(&pdf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := pdf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatal("el PDF no tiene encabezado o cierre")
+	}
+	xref := strings.Index(out, "xref\n")
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(out[xref:], -1)
+	if len(offsets) == 0 {
+		t.Fatal("el PDF no tiene objetos en la tabla xref")
+	}
+	for i, m := range offsets {
+		off, _ := strconv.Atoi(m[1])
+		if !strings.HasPrefix(out[off:], fmt.Sprintf("%d 0 obj", i+1)) {
+			t.Errorf("xref del objeto %d apunta a %q", i+1, out[off:min(off+12, len(out))])
+		}
+	}
+	if !strings.Contains(out, fmt.Sprintf("startxref\n%d\n", xref)) {
+		t.Error("startxref no apunta a la tabla xref")
+	}
+
+	var img bytes.Buffer
+	if err := renderCanvasPNG(&img, r); err != nil {
+		if strings.Contains(err.Error(), "fuente") {
+			t.Skip("sin las fuentes DejaVu:", err)
+		}
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(&img); err != nil {
+		t.Errorf("el PNG no se puede leer: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// renderHTML escribe una página autocontenida. Los grafos se listan como tablas de
+// nodos y aristas.
+func renderHTML(w io.Writer, r *Report) error {
+	bw := bufio.NewWriter(w)
+	esc := html.EscapeString
+
+	fmt.Fprintln(bw, "<!doctype html>")
+	fmt.Fprintf(bw, "<html><head><meta charset=\"utf-8\"><title>%s</title>\n", esc(r.Title))
+	fmt.Fprintln(bw, "<style>body{font-family:sans-serif;margin:20px}table{border-collapse:collapse;margin-bottom:16px}"+
+		"th,td{border:1px solid #999;padding:4px 8px;text-align:left;vertical-align:top}th{background:#4d4d4d;color:#fff}"+
+		"h1{background:#336699;color:#fff;padding:8px}.bar{display:flex;align-items:flex-start;border:1px solid #999;margin-bottom:16px}"+
+		".seg{overflow:hidden;font-size:12px;text-align:center;border-right:1px solid #fff;min-width:2px;min-height:72px}.nested{display:flex;margin-top:4px}"+
+		".nested .seg{min-height:60px}pre{background:#f2f2f2;padding:8px}"+
+		".grid{display:grid;gap:1px;margin-bottom:8px}.grid div{height:12px}.mark{outline:2px solid #cc0000;outline-offset:-2px}"+
+		".legend{margin-bottom:16px}.legend span{display:inline-block;width:12px;height:12px;margin:0 4px 0 12px;vertical-align:middle}</style>")
+	fmt.Fprintln(bw, "</head><body>")
+	if r.Title != "" {
+		fmt.Fprintf(bw, "<h1>%s</h1>\n", esc(r.Title))
+	}
+
+	for _, s := range r.Sections {
+		if s.Title != "" {
+			fmt.Fprintf(bw, "<h2>%s</h2>\n", esc(s.Title))
+		}
+		if len(s.Fields) > 0 {
+			fmt.Fprintf(bw, "<table style=\"background:%s\">\n", colorOr(s.Color, "#f2f2f2"))
+			for _, f := range s.Fields {
+				fmt.Fprintf(bw, "<tr><td>%s</td><td>%s</td></tr>\n", esc(f.Key), esc(f.Value))
+			}
+			fmt.Fprintln(bw, "</table>")
+		}
+		if s.Table != nil {
+			writeHTMLTable(bw, s.Table.Columns, s.Table.Rows)
+		}
+		if len(s.Bar) > 0 {
+			fmt.Fprintln(bw, "<div class=\"bar\">")
+			for _, g := range barGroups(s.Bar) {
+				fmt.Fprintf(bw, "<div class=\"seg\" style=\"width:%.4f%%;background:%s\" title=\"%s\">%s<br>%.2f%%%s",
+					g.Percent, colorOr(g.Color, "#e6e6e6"), segmentTitle(g.Segment), esc(g.Label), g.Percent, segmentDetail(g.Segment))
+				writeNestedHTML(bw, g)
+				fmt.Fprintln(bw, "</div>")
+			}
+			fmt.Fprintln(bw, "</div>")
+		}
+		if s.Grid != nil {
+			writeHTMLGrid(bw, s.Grid)
+		}
+		if g := s.Graph; g != nil {
+			rows := make([][]string, len(g.Nodes))
+			for i, n := range g.Nodes {
+				rows[i] = []string{n.ID, n.Cluster, strings.Join(n.Cells, "\n")}
+			}
+			writeHTMLTable(bw, []string{"Nodo", "Grupo", "Contenido"}, rows)
+			rows = make([][]string, len(g.Edges))
+			for i, e := range g.Edges {
+				rows[i] = []string{e.From, e.To, e.Label}
+			}
+			writeHTMLTable(bw, []string{"Desde", "Hacia", "Etiqueta"}, rows)
+		}
+		if s.Text != "" {
+			fmt.Fprintf(bw, "<pre>%s</pre>\n", esc(s.Text))
+		}
+	}
+	fmt.Fprintln(bw, "</body></html>")
+	return bw.Flush()
+}
+
+func writeHTMLTable(w io.Writer, columns []string, rows [][]string) {
+	fmt.Fprintln(w, "<table><thead><tr>")
+	for _, c := range columns {
+		fmt.Fprintf(w, "<th>%s</th>", html.EscapeString(c))
+	}
+	fmt.Fprintln(w, "</tr></thead><tbody>")
+	for _, row := range rows {
+		fmt.Fprint(w, "<tr>")
+		for _, cell := range row {
+			fmt.Fprintf(w, "<td>%s</td>", strings.ReplaceAll(html.EscapeString(cell), "\n", "<br>"))
+		}
+		fmt.Fprintln(w, "</tr>")
+	}
+	fmt.Fprintln(w, "</tbody></table>")
+}
+
+// writeHTMLGrid dibuja el mapa con una celda por div; el title da el número de la celda.
+func writeHTMLGrid(w io.Writer, g *Grid) {
+	step := max(g.Step, 1)
+	fmt.Fprintf(w, "<div class=\"grid\" style=\"grid-template-columns:repeat(%d,12px)\">\n", max(g.Columns, 1))
+	for i, c := range g.Cells {
+		class := ""
+		if c.Mark {
+			class = ` class="mark"`
+		}
+		fmt.Fprintf(w, "<div%s style=\"background:%s\" title=\"%d\"></div>", class, c.Color, g.Start+i*step)
+	}
+	fmt.Fprintln(w, "\n</div>")
+	if len(g.Legend) > 0 {
+		fmt.Fprint(w, "<div class=\"legend\">")
+		for _, l := range g.Legend {
+			class := ""
+			if l.Mark {
+				class = ` class="mark"`
+			}
+			fmt.Fprintf(w, "<span%s style=\"background:%s\"></span>%s", class, l.Color, html.EscapeString(l.Label))
+		}
+		fmt.Fprintln(w, "</div>")
+	}
+}
+
+// writeNestedHTML dibuja los tramos anidados dentro de su tramo contenedor.
+func writeNestedHTML(w io.Writer, g barGroup) {
+	if len(g.Children) == 0 || g.Percent == 0 {
+		return
+	}
+	fmt.Fprint(w, "<div class=\"nested\">")
+	for _, seg := range g.Children {
+		fmt.Fprintf(w, "<div class=\"seg\" style=\"width:%.4f%%;background:%s\" title=\"%s\">%s<br>%.2f%%%s</div>",
+			seg.Percent/g.Percent*100, colorOr(seg.Color, "#ff9999"), segmentTitle(seg), html.EscapeString(seg.Label), seg.Percent, segmentDetail(seg))
+	}
+	fmt.Fprint(w, "</div>")
+}
+
+// segmentDetail devuelve las líneas de detalle de un tramo, cada una en su renglón.
+func segmentDetail(seg Segment) string {
+	var b strings.Builder
+	for _, d := range seg.Detail {
+		b.WriteString("<br><small>" + html.EscapeString(d) + "</small>")
+	}
+	return b.String()
+}
+
+// segmentTitle es el texto completo del tramo, para verlo al pasar el mouse sobre los angostos.
+func segmentTitle(seg Segment) string {
+	parts := append([]string{seg.Label, fmt.Sprintf("%.2f%%", seg.Percent)}, seg.Detail...)
+	return html.EscapeString(strings.Join(parts, " · "))
+}
+
+func colorOr(c, def string) string {
+	if c == "" {
+		return def
+	}
+	return c
+}
@@ -0,0 +1,136 @@
+// Package report es el modelo de datos de los reportes de rep y los renderizadores que
+// lo convierten a cada formato de salida. Los comandos solo leen el disco y arman un
+// Report; cómo se dibuja lo decide el renderizador registrado para el formato pedido
+// (ver Register y Write).
+package report
+
+// Report es un reporte listo para renderizar: un título y secciones en orden.
+type Report struct {
+	Name     string    `json:"name"`  // nombre del reporte en rep (mbr, disk, tree...)
+	Title    string    `json:"title"` // encabezado, ej. "REPORTE DE MBR"
+	Sections []Section `json:"sections"`
+}
+
+// Section es un bloque del reporte. Normalmente usa solo uno de sus campos de contenido;
+// los renderizadores dibujan los que estén presentes en este orden: Fields, Table, Bar,
+// Grid, Graph, Text.
+type Section struct {
+	Title  string    `json:"title,omitempty"`
+	Color  string    `json:"color,omitempty"` // color de fondo "#rrggbb"; vacío = gris
+	Fields []Field   `json:"fields,omitempty"`
+	Table  *Table    `json:"table,omitempty"`
+	Bar    []Segment `json:"bar,omitempty"`
+	Grid   *Grid     `json:"grid,omitempty"`
+	Graph  *Graph    `json:"graph,omitempty"`
+	Text   string    `json:"text,omitempty"` // texto preformateado (bitmaps, contenido de archivos)
+}
+
+// Field es un par campo/valor.
+type Field struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Table es una tabla con encabezados.
+type Table struct {
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+// Segment es un tramo de una barra proporcional (el reporte disk). Percent va de 0 a 100;
+// los tramos Nested se dibujan dentro del tramo anterior que no lo sea (lógicas dentro
+// de la extendida). Detail son líneas extra bajo la etiqueta y el porcentaje (rango de
+// bytes, ID de montaje); si el tramo es angosto se omiten en las imágenes.
+type Segment struct {
+	Label   string   `json:"label"`
+	Percent float64  `json:"percent"`
+	Color   string   `json:"color,omitempty"`
+	Nested  bool     `json:"nested,omitempty"`
+	Detail  []string `json:"detail,omitempty"`
+}
+
+// Grid es un mapa de celdas de colores en filas de Columns celdas (el reporte heatmap:
+// una celda por bloque o por grupo de bloques).
+type Grid struct {
+	Columns int          `json:"columns"`
+	Start   int          `json:"start"` // número de la primera celda, para rotular las filas
+	Step    int          `json:"step"`  // cuánto avanza el número por celda (bloques por celda)
+	Cells   []Cell       `json:"cells"`
+	Legend  []LegendItem `json:"legend,omitempty"`
+}
+
+// Cell es una celda del mapa. Symbol la representa en texto; Mark la resalta con un borde.
+type Cell struct {
+	Color  string `json:"color"`
+	Symbol string `json:"symbol"`
+	Mark   bool   `json:"mark,omitempty"`
+}
+
+// LegendItem explica un color (y su símbolo en texto) del mapa.
+type LegendItem struct {
+	Label  string `json:"label"`
+	Color  string `json:"color"`
+	Symbol string `json:"symbol"`
+	Mark   bool   `json:"mark,omitempty"`
+}
+
+// Graph es un grafo dirigido de nodos tipo registro (inode, block, tree).
+type Graph struct {
+	Direction string    `json:"direction"` // "LR" o "TB"
+	Clusters  []Cluster `json:"clusters,omitempty"`
+	Nodes     []Node    `json:"nodes"`
+	Edges     []Edge    `json:"edges"`
+}
+
+// Cluster agrupa nodos, ej. "Inodos" y "Bloques" en el reporte tree.
+type Cluster struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Color string `json:"color,omitempty"` // color del borde
+}
+
+// Node es un nodo del grafo; Cells son los campos del registro, el primero es el título.
+type Node struct {
+	ID      string   `json:"id"`
+	Cells   []string `json:"cells"`
+	Color   string   `json:"color,omitempty"`
+	Cluster string   `json:"cluster,omitempty"`
+}
+
+// Edge es una arista entre dos nodos, con etiqueta opcional.
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label,omitempty"`
+}
+
+// HasGraph indica si alguna sección es un grafo.
+func (r *Report) HasGraph() bool {
+	for _, s := range r.Sections {
+		if s.Graph != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// barGroup es un tramo de primer nivel con los tramos anidados que lo siguen.
+type barGroup struct {
+	Segment
+	Children []Segment
+}
+
+// barGroups agrupa la barra para dibujarla: cada tramo Nested queda dentro del último
+// tramo de primer nivel (o suelto si no hay ninguno antes).
+func barGroups(bar []Segment) []barGroup {
+	var groups []barGroup
+	for _, seg := range bar {
+		if seg.Nested && len(groups) > 0 {
+			last := &groups[len(groups)-1]
+			last.Children = append(last.Children, seg)
+			continue
+		}
+		groups = append(groups, barGroup{Segment: seg})
+	}
+	return groups
+}
@@ -0,0 +1,173 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PDF de una sola página escrito a mano, con las fuentes estándar de PDF (no hace
+// falta incrustar nada). Lo que no entra en WinAnsi se reemplaza: "→" por "->", el
+// resto por "?".
+
+// maxPDFSide es el lado máximo de página que aceptan los lectores (200 pulgadas);
+// los reportes más grandes se escalan.
+const maxPDFSide = 14400.0
+
+// pdfFonts son las fuentes de cada estilo: nombre del recurso, fuente base y ancho
+// medio de un carácter en unidades de tamaño (para centrar).
+var pdfFonts = map[fontStyle]struct {
+	res, base string
+	size      float64
+	avgWidth  float64
+}{
+	fontBold:  {"F1", "Helvetica-Bold", 14, 0.58},
+	fontMono:  {"F2", "Courier", 12, 0.6},
+	fontSmall: {"F3", "Helvetica", 11, 0.52},
+}
+
+// pdfPainter escribe los operadores de la página. PDF tiene el origen abajo a la
+// izquierda: las y se invierten con la altura de la página.
+type pdfPainter struct {
+	b      bytes.Buffer
+	height float64
+}
+
+func pdfColor(hex string) (r, g, b float64) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return 0, 0, 0
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0
+	}
+	return float64(v>>16&0xff) / 255, float64(v>>8&0xff) / 255, float64(v&0xff) / 255
+}
+
+func (p *pdfPainter) fill(color string) {
+	r, g, b := pdfColor(color)
+	fmt.Fprintf(&p.b, "%.3f %.3f %.3f rg\n", r, g, b)
+}
+
+func (p *pdfPainter) stroke(color string) {
+	r, g, b := pdfColor(color)
+	fmt.Fprintf(&p.b, "%.3f %.3f %.3f RG 1 w\n", r, g, b)
+}
+
+func (p *pdfPainter) rect(x, y, w, h float64, color string) {
+	p.fill(color)
+	fmt.Fprintf(&p.b, "%.2f %.2f %.2f %.2f re f\n", x, p.height-y-h, w, h)
+}
+
+func (p *pdfPainter) strokeRect(x, y, w, h float64, color string, dashed bool) {
+	p.stroke(color)
+	if dashed {
+		p.b.WriteString("[4 2] 0 d\n")
+	}
+	fmt.Fprintf(&p.b, "%.2f %.2f %.2f %.2f re S\n", x, p.height-y-h, w, h)
+	if dashed {
+		p.b.WriteString("[] 0 d\n")
+	}
+}
+
+func (p *pdfPainter) line(x1, y1, x2, y2 float64, color string) {
+	p.stroke(color)
+	fmt.Fprintf(&p.b, "%.2f %.2f m %.2f %.2f l S\n", x1, p.height-y1, x2, p.height-y2)
+}
+
+func (p *pdfPainter) curve(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, color string) {
+	p.stroke(color)
+	h := p.height
+	fmt.Fprintf(&p.b, "%.2f %.2f m %.2f %.2f %.2f %.2f %.2f %.2f c S\n",
+		x1, h-y1, cx1, h-cy1, cx2, h-cy2, x2, h-y2)
+}
+
+func (p *pdfPainter) polygon(points []float64, color string) {
+	if len(points) < 4 {
+		return
+	}
+	p.fill(color)
+	fmt.Fprintf(&p.b, "%.2f %.2f m", points[0], p.height-points[1])
+	for i := 2; i+1 < len(points); i += 2 {
+		fmt.Fprintf(&p.b, " %.2f %.2f l", points[i], p.height-points[i+1])
+	}
+	p.b.WriteString(" h f\n")
+}
+
+func (p *pdfPainter) text(s string, x, y, ax float64, color string, f fontStyle) {
+	font := pdfFonts[f]
+	s = strings.ReplaceAll(s, "→", "->")
+	x -= ax * float64(utf8.RuneCountInString(s)) * font.size * font.avgWidth
+	baseline := p.height - y - font.size*0.35
+	p.fill(color)
+	fmt.Fprintf(&p.b, "BT /%s %.0f Tf %.2f %.2f Td (%s) Tj ET\n", font.res, font.size, x, baseline, pdfString(s))
+}
+
+// pdfString pasa s a WinAnsi (Latin-1 en lo que importa aquí) y escapa los paréntesis.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32:
+			b.WriteByte(' ')
+		case r < 128:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func renderPDF(w io.Writer, r *Report) error {
+	doc := newCanvasDoc(r)
+	p := &pdfPainter{height: doc.height}
+	p.fill("#ffffff")
+	fmt.Fprintf(&p.b, "0 0 %.2f %.2f re f\n", doc.width, doc.height)
+	doc.draw(p)
+
+	scale := min(1, maxPDFSide/doc.width, maxPDFSide/doc.height)
+	content := fmt.Sprintf("%.4f 0 0 %.4f 0 0 cm\n", scale, scale) + p.b.String()
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Contents 4 0 R "+
+			"/Resources << /Font << /F1 5 0 R /F2 6 0 R /F3 7 0 R >> >> >>", doc.width*scale, doc.height*scale),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+	}
+	for _, style := range []fontStyle{fontBold, fontMono, fontSmall} {
+		objects = append(objects, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>",
+			pdfFonts[style].base))
+	}
+
+	bw := bufio.NewWriter(w)
+	offset := 0
+	write := func(s string) {
+		n, _ := bw.WriteString(s)
+		offset += n
+	}
+	write("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = offset
+		write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", i+1, obj))
+	}
+	xref := offset
+	write(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(objects)+1))
+	for _, off := range offsets {
+		write(fmt.Sprintf("%010d 00000 n \n", off))
+	}
+	write(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref))
+	return bw.Flush()
+}
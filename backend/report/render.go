@@ -0,0 +1,145 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Renderer convierte un reporte a un formato de salida.
+type Renderer interface {
+	Render(w io.Writer, r *Report) error
+}
+
+// RendererFunc permite usar una función como Renderer.
+type RendererFunc func(w io.Writer, r *Report) error
+
+// Render implementa Renderer.
+func (f RendererFunc) Render(w io.Writer, r *Report) error { return f(w, r) }
+
+var renderers = map[string]Renderer{}
+
+// Register asocia un renderizador a un formato (la extensión, en minúsculas). Un
+// registro posterior para el mismo formato reemplaza al anterior.
+func Register(format string, r Renderer) {
+	renderers[strings.ToLower(format)] = r
+}
+
+// Formats devuelve los formatos registrados, ordenados.
+func Formats() []string {
+	var out []string
+	for f := range renderers {
+		out = append(out, f)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// aliases son extensiones que se escriben con el renderizador de otro formato.
+var aliases = map[string]string{"jpeg": "jpg", "htm": "html", "gv": "dot", "text": "txt"}
+
+// Normalize pasa un formato o extensión a su nombre registrado ("JPEG" → "jpg").
+func Normalize(format string) string {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if a, ok := aliases[format]; ok {
+		return a
+	}
+	return format
+}
+
+// FormatFromPath deduce el formato de la extensión de path; "" si no tiene una conocida.
+func FormatFromPath(path string) string {
+	f := Normalize(filepath.Ext(path))
+	if _, ok := renderers[f]; !ok {
+		return ""
+	}
+	return f
+}
+
+// contentTypes son los tipos MIME de cada formato, para servir reportes por HTTP.
+var contentTypes = map[string]string{
+	"txt":  "text/plain; charset=utf-8",
+	"json": "application/json",
+	"html": "text/html; charset=utf-8",
+	"dot":  "text/vnd.graphviz; charset=utf-8",
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"svg":  "image/svg+xml",
+	"pdf":  "application/pdf",
+}
+
+// ContentType devuelve el tipo MIME del formato; application/octet-stream si no lo conoce.
+func ContentType(format string) string {
+	if ct, ok := contentTypes[Normalize(format)]; ok {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// Supported indica si hay un renderizador para el formato.
+func Supported(format string) bool {
+	_, ok := renderers[Normalize(format)]
+	return ok
+}
+
+// Render escribe el reporte en w con el renderizador del formato.
+func Render(w io.Writer, format string, r *Report) error {
+	renderer, ok := renderers[Normalize(format)]
+	if !ok {
+		return fmt.Errorf("formato '%s' no soportado (disponibles: %s)", format, strings.Join(Formats(), ", "))
+	}
+	return renderer.Render(w, r)
+}
+
+// Write renderiza el reporte en el archivo path, creando sus carpetas. Si el
+// renderizado falla no deja un archivo a medias.
+func Write(path, format string, r *Report) error {
+	if _, ok := renderers[Normalize(format)]; !ok {
+		return Render(nil, format, r) // mismo mensaje de formato no soportado
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("no se pudieron crear las carpetas de %s: %w", path, err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Render(f, format, r); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// Los formatos de imagen se dibujan aquí mismo; para los grafos se usa Graphviz si
+// está instalado, porque su layout es mejor (ver imageRenderer).
+func init() {
+	Register("txt", RendererFunc(renderText))
+	Register("json", RendererFunc(renderJSON))
+	Register("html", RendererFunc(renderHTML))
+	Register("dot", RendererFunc(renderDOT))
+	Register("png", imageRenderer("png", renderCanvasPNG))
+	Register("jpg", imageRenderer("jpg", renderCanvasJPG))
+	Register("svg", imageRenderer("svg", renderSVG))
+	Register("pdf", imageRenderer("pdf", renderPDF))
+}
+
+// imageRenderer pasa los reportes con grafos por Graphviz cuando el comando dot está
+// disponible y usa own para el resto, o si dot no está o falla.
+func imageRenderer(format string, own RendererFunc) Renderer {
+	return RendererFunc(func(w io.Writer, r *Report) error {
+		if r.HasGraph() && graphvizAvailable() {
+			var buf bytes.Buffer
+			if err := graphviz(format)(&buf, r); err == nil {
+				_, err = buf.WriteTo(w)
+				return err
+			}
+		}
+		return own(w, r)
+	})
+}
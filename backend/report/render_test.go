@@ -0,0 +1,177 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sampleReport usa todos los tipos de sección del modelo.
+func sampleReport() *Report {
+	return &Report{Name: "demo", Title: "REPORTE DE PRUEBA", Sections: []Section{
+		{Title: "Partición", Color: "#ccddee", Fields: []Field{{"Nombre", "Part1"}, {"Tamaño", "3 MB"}}},
+		{Title: "Tabla", Table: &Table{Columns: []string{"Inodo", "Nombre"}, Rows: [][]string{{"0", "/"}, {"12", "ñandú.txt"}}}},
+		{Bar: []Segment{
+			{Label: "MBR", Percent: 0.5},
+			{Label: "Extendida", Percent: 60},
+			{Label: "Lógica", Percent: 40, Nested: true, Detail: []string{"id 351B"}},
+			{Label: "Libre", Percent: 39.5},
+		}},
+		{Grid: &Grid{
+			Columns: 4, Step: 2,
+			Cells:  []Cell{{"#000000", "#", false}, {"#ffffff", ".", false}, {"#000000", "#", true}, {"#ffffff", ".", false}, {"#000000", "#", false}},
+			Legend: []LegendItem{{"usado", "#000000", "#", false}, {"libre", "#ffffff", ".", false}},
+		}},
+		{Graph: &Graph{
+			Direction: "LR",
+			Clusters:  []Cluster{{ID: "inodos", Label: "Inodos"}},
+			Nodes:     []Node{{ID: "i0", Cells: []string{"Inodo 0", "tipo carpeta"}, Cluster: "inodos"}, {ID: "b0", Cells: []string{"Bloque 0"}}},
+			Edges:     []Edge{{From: "i0", To: "b0", Label: "I_block[0]"}},
+		}},
+		{Text: "línea 1\nlínea 2\n"},
+	}}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	want := sampleReport()
+	var buf bytes.Buffer
+	if err := Render(&buf, "json", want); err != nil {
+		t.Fatal(err)
+	}
+	var got Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("el JSON no se puede leer: %v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(&got, want) {
+		t.Errorf("el reporte cambió al pasar por JSON:\n%+v\n%+v", got, *want)
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	want := `REPORTE DE PRUEBA
+=================
+
+Partición
+---------
+Nombre  Part1
+Tamaño  3 MB
+
+Tabla
+-----
+Inodo  Nombre
+-----  ---------
+0      /
+12     ñandú.txt
+
+MBR                    0.50%
+Extendida             60.00%
+  Lógica              40.00%  id 351B
+Libre                 39.50%
+
+      0  #.#.
+      8  #
+
+#  usado
+.  libre
+
+[i0] Inodo 0 | tipo carpeta
+[b0] Bloque 0
+i0 -> b0 (I_block[0])
+
+línea 1
+línea 2
+`
+	var buf bytes.Buffer
+	if err := Render(&buf, "TXT", sampleReport()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want {
+		t.Errorf("txt =\n%s\nse esperaba\n%s", buf.String(), want)
+	}
+
+	// El reporte file (sin título, solo texto) sale byte a byte
+	raw := "a,b\n\tc\x00"
+	buf.Reset()
+	Render(&buf, "txt", &Report{Name: "file", Sections: []Section{{Text: raw}}})
+	if buf.String() != raw {
+		t.Errorf("file en txt = %q, se esperaba %q", buf.String(), raw)
+	}
+}
+
+func TestRenderHTMLEscapes(t *testing.T) {
+	r := &Report{Title: "<b>", Sections: []Section{{Fields: []Field{{"ruta", "/a/<script>"}}}}}
+	var buf bytes.Buffer
+	if err := Render(&buf, "html", r); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "<script>") || !strings.Contains(buf.String(), "&lt;script&gt;") {
+		t.Errorf("html sin escapar:\n%s", buf.String())
+	}
+}
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		path, format, contentType string
+	}{
+		{"/r/tree.svg", "svg", "image/svg+xml"},
+		{"/r/foto.JPEG", "jpg", "image/jpeg"},
+		{"/r/pagina.htm", "html", "text/html; charset=utf-8"},
+		{"/r/grafo.gv", "dot", "text/vnd.graphviz; charset=utf-8"},
+		{"/r/sb.json", "json", "application/json"},
+		{"/r/bitmap.txt", "txt", "text/plain; charset=utf-8"},
+		{"/r/sin_extension", "", "application/octet-stream"},
+		{"/r/raro.xyz", "", "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		if got := FormatFromPath(tt.path); got != tt.format {
+			t.Errorf("FormatFromPath(%q) = %q, se esperaba %q", tt.path, got, tt.format)
+		}
+		if got := ContentType(filepath.Ext(tt.path)); got != tt.contentType {
+			t.Errorf("ContentType(%q) = %q, se esperaba %q", filepath.Ext(tt.path), got, tt.contentType)
+		}
+	}
+	for _, f := range []string{"txt", "json", "html", "dot", "png", "jpg", "svg", "pdf"} {
+		if !Supported(f) {
+			t.Errorf("el formato %s no está registrado", f)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "sub", "sb.json")
+	if err := Write(path, "json", sampleReport()); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || !json.Valid(data) {
+		t.Errorf("Write no dejó un JSON válido en %s: %v", path, err)
+	}
+
+	if err := Write(filepath.Join(dir, "x.xyz"), "xyz", sampleReport()); err == nil || !strings.Contains(err.Error(), "no soportado") {
+		t.Errorf("Write con formato desconocido: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "x.xyz")); !os.IsNotExist(err) {
+		t.Error("Write con formato desconocido creó el archivo")
+	}
+
+	// Si el renderizador falla no queda un archivo a medias
+	Register("falla", RendererFunc(func(w io.Writer, r *Report) error {
+		io.WriteString(w, "a medias")
+		return errors.New("falla")
+	}))
+	t.Cleanup(func() { delete(renderers, "falla") })
+	broken := filepath.Join(dir, "r.falla")
+	if err := Write(broken, "falla", sampleReport()); err == nil {
+		t.Error("Write no devolvió el error del renderizador")
+	}
+	if _, err := os.Stat(broken); !os.IsNotExist(err) {
+		t.Error("Write dejó el archivo de un renderizado fallido")
+	}
+}
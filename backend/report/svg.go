@@ -0,0 +1,71 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// svgPainter acumula los elementos de un SVG.
+type svgPainter struct {
+	b strings.Builder
+}
+
+func (p *svgPainter) rect(x, y, w, h float64, color string) {
+	fmt.Fprintf(&p.b, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"/>\n", x, y, w, h, color)
+}
+
+func (p *svgPainter) strokeRect(x, y, w, h float64, color string, dashed bool) {
+	dash := ""
+	if dashed {
+		dash = ` stroke-dasharray="4 2"`
+	}
+	fmt.Fprintf(&p.b, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"none\" stroke=\"%s\"%s/>\n",
+		x, y, w, h, color, dash)
+}
+
+func (p *svgPainter) line(x1, y1, x2, y2 float64, color string) {
+	fmt.Fprintf(&p.b, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"%s\"/>\n", x1, y1, x2, y2, color)
+}
+
+func (p *svgPainter) curve(x1, y1, cx1, cy1, cx2, cy2, x2, y2 float64, color string) {
+	fmt.Fprintf(&p.b, "<path d=\"M%.1f,%.1f C%.1f,%.1f %.1f,%.1f %.1f,%.1f\" fill=\"none\" stroke=\"%s\"/>\n",
+		x1, y1, cx1, cy1, cx2, cy2, x2, y2, color)
+}
+
+func (p *svgPainter) polygon(points []float64, color string) {
+	pts := make([]string, 0, len(points)/2)
+	for i := 0; i+1 < len(points); i += 2 {
+		pts = append(pts, fmt.Sprintf("%.1f,%.1f", points[i], points[i+1]))
+	}
+	fmt.Fprintf(&p.b, "<polygon points=\"%s\" fill=\"%s\"/>\n", strings.Join(pts, " "), color)
+}
+
+func (p *svgPainter) text(s string, x, y, ax float64, color string, f fontStyle) {
+	anchor := "start"
+	if ax == 0.5 {
+		anchor = "middle"
+	}
+	family, size, weight := "DejaVu Sans, sans-serif", 14, "bold"
+	switch f {
+	case fontMono:
+		family, size, weight = "DejaVu Sans Mono, monospace", 12, "normal"
+	case fontSmall:
+		size, weight = 11, "normal"
+	}
+	fmt.Fprintf(&p.b, "<text x=\"%.1f\" y=\"%.1f\" fill=\"%s\" font-family=\"%s\" font-size=\"%d\" font-weight=\"%s\" "+
+		"text-anchor=\"%s\" dominant-baseline=\"middle\" xml:space=\"preserve\">%s</text>\n",
+		x, y, color, family, size, weight, anchor, html.EscapeString(s))
+}
+
+func renderSVG(w io.Writer, r *Report) error {
+	doc := newCanvasDoc(r)
+	p := &svgPainter{}
+	doc.draw(p)
+	width, height := int(doc.width), int(doc.height)
+	_, err := fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n"+
+		"<rect width=\"100%%\" height=\"100%%\" fill=\"#ffffff\"/>\n%s</svg>\n",
+		width, height, width, height, p.b.String())
+	return err
+}
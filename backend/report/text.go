@@ -0,0 +1,134 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// renderText escribe el reporte como texto plano con columnas alineadas. Un reporte
+// sin título con una sola sección de texto (el reporte file) sale tal cual, byte a byte.
+func renderText(w io.Writer, r *Report) error {
+	bw := bufio.NewWriter(w)
+	if r.Title != "" {
+		fmt.Fprintf(bw, "%s\n%s\n\n", r.Title, strings.Repeat("=", utf8.RuneCountInString(r.Title)))
+	}
+	for i, s := range r.Sections {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		if s.Title != "" {
+			fmt.Fprintf(bw, "%s\n%s\n", s.Title, strings.Repeat("-", utf8.RuneCountInString(s.Title)))
+		}
+		if len(s.Fields) > 0 {
+			width := 0
+			for _, f := range s.Fields {
+				width = max(width, utf8.RuneCountInString(f.Key))
+			}
+			for _, f := range s.Fields {
+				fmt.Fprintf(bw, "%s  %s\n", pad(f.Key, width), f.Value)
+			}
+		}
+		if s.Table != nil {
+			writeTextTable(bw, s.Table)
+		}
+		for _, seg := range s.Bar {
+			indent := ""
+			if seg.Nested {
+				indent = "  "
+			}
+			fmt.Fprintf(bw, "%s%s %6.2f%%", indent, pad(seg.Label, 20-len(indent)), seg.Percent)
+			if len(seg.Detail) > 0 {
+				fmt.Fprintf(bw, "  %s", strings.Join(seg.Detail, "  "))
+			}
+			bw.WriteString("\n")
+		}
+		if s.Grid != nil {
+			writeTextGrid(bw, s.Grid)
+		}
+		if g := s.Graph; g != nil {
+			for _, n := range g.Nodes {
+				fmt.Fprintf(bw, "[%s] %s\n", n.ID, strings.Join(n.Cells, " | "))
+			}
+			for _, e := range g.Edges {
+				if e.Label != "" {
+					fmt.Fprintf(bw, "%s -> %s (%s)\n", e.From, e.To, e.Label)
+				} else {
+					fmt.Fprintf(bw, "%s -> %s\n", e.From, e.To)
+				}
+			}
+		}
+		bw.WriteString(s.Text)
+	}
+	return bw.Flush()
+}
+
+// writeTextGrid escribe el mapa con un símbolo por celda, cada fila rotulada con el
+// número de su primera celda, y la leyenda al final.
+func writeTextGrid(w io.Writer, g *Grid) {
+	cols := max(g.Columns, 1)
+	step := max(g.Step, 1)
+	for row := 0; row*cols < len(g.Cells); row++ {
+		var line strings.Builder
+		for _, c := range g.Cells[row*cols : min((row+1)*cols, len(g.Cells))] {
+			line.WriteString(c.Symbol)
+		}
+		fmt.Fprintf(w, "%7d  %s\n", g.Start+row*cols*step, line.String())
+	}
+	if len(g.Legend) > 0 {
+		fmt.Fprintln(w)
+		for _, l := range g.Legend {
+			fmt.Fprintf(w, "%s  %s\n", l.Symbol, l.Label)
+		}
+	}
+}
+
+func writeTextTable(w io.Writer, t *Table) {
+	widths := make([]int, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = utf8.RuneCountInString(c)
+	}
+	for _, row := range t.Rows {
+		for i := 0; i < len(row) && i < len(widths); i++ {
+			widths[i] = max(widths[i], utf8.RuneCountInString(row[i]))
+		}
+	}
+	line := func(cells []string) {
+		parts := make([]string, len(widths))
+		for i := range widths {
+			if i < len(cells) {
+				parts[i] = pad(cells[i], widths[i])
+			} else {
+				parts[i] = pad("", widths[i])
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(parts, "  "), " "))
+	}
+	line(t.Columns)
+	sep := make([]string, len(widths))
+	for i, wd := range widths {
+		sep[i] = strings.Repeat("-", wd)
+	}
+	line(sep)
+	for _, row := range t.Rows {
+		line(row)
+	}
+}
+
+// pad completa s con espacios hasta width caracteres (no bytes: hay tildes).
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// renderJSON escribe el modelo tal cual, indentado.
+func renderJSON(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
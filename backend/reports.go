@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"proyecto1/commands"
+	"proyecto1/report"
+	"strconv"
+)
+
+// Reportes por HTTP: se generan en memoria y se devuelven en la respuesta, así el
+// frontend los puede mostrar sin acceso al sistema de archivos del servidor.
+//
+//	GET /reports                       lista de reportes y formatos
+//	GET /reports/{id}/{name}?format=svg&path=/users.txt[&download=1]
+//
+// path es la ruta dentro de la partición que usan file, ls y du (el -path_file_ls de rep);
+// du acepta depth y journaling operation, prefix, user, from y to.
+
+// reportsIndexHandler responde la lista de reportes y formatos disponibles.
+func reportsIndexHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{
+		"reports": commands.ReportNames,
+		"formats": report.Formats(),
+	})
+}
+
+// reportHandler genera el reporte {name} de la partición {id}.
+func reportHandler(w http.ResponseWriter, r *http.Request) {
+	id, name := r.PathValue("id"), r.PathValue("name")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "svg"
+	}
+	format = report.Normalize(format)
+	if !report.Supported(format) {
+		http.Error(w, fmt.Sprintf("formato '%s' no soportado", format), http.StatusBadRequest)
+		return
+	}
+
+	var buf bytes.Buffer
+	err := func() error {
+		// El estado (particiones montadas, sesión) es global: igual que /execute
+		serverMu.Lock()
+		defer serverMu.Unlock()
+		rep, err := commands.BuildReport(name, id, reportOptions(r))
+		if err != nil {
+			return err
+		}
+		return report.Render(&buf, format, rep)
+	}()
+	if err != nil {
+		http.Error(w, err.Error(), reportStatus(err))
+		return
+	}
+
+	disposition := "inline"
+	if r.URL.Query().Get("download") != "" {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Type", report.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, name+"."+format))
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := buf.WriteTo(w); err != nil {
+		log.Printf("Error al enviar el reporte %s: %v", name, err)
+	}
+}
+
+// reportOptions lee de la query los parámetros que usan algunos reportes.
+func reportOptions(r *http.Request) commands.ReportOptions {
+	q := r.URL.Query()
+	depth, err := strconv.Atoi(q.Get("depth"))
+	if err != nil {
+		depth = -1 // sin límite
+	}
+	recursive, _ := strconv.ParseBool(q.Get("recursive"))
+	return commands.ReportOptions{
+		PathFileLS: q.Get("path"),
+		Depth:      depth,
+		Recursive:  recursive,
+		Sort:       q.Get("sort"),
+		Journal: commands.JournalFilter{
+			Operation:  q.Get("operation"),
+			PathPrefix: q.Get("prefix"),
+			User:       q.Get("user"),
+			From:       q.Get("from"),
+			To:         q.Get("to"),
+		},
+	}
+}
+
+// reportStatus elige el código HTTP para un error al generar un reporte.
+func reportStatus(err error) int {
+	switch {
+	case errors.Is(err, commands.ErrUnknownReport), errors.Is(err, commands.ErrNotMounted):
+		return http.StatusNotFound
+	case errors.Is(err, commands.ErrNoSession):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
@@ -4,6 +4,10 @@ type Session struct {
 	User string
 	PartitionID string
 	IsActive bool
+	UID int32 // UID y GID principal del usuario (se cargan al iniciar sesión)
+	GID int32
+	Umask uint16 // umask del usuario en bits octales (ej. 0o022)
+	Groups []int32 // GIDs de los grupos suplementarios del usuario (se cargan al iniciar sesión)
 }
 
 var CurrentSession Session
\ No newline at end of file
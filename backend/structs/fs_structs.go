@@ -2,9 +2,12 @@ package structs
 
 const NAME_MAX = 64 // ajustar según lo que necesites
 
-// Asegura que el FileBlock sea mayor o igual al tamaño del FolderBlock.
-// Cada ContentEntry ocupa NAME_MAX bytes + 4 bytes para B_inodo.
-// FolderBlock tiene 4 entradas, así que reservar suficiente espacio para FileBlock.
+// CONTENT_ENTRY_SIZE es el tamaño en disco de un ContentEntry: NAME_MAX bytes + 4 bytes para B_inodo.
+const CONTENT_ENTRY_SIZE = NAME_MAX + 4
+
+// FILE_BLOCK_SIZE es el tamaño de bloque por defecto de mkfs.
+// Alcanza para 4 entradas de carpeta; con -blocksize se puede formatear con bloques más grandes,
+// y el tamaño real de cada partición queda guardado en S_block_size del superbloque.
 const FILE_BLOCK_SIZE = 4*NAME_MAX + 16
 
 type ContentEntry struct {
@@ -13,12 +16,14 @@ type ContentEntry struct {
 }
 
 // FolderBlock es la estructura para un bloque de directorio.
+// La cantidad de entradas depende del tamaño de bloque (S_block_size / CONTENT_ENTRY_SIZE),
+// por eso se crea con fs.NewFolderBlock y no con un valor cero.
 type FolderBlock struct {
-	B_content [4]ContentEntry
+	B_content []ContentEntry
 }
 
 // FileBlock es la estructura para un bloque de contenido de archivo.
-// Aumentado para que su tamaño >= FolderBlock y evitar truncamientos.
+// B_content mide exactamente S_block_size bytes (ver fs.NewFileBlock).
 type FileBlock struct {
-	B_content [FILE_BLOCK_SIZE]byte
+	B_content []byte
 }
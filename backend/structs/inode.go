@@ -9,6 +9,8 @@ type Inode struct {
 	I_ctime int64     // Fecha de creación (Unix timestamp)
 	I_mtime int64     // Última fecha de modificación (Unix timestamp)
 	I_block [15]int32 // Bloques de datos (12 directos, 1 indirecto, 1 doble, 1 triple)
-	I_type  int32     // Tipo (1: archivo, 0: carpeta)
+	I_type  int32     // Tipo (1: archivo, 0: carpeta, 2: enlace simbólico)
 	I_perm  int32     // Permisos (formato UGO - ej. 664)
+	I_links int32     // Cantidad de entradas de carpeta que apuntan al inodo (hard links)
+	I_acl   int32     // Bloque con la ACL extendida (-1: sin ACL)
 }
\ No newline at end of file
@@ -0,0 +1,39 @@
+package users
+
+import (
+	"fmt"
+	"os"
+
+	"proyecto1/fs"
+	"proyecto1/structs"
+)
+
+// Load lee /users.txt de la partición y lo interpreta.
+func Load(file *os.File, sb structs.Superblock) (*Table, error) {
+	inode, _, err := fs.FindInodeByPathNoCheck(file, sb, Path)
+	if err != nil {
+		return nil, fmt.Errorf("no se encontró %s: %w", Path, err)
+	}
+	content, err := fs.ReadFileContent(file, sb, inode)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer %s: %w", Path, err)
+	}
+	return Parse(string(content)), nil
+}
+
+// Save valida la tabla y reescribe /users.txt de forma atómica (ver fs.ReplaceFileContent):
+// si falta espacio a mitad de camino, el archivo anterior queda intacto.
+func Save(file *os.File, sb structs.Superblock, t *Table) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	_, inodeIndex, err := fs.FindInodeByPathNoCheck(file, sb, Path)
+	if err != nil {
+		return fmt.Errorf("no se encontró %s: %w", Path, err)
+	}
+	if err := fs.ReplaceFileContent(file, sb, inodeIndex, []byte(t.String())); err != nil {
+		return fmt.Errorf("error al escribir %s: %w", Path, err)
+	}
+	return nil
+}
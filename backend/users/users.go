@@ -0,0 +1,453 @@
+// Package users interpreta y modifica /users.txt, el archivo de usuarios y grupos de
+// cada partición. Cada línea es un grupo "GID,G,nombre" o un usuario
+// "UID,U,grupo,nombre,contraseña[,grupos[,umask]]"; un ID 0 marca el registro como eliminado.
+// El sexto campo, opcional, lista los grupos suplementarios del usuario separados por ';'
+// y el séptimo su umask en octal (si falta se usa la umask por defecto, 002).
+package users
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"proyecto1/fs"
+)
+
+// Path es la ruta de users.txt dentro de la partición.
+const Path = "/users.txt"
+
+// RootName es el usuario y grupo que crea mkfs; no se pueden eliminar.
+const RootName = "root"
+
+// Group es un registro "GID,G,nombre".
+type Group struct {
+	GID  int32
+	Name string
+}
+
+// Deleted indica si el grupo fue eliminado con rmgrp.
+func (g *Group) Deleted() bool { return g.GID == 0 }
+
+// User es un registro "UID,U,grupo,nombre,contraseña[,grupos[,umask]]".
+// Password es lo que hay guardado en el archivo (normalmente un hash bcrypt) y Groups
+// los grupos suplementarios, además del grupo principal Group.
+type User struct {
+	UID      int32
+	Group    string
+	Name     string
+	Password string
+	Groups   []string
+	Umask    fs.Perm
+}
+
+// Deleted indica si el usuario fue eliminado con rmusr.
+func (u *User) Deleted() bool { return u.UID == 0 }
+
+// entry es una línea del archivo. Las líneas que no se pueden interpretar se
+// guardan en raw y se vuelven a escribir sin cambios.
+type entry struct {
+	group *Group
+	user  *User
+	raw   string
+}
+
+// Table es el contenido de users.txt en el mismo orden en que aparece en el archivo.
+type Table struct {
+	entries []entry
+}
+
+// Parse interpreta el contenido de users.txt. Nunca falla: las líneas mal formadas
+// se conservan pero no cuentan para las búsquedas.
+func Parse(content string) *Table {
+	t := &Table{}
+	for _, raw := range strings.Split(content, "\n") {
+		line := strings.Trim(raw, " \t\r\x00")
+		if line == "" {
+			continue
+		}
+		t.entries = append(t.entries, parseLine(line))
+	}
+	return t
+}
+
+func parseLine(line string) entry {
+	fields := strings.SplitN(line, ",", 7)
+	for i := range fields {
+		fields[i] = trimField(fields[i])
+	}
+	if len(fields) < 3 {
+		return entry{raw: line}
+	}
+	id, err := strconv.ParseInt(fields[0], 10, 32)
+	if err != nil || id < 0 {
+		return entry{raw: line}
+	}
+
+	switch {
+	case fields[1] == "G" && len(fields) == 3:
+		return entry{group: &Group{GID: int32(id), Name: fields[2]}}
+	case fields[1] == "U" && len(fields) >= 5:
+		u := &User{UID: int32(id), Group: fields[2], Name: fields[3], Password: fields[4], Umask: fs.DefaultUmask}
+		if len(fields) >= 6 {
+			for _, g := range strings.Split(fields[5], ";") {
+				if g = trimField(g); g != "" {
+					u.Groups = append(u.Groups, g)
+				}
+			}
+		}
+		if len(fields) == 7 {
+			if umask, err := fs.ParsePerm(fields[6]); err == nil {
+				u.Umask = umask
+			}
+		}
+		return entry{user: u}
+	}
+	return entry{raw: line}
+}
+
+// trimField quita espacios y comillas envolventes (ej. "usuario1" -> usuario1).
+func trimField(s string) string {
+	return strings.Trim(s, " \t\r\n\"")
+}
+
+// String devuelve el contenido de users.txt, una línea por registro.
+func (t *Table) String() string {
+	var sb strings.Builder
+	for _, e := range t.entries {
+		switch {
+		case e.group != nil:
+			fmt.Fprintf(&sb, "%d,G,%s\n", e.group.GID, e.group.Name)
+		case e.user != nil:
+			fmt.Fprintf(&sb, "%d,U,%s,%s,%s", e.user.UID, e.user.Group, e.user.Name, e.user.Password)
+			if len(e.user.Groups) > 0 || e.user.Umask != fs.DefaultUmask {
+				sb.WriteString("," + strings.Join(e.user.Groups, ";"))
+			}
+			if e.user.Umask != fs.DefaultUmask {
+				sb.WriteString("," + e.user.Umask.Octal())
+			}
+			sb.WriteString("\n")
+		default:
+			sb.WriteString(e.raw + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// Groups devuelve los grupos (incluidos los eliminados) en orden de aparición.
+func (t *Table) Groups() []*Group {
+	var groups []*Group
+	for _, e := range t.entries {
+		if e.group != nil {
+			groups = append(groups, e.group)
+		}
+	}
+	return groups
+}
+
+// Users devuelve los usuarios (incluidos los eliminados) en orden de aparición.
+func (t *Table) Users() []*User {
+	var users []*User
+	for _, e := range t.entries {
+		if e.user != nil {
+			users = append(users, e.user)
+		}
+	}
+	return users
+}
+
+// Group busca un grupo por nombre. Si hay uno activo y otros eliminados con el mismo
+// nombre, devuelve el activo. Devuelve nil si no existe.
+func (t *Table) Group(name string) *Group {
+	var deleted *Group
+	for _, g := range t.Groups() {
+		if g.Name != name {
+			continue
+		}
+		if !g.Deleted() {
+			return g
+		}
+		if deleted == nil {
+			deleted = g
+		}
+	}
+	return deleted
+}
+
+// GroupByGID busca un grupo activo por su GID. Devuelve nil si no existe.
+func (t *Table) GroupByGID(gid int32) *Group {
+	if gid == 0 {
+		return nil
+	}
+	for _, g := range t.Groups() {
+		if g.GID == gid {
+			return g
+		}
+	}
+	return nil
+}
+
+// User busca un usuario por nombre, dando prioridad al activo igual que Group.
+// Devuelve nil si no existe.
+func (t *Table) User(name string) *User {
+	var deleted *User
+	for _, u := range t.Users() {
+		if u.Name != name {
+			continue
+		}
+		if !u.Deleted() {
+			return u
+		}
+		if deleted == nil {
+			deleted = u
+		}
+	}
+	return deleted
+}
+
+// UserByUID busca un usuario activo por su UID. Devuelve nil si no existe.
+func (t *Table) UserByUID(uid int32) *User {
+	if uid == 0 {
+		return nil
+	}
+	for _, u := range t.Users() {
+		if u.UID == uid {
+			return u
+		}
+	}
+	return nil
+}
+
+// IDs devuelve el UID y el GID de un usuario activo.
+func (t *Table) IDs(name string) (int32, int32, error) {
+	u := t.User(name)
+	if u == nil || u.Deleted() {
+		return 0, 0, fmt.Errorf("el usuario '%s' no existe", name)
+	}
+	g := t.Group(u.Group)
+	if g == nil {
+		return 0, 0, fmt.Errorf("el grupo '%s' del usuario '%s' no existe", u.Group, name)
+	}
+	return u.UID, g.GID, nil
+}
+
+// GIDs devuelve los GID de los grupos suplementarios activos de un usuario.
+// Los grupos eliminados o inexistentes se ignoran.
+func (t *Table) GIDs(name string) []int32 {
+	u := t.User(name)
+	if u == nil || u.Deleted() {
+		return nil
+	}
+	var gids []int32
+	for _, group := range u.Groups {
+		if g := t.Group(group); g != nil && !g.Deleted() {
+			gids = append(gids, g.GID)
+		}
+	}
+	return gids
+}
+
+// ValidName revisa que un nombre de usuario o grupo se pueda guardar en una línea
+// de users.txt sin romper su formato.
+func ValidName(name string) error {
+	if name == "" {
+		return fmt.Errorf("el nombre no puede estar vacío")
+	}
+	if strings.ContainsAny(name, ",;\"\n\r\t ") {
+		return fmt.Errorf("el nombre '%s' no puede contener comas, punto y coma, comillas ni espacios", name)
+	}
+	return nil
+}
+
+// AddGroup agrega un grupo nuevo con el siguiente GID libre.
+func (t *Table) AddGroup(name string) (*Group, error) {
+	if err := ValidName(name); err != nil {
+		return nil, err
+	}
+	if g := t.Group(name); g != nil && !g.Deleted() {
+		return nil, fmt.Errorf("el grupo '%s' ya existe", name)
+	}
+	var maxID int32
+	for _, g := range t.Groups() {
+		if g.GID > maxID {
+			maxID = g.GID
+		}
+	}
+	g := &Group{GID: maxID + 1, Name: name}
+	t.entries = append(t.entries, entry{group: g})
+	return g, nil
+}
+
+// RemoveGroup marca un grupo como eliminado (GID 0).
+func (t *Table) RemoveGroup(name string) error {
+	if name == RootName {
+		return fmt.Errorf("el grupo '%s' no se puede eliminar", name)
+	}
+	g := t.Group(name)
+	if g == nil {
+		return fmt.Errorf("no se encontró el grupo '%s'", name)
+	}
+	if g.Deleted() {
+		return fmt.Errorf("el grupo '%s' ya estaba eliminado", name)
+	}
+	g.GID = 0
+	return nil
+}
+
+// AddUser agrega un usuario nuevo con el siguiente UID libre. password se guarda tal
+// cual, así que quien llama debe pasarlo ya cifrado.
+func (t *Table) AddUser(name, group, password string) (*User, error) {
+	if err := ValidName(name); err != nil {
+		return nil, err
+	}
+	if u := t.User(name); u != nil && !u.Deleted() {
+		return nil, fmt.Errorf("el usuario '%s' ya existe", name)
+	}
+	if g := t.Group(group); g == nil || g.Deleted() {
+		return nil, fmt.Errorf("el grupo '%s' no existe", group)
+	}
+	if password == "" || strings.ContainsAny(password, ",\n") {
+		return nil, fmt.Errorf("contraseña inválida para el usuario '%s'", name)
+	}
+	var maxID int32
+	for _, u := range t.Users() {
+		if u.UID > maxID {
+			maxID = u.UID
+		}
+	}
+	u := &User{UID: maxID + 1, Group: group, Name: name, Password: password, Umask: fs.DefaultUmask}
+	t.entries = append(t.entries, entry{user: u})
+	return u, nil
+}
+
+// RemoveUser marca un usuario como eliminado (UID 0).
+func (t *Table) RemoveUser(name string) error {
+	if name == RootName {
+		return fmt.Errorf("el usuario '%s' no se puede eliminar", name)
+	}
+	u := t.User(name)
+	if u == nil {
+		return fmt.Errorf("el usuario '%s' no existe", name)
+	}
+	if u.Deleted() {
+		return fmt.Errorf("el usuario '%s' ya está eliminado", name)
+	}
+	u.UID = 0
+	return nil
+}
+
+// SetUserGroup cambia el grupo de un usuario activo.
+func (t *Table) SetUserGroup(name, group string) error {
+	if g := t.Group(group); g == nil || g.Deleted() {
+		return fmt.Errorf("el grupo '%s' no existe", group)
+	}
+	u, err := t.activeUser(name)
+	if err != nil {
+		return err
+	}
+	u.Group = group
+	// Si ya era suplementario, deja de serlo: ahora es el principal
+	for i, g := range u.Groups {
+		if g == group {
+			u.Groups = append(u.Groups[:i], u.Groups[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// AddUserGroup agrega un grupo suplementario a un usuario activo.
+func (t *Table) AddUserGroup(name, group string) error {
+	u, err := t.activeUser(name)
+	if err != nil {
+		return err
+	}
+	if g := t.Group(group); g == nil || g.Deleted() {
+		return fmt.Errorf("el grupo '%s' no existe", group)
+	}
+	if u.Group == group {
+		return fmt.Errorf("'%s' ya es el grupo principal del usuario '%s'", group, name)
+	}
+	for _, g := range u.Groups {
+		if g == group {
+			return fmt.Errorf("el usuario '%s' ya pertenece al grupo '%s'", name, group)
+		}
+	}
+	u.Groups = append(u.Groups, group)
+	return nil
+}
+
+// RemoveUserGroup quita un grupo suplementario de un usuario activo.
+func (t *Table) RemoveUserGroup(name, group string) error {
+	u, err := t.activeUser(name)
+	if err != nil {
+		return err
+	}
+	for i, g := range u.Groups {
+		if g == group {
+			u.Groups = append(u.Groups[:i], u.Groups[i+1:]...)
+			return nil
+		}
+	}
+	if u.Group == group {
+		return fmt.Errorf("'%s' es el grupo principal del usuario '%s'; usa chgrp para cambiarlo", group, name)
+	}
+	return fmt.Errorf("el usuario '%s' no pertenece al grupo '%s'", name, group)
+}
+
+// SetUmask cambia la umask de un usuario activo.
+func (t *Table) SetUmask(name string, umask fs.Perm) error {
+	u, err := t.activeUser(name)
+	if err != nil {
+		return err
+	}
+	u.Umask = umask & 0o777
+	return nil
+}
+
+func (t *Table) activeUser(name string) (*User, error) {
+	u := t.User(name)
+	if u == nil {
+		return nil, fmt.Errorf("el usuario '%s' no existe", name)
+	}
+	if u.Deleted() {
+		return nil, fmt.Errorf("el usuario '%s' está eliminado", name)
+	}
+	return u, nil
+}
+
+// Validate revisa que no haya dos grupos o dos usuarios activos con el mismo nombre o ID.
+// Save la llama antes de escribir, para no dejar un users.txt ambiguo en disco.
+func (t *Table) Validate() error {
+	groupNames := map[string]bool{}
+	groupIDs := map[int32]bool{}
+	for _, g := range t.Groups() {
+		if g.Deleted() {
+			continue
+		}
+		if groupNames[g.Name] {
+			return fmt.Errorf("grupo '%s' duplicado en users.txt", g.Name)
+		}
+		if groupIDs[g.GID] {
+			return fmt.Errorf("GID %d duplicado en users.txt", g.GID)
+		}
+		groupNames[g.Name] = true
+		groupIDs[g.GID] = true
+	}
+
+	userNames := map[string]bool{}
+	userIDs := map[int32]bool{}
+	for _, u := range t.Users() {
+		if u.Deleted() {
+			continue
+		}
+		if userNames[u.Name] {
+			return fmt.Errorf("usuario '%s' duplicado en users.txt", u.Name)
+		}
+		if userIDs[u.UID] {
+			return fmt.Errorf("UID %d duplicado en users.txt", u.UID)
+		}
+		userNames[u.Name] = true
+		userIDs[u.UID] = true
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package users
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"proyecto1/fs"
+	"proyecto1/fs/fstest"
+	"proyecto1/structs"
+)
+
+const baseUsers = "1,G,root\n1,U,root,root,123\n"
+
+func TestParseString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "mkfs", in: baseUsers, want: baseUsers},
+		{
+			name: "espacios, comillas y CRLF",
+			in:   " 1 , G , root \r\n1,U,\"root\",\"root\",123\r\n\n\n",
+			want: baseUsers,
+		},
+		{
+			name: "grupos suplementarios y umask",
+			in:   baseUsers + "2,G,ventas\n3,G,dev\n2,U,ventas,ana,x,dev;root,027\n3,U,dev,luis,y,root\n4,U,dev,eva,z,,077\n",
+			want: baseUsers + "2,G,ventas\n3,G,dev\n2,U,ventas,ana,x,dev;root,027\n3,U,dev,luis,y,root\n4,U,dev,eva,z,,077\n",
+		},
+		{
+			name: "la umask por defecto no se escribe",
+			in:   baseUsers + "2,U,root,ana,x,,002\n",
+			want: baseUsers + "2,U,root,ana,x\n",
+		},
+		{
+			name: "eliminados",
+			in:   baseUsers + "0,G,viejo\n0,U,root,pepe,x\n",
+			want: baseUsers + "0,G,viejo\n0,U,root,pepe,x\n",
+		},
+		{
+			name: "las líneas mal formadas se conservan",
+			in:   baseUsers + "basura\nx,G,nada\n2,Q,raro\n",
+			want: baseUsers + "basura\nx,G,nada\n2,Q,raro\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.in).String()
+			if got != tt.want {
+				t.Errorf("String() =\n%q\nse esperaba\n%q", got, tt.want)
+			}
+			if again := Parse(got).String(); again != got {
+				t.Errorf("volver a interpretar cambió el contenido:\n%q\n%q", got, again)
+			}
+		})
+	}
+}
+
+func TestLookups(t *testing.T) {
+	tbl := Parse(baseUsers + "0,G,ventas\n2,G,ventas\n3,G,dev\n0,U,ventas,ana,viejo\n2,U,ventas,ana,x,dev;borrado\n")
+
+	if g := tbl.Group("ventas"); g == nil || g.GID != 2 {
+		t.Errorf("Group(ventas) = %+v, se esperaba el activo (GID 2)", g)
+	}
+	if g := tbl.GroupByGID(0); g != nil {
+		t.Errorf("GroupByGID(0) = %+v, se esperaba nil", g)
+	}
+	if u := tbl.User("ana"); u == nil || u.UID != 2 || u.Password != "x" {
+		t.Errorf("User(ana) = %+v, se esperaba el activo (UID 2)", u)
+	}
+	uid, gid, err := tbl.IDs("ana")
+	if err != nil || uid != 2 || gid != 2 {
+		t.Errorf("IDs(ana) = %d, %d, %v", uid, gid, err)
+	}
+	if got := tbl.GIDs("ana"); !reflect.DeepEqual(got, []int32{3}) {
+		t.Errorf("GIDs(ana) = %v, se esperaba [3] (borrado no existe)", got)
+	}
+	if _, _, err := tbl.IDs("nadie"); err == nil {
+		t.Error("IDs(nadie) no devolvió error")
+	}
+}
+
+func TestEdits(t *testing.T) {
+	tbl := Parse(baseUsers)
+	steps := []struct {
+		name string
+		do   func() error
+		ok   bool
+	}{
+		{"agregar grupo", func() error { _, err := tbl.AddGroup("ventas"); return err }, true},
+		{"grupo repetido", func() error { _, err := tbl.AddGroup("ventas"); return err }, false},
+		{"nombre inválido", func() error { _, err := tbl.AddGroup("a,b"); return err }, false},
+		{"agregar usuario", func() error { _, err := tbl.AddUser("ana", "ventas", "h1"); return err }, true},
+		{"usuario sin grupo", func() error { _, err := tbl.AddUser("luis", "nada", "h2"); return err }, false},
+		{"contraseña con coma", func() error { _, err := tbl.AddUser("luis", "ventas", "a,b"); return err }, false},
+		{"grupo suplementario", func() error { return tbl.AddUserGroup("ana", "root") }, true},
+		{"suplementario = principal", func() error { return tbl.AddUserGroup("ana", "ventas") }, false},
+		{"umask", func() error { return tbl.SetUmask("ana", 0o027) }, true},
+		{"root no se elimina", func() error { return tbl.RemoveUser(RootName) }, false},
+		{"eliminar grupo", func() error { return tbl.RemoveGroup("ventas") }, true},
+		{"recrear grupo", func() error { _, err := tbl.AddGroup("ventas"); return err }, true},
+	}
+	for _, s := range steps {
+		if err := s.do(); (err == nil) != s.ok {
+			t.Fatalf("%s: error = %v, se esperaba ok=%v", s.name, err, s.ok)
+		}
+	}
+
+	// el GID de un grupo eliminado queda en 0, así que el recreado vuelve a tomar el 2
+	want := baseUsers + "0,G,ventas\n2,U,ventas,ana,h1,root,027\n2,G,ventas\n"
+	if got := tbl.String(); got != want {
+		t.Errorf("String() =\n%q\nse esperaba\n%q", got, want)
+	}
+	if err := tbl.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if err := Parse(want + "4,G,ventas\n").Validate(); err == nil {
+		t.Error("Validate() aceptó dos grupos 'ventas' activos")
+	}
+	if err := Parse(want + "2,U,root,otro,x\n").Validate(); err == nil {
+		t.Error("Validate() aceptó dos usuarios con UID 2")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	file, sb := newUsersDisk(t, baseUsers)
+
+	tbl, err := Load(file, sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tbl.String(); got != baseUsers {
+		t.Fatalf("Load() = %q, se esperaba %q", got, baseUsers)
+	}
+
+	// Más de un bloque, para que Save reasigne bloques
+	for _, name := range []string{"ventas", "dev", "soporte", "contabilidad", "gerencia"} {
+		if _, err := tbl.AddGroup(name); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tbl.AddUser("u_"+name, name, "$2a$10$hashdeprueba"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Save(file, sb, tbl); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(file, sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.String() != tbl.String() {
+		t.Errorf("Load() después de Save() =\n%q\nse esperaba\n%q", loaded.String(), tbl.String())
+	}
+
+	// Una tabla inválida no se escribe
+	bad := Parse(tbl.String() + "99,G,ventas\n")
+	if err := Save(file, sb, bad); err == nil {
+		t.Fatal("Save() aceptó una tabla con grupos duplicados")
+	}
+	if again, _ := Load(file, sb); again.String() != tbl.String() {
+		t.Error("Save() con error modificó users.txt")
+	}
+}
+
+// newUsersDisk arma un sistema de archivos mínimo con la raíz y /users.txt.
+func newUsersDisk(t *testing.T, content string) (*os.File, structs.Superblock) {
+	t.Helper()
+	file, sb := fstest.NewImage(t, 16)
+
+	index, _ := fs.FindFreeInode(file, sb)
+	fs.MarkInodeAsUsed(file, sb, index)
+	inode := structs.Inode{I_uid: fs.RootUID, I_gid: fs.RootUID, I_type: 1, I_links: 1, I_acl: -1}
+	for i := range inode.I_block {
+		inode.I_block[i] = -1
+	}
+	fs.WriteInode(file, sb, index, inode)
+
+	root, _ := fs.ReadInode(file, sb, 0)
+	folder, _ := fs.ReadFolderBlock(file, sb, root.I_block[0])
+	copy(folder.B_content[2].B_name[:], "users.txt")
+	folder.B_content[2].B_inodo = index
+	fs.WriteFolderBlock(file, sb, root.I_block[0], folder)
+
+	if err := fs.AppendFileContent(file, sb, index, []byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	return file, sb
+}
@@ -7,6 +7,7 @@ import LoginPage from './components/LoginPage';
 import DisksPage from './components/DisksPage';
 import PartitionsPage from './components/PartitionsPage';
 import FileBrowser from './components/FileBrowser';
+import ReportsPage from './components/ReportsPage';
 import { executeCommands } from './services/api';
 
 function App() {
@@ -60,6 +61,7 @@ function App() {
           <Route path="/discos" element={<DisksPage onExecute={handleExecuteCommands} />} />
           <Route path="/disco" element={<PartitionsPage onExecute={handleExecuteCommands} />} />
           <Route path="/browse" element={<FileBrowser onExecute={handleExecuteCommands} />} />
+          <Route path="/reportes" element={<ReportsPage />} />
         </Routes>
       </div>
     </BrowserRouter>
@@ -23,6 +23,7 @@ function Header({ onExecute }) {
         <div>
           <Link to="/login" className="btn btn-outline-light me-2">Iniciar sesión</Link>
           <Link to="/discos" className="btn btn-outline-light me-2">Discos</Link>
+          <Link to="/reportes" className="btn btn-outline-light me-2">Reportes</Link>
           <button onClick={handleLogout} className="btn btn-outline-danger">Logout</button>
         </div>
       </div>
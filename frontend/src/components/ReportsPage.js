@@ -0,0 +1,182 @@
+import React, { useEffect, useState } from 'react';
+import { useLocation, useNavigate } from 'react-router-dom';
+import { fetchReport, reportUrl } from '../services/api';
+
+const REPORTS = ['mbr', 'disk', 'sb', 'inode', 'block', 'tree', 'bm_inode', 'bm_block', 'file', 'ls', 'journaling', 'du', 'heatmap'];
+const FORMATS = ['svg', 'png', 'jpg', 'pdf', 'html', 'txt', 'json', 'dot'];
+const NEEDS_PATH = ['file', 'ls', 'du'];
+const JOURNAL_FILTERS = ['operation', 'prefix', 'user', 'from', 'to'];
+const LS_SORTS = { name: 'Nombre', size: 'Tamaño', time: 'Modificación', atime: 'Último acceso', ctime: 'Creación' };
+
+function useQuery() { return new URLSearchParams(useLocation().search); }
+
+function ReportsPage() {
+  const query = useQuery();
+  const navigate = useNavigate();
+  const [id, setId] = useState(query.get('id') || '');
+  const [name, setName] = useState(query.get('name') || 'mbr');
+  const [format, setFormat] = useState(query.get('format') || 'svg');
+  const [path, setPath] = useState(query.get('path') || '/');
+  const [depth, setDepth] = useState(query.get('depth') || '');
+  const [recursive, setRecursive] = useState(query.get('recursive') === 'true');
+  const [sort, setSort] = useState(query.get('sort') || 'name');
+  const [filters, setFilters] = useState(() =>
+    Object.fromEntries(JOURNAL_FILTERS.map(key => [key, query.get(key) || ''])));
+  const [result, setResult] = useState(null); // { url, text, contentType }
+  const [error, setError] = useState('');
+  const [loading, setLoading] = useState(false);
+
+  // Libera la URL del blob anterior al cambiar de reporte
+  useEffect(() => () => { if (result && result.url) URL.revokeObjectURL(result.url); }, [result]);
+
+  // Parámetros extra que usa el reporte elegido
+  function reportOptions() {
+    if (name === 'du') return { path, depth };
+    if (name === 'ls') return { path, recursive: recursive ? 'true' : '', sort };
+    if (NEEDS_PATH.includes(name)) return { path };
+    if (name === 'journaling') return filters;
+    return {};
+  }
+
+  async function generate(e) {
+    if (e) e.preventDefault();
+    if (!id) { setError('Indica el ID de la partición.'); return; }
+    const options = reportOptions();
+    const params = new URLSearchParams({ id, name, format });
+    Object.entries(options).forEach(([key, value]) => { if (value) params.set(key, value); });
+    navigate(`/reportes?${params}`, { replace: true });
+
+    setLoading(true);
+    setError('');
+    try {
+      const { blob, contentType } = await fetchReport(id, name, format, options);
+      if (contentType.startsWith('text/') || contentType.startsWith('application/json')) {
+        const text = await blob.text();
+        setResult({ text, contentType, url: contentType.startsWith('text/html') ? URL.createObjectURL(blob) : null });
+      } else {
+        setResult({ url: URL.createObjectURL(blob), contentType });
+      }
+    } catch (err) {
+      setResult(null);
+      setError(err.message || String(err));
+    } finally {
+      setLoading(false);
+    }
+  }
+
+  function renderResult() {
+    if (!result) return null;
+    const { url, text, contentType } = result;
+    if (contentType.startsWith('image/')) {
+      return (
+        <div className="bg-white p-2 rounded" style={{ overflow: 'auto', maxHeight: '75vh' }}>
+          <img src={url} alt={`reporte ${name}`} style={{ maxWidth: 'none' }} />
+        </div>
+      );
+    }
+    if (contentType.startsWith('application/pdf') || contentType.startsWith('text/html')) {
+      return <iframe title={`reporte ${name}`} src={url} className="w-100 bg-white rounded" style={{ height: '75vh', border: 0 }} />;
+    }
+    return <textarea className="form-control bg-dark text-light font-monospace" value={text} rows={24} readOnly />;
+  }
+
+  return (
+    <div className="container mt-4">
+      <div className="d-flex justify-content-between align-items-center mb-3">
+        <h4>Reportes</h4>
+        <button className="btn btn-secondary" onClick={() => navigate(-1)}>Volver</button>
+      </div>
+
+      <form className="row g-2 align-items-end mb-3" onSubmit={generate}>
+        <div className="col-md-2">
+          <label className="form-label">Partición</label>
+          <input className="form-control" value={id} onChange={e => setId(e.target.value)} placeholder="351A" />
+        </div>
+        <div className="col-md-2">
+          <label className="form-label">Reporte</label>
+          <select className="form-select" value={name} onChange={e => setName(e.target.value)}>
+            {REPORTS.map(r => <option key={r} value={r}>{r}</option>)}
+          </select>
+        </div>
+        <div className="col-md-2">
+          <label className="form-label">Formato</label>
+          <select className="form-select" value={format} onChange={e => setFormat(e.target.value)}>
+            {FORMATS.map(f => <option key={f} value={f}>{f}</option>)}
+          </select>
+        </div>
+        {NEEDS_PATH.includes(name) && (
+          <div className="col-md-3">
+            <label className="form-label">Ruta en la partición</label>
+            <input className="form-control" value={path} onChange={e => setPath(e.target.value)} />
+          </div>
+        )}
+        {name === 'du' && (
+          <div className="col-md-1">
+            <label className="form-label">Niveles</label>
+            <input type="number" min="0" className="form-control" value={depth} placeholder="todos"
+              onChange={e => setDepth(e.target.value)} />
+          </div>
+        )}
+        {name === 'ls' && (
+          <>
+            <div className="col-md-2">
+              <label className="form-label">Orden</label>
+              <select className="form-select" value={sort} onChange={e => setSort(e.target.value)}>
+                {Object.entries(LS_SORTS).map(([key, label]) => <option key={key} value={key}>{label}</option>)}
+              </select>
+            </div>
+            <div className="col-md-1 form-check mb-2">
+              <input type="checkbox" className="form-check-input" id="ls-recursive" checked={recursive}
+                onChange={e => setRecursive(e.target.checked)} />
+              <label className="form-check-label" htmlFor="ls-recursive">Recursivo</label>
+            </div>
+          </>
+        )}
+        {name === 'journaling' && (
+          <>
+            <div className="col-md-2">
+              <label className="form-label">Operación</label>
+              <input className="form-control" value={filters.operation} placeholder="mkfile,remove"
+                onChange={e => setFilters({ ...filters, operation: e.target.value })} />
+            </div>
+            <div className="col-md-2">
+              <label className="form-label">Ruta</label>
+              <input className="form-control" value={filters.prefix} placeholder="/home"
+                onChange={e => setFilters({ ...filters, prefix: e.target.value })} />
+            </div>
+            <div className="col-md-2">
+              <label className="form-label">Usuario</label>
+              <input className="form-control" value={filters.user}
+                onChange={e => setFilters({ ...filters, user: e.target.value })} />
+            </div>
+            <div className="col-md-2">
+              <label className="form-label">Desde</label>
+              <input type="date" className="form-control" value={filters.from}
+                onChange={e => setFilters({ ...filters, from: e.target.value })} />
+            </div>
+            <div className="col-md-2">
+              <label className="form-label">Hasta</label>
+              <input type="date" className="form-control" value={filters.to}
+                onChange={e => setFilters({ ...filters, to: e.target.value })} />
+            </div>
+          </>
+        )}
+        <div className="col-md-3">
+          <button type="submit" className="btn btn-primary me-2" disabled={loading}>
+            {loading ? 'Generando...' : 'Generar'}
+          </button>
+          {result && (
+            <a className="btn btn-outline-light" href={reportUrl(id, name, format, reportOptions(), true)}>
+              Descargar
+            </a>
+          )}
+        </div>
+      </form>
+
+      {error && <div className="alert alert-danger">{error}</div>}
+      {renderResult()}
+    </div>
+  );
+}
+
+export default ReportsPage;
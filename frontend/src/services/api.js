@@ -11,4 +11,26 @@ export const executeCommands = async (commands) => {
   } catch (error) {
     throw new Error('Error al comunicarse con el servidor: ' + error.message);
   }
-};
\ No newline at end of file
+};
+
+// Reportes: GET /reports/{id}/{name} devuelve el reporte generado en memoria.
+// options: path (file, ls y du), depth (du), recursive y sort (ls) y los filtros de
+// journaling (operation, prefix, user, from, to)
+export const reportUrl = (id, name, format, options = {}, download = false) => {
+  const params = new URLSearchParams({ format });
+  Object.entries(options).forEach(([key, value]) => { if (value) params.set(key, value); });
+  if (download) params.set('download', '1');
+  return `${API_BASE_URL}/reports/${encodeURIComponent(id)}/${encodeURIComponent(name)}?${params}`;
+};
+
+export const fetchReport = async (id, name, format, options) => {
+  try {
+    const response = await axios.get(reportUrl(id, name, format, options), { responseType: 'blob' });
+    return { blob: response.data, contentType: response.headers['content-type'] || '' };
+  } catch (error) {
+    // El servidor responde el motivo en texto plano
+    const data = error.response && error.response.data;
+    const detail = data && data.text ? await data.text() : error.message;
+    throw new Error(detail);
+  }
+};